@@ -12,6 +12,7 @@ import (
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/middleware"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/routes"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/storage"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	fiberlogger "github.com/gofiber/fiber/v2/middleware/logger"
@@ -33,28 +34,59 @@ func main() {
 	// Database
 	db := database.InitDB(cfg)
 
+	// Blob storage
+	blobStore, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	// Services
-	authService := services.NewAuthService(db, cfg)
+	mediaService := services.NewMediaService(blobStore, cfg)
+	inviteService := services.NewInviteService(db, cfg)
+	authService := services.NewAuthService(db, cfg, mediaService, inviteService)
 	subscriptionService := services.NewSubscriptionService(db)
 	moderationService := services.NewModerationService(db)
-	auraService := services.NewAuraService(db, cfg)
-	auraMatchService := services.NewAuraMatchService(db, cfg)
-	streakService := services.NewStreakService(db)
+	quotaService := services.NewQuotaService(db, cfg)
+	colorCatalogService := services.NewColorCatalogService(db, cfg)
+	analysisCache := services.NewAnalysisCache(db, cfg)
+	aiUsageService := services.NewAIUsageService(db, cfg)
+	promptService := services.NewPromptService(db, cfg)
+	auraService := services.NewAuraService(db, cfg, mediaService, quotaService, colorCatalogService, analysisCache, aiUsageService, promptService)
+	scanJobService := services.NewScanJobService(db, cfg, auraService, mediaService)
+	friendService := services.NewFriendService(db)
+	auraMatchService := services.NewAuraMatchService(db, cfg, colorCatalogService, aiUsageService, promptService, friendService)
+	streakService := services.NewStreakService(db, colorCatalogService)
+	auraCardService := services.NewAuraCardService(db, colorCatalogService)
+	shareService := services.NewShareService(db, auraCardService)
+	readingPurgeService := services.NewReadingPurgeService(db, cfg, mediaService)
 
 	// Handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	healthHandler := handlers.NewHealthHandler()
 	webhookHandler := handlers.NewWebhookHandler(subscriptionService, cfg)
 	moderationHandler := handlers.NewModerationHandler(moderationService)
-	auraHandler := handlers.NewAuraHandler(auraService)
+	auraHandler := handlers.NewAuraHandler(auraService, scanJobService, auraCardService)
 	auraMatchHandler := handlers.NewAuraMatchHandler(auraMatchService)
 	streakHandler := handlers.NewStreakHandler(streakService)
 	legalHandler := handlers.NewLegalHandler()
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	colorCatalogHandler := handlers.NewColorCatalogHandler(colorCatalogService)
+	shareHandler := handlers.NewShareHandler(shareService, cfg)
+	aiUsageHandler := handlers.NewAIUsageHandler(aiUsageService)
+	promptHandler := handlers.NewPromptHandler(promptService)
+	friendHandler := handlers.NewFriendHandler(friendService)
+	inviteHandler := handlers.NewInviteHandler(inviteService, cfg)
 
 	// Fiber app
 	app := fiber.New(fiber.Config{
 		BodyLimit:    4 * 1024 * 1024, // 4MB
 		ErrorHandler: customErrorHandler,
+		// c.IP() reads the client address from ProxyHeader only on requests from a
+		// trusted proxy, so clients cannot pick the IP that rate limits count against.
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxyList(),
+		EnableIPValidation:      true,
 	})
 
 	// Global middleware
@@ -74,7 +106,14 @@ func main() {
 	app.Use("/api/auth", authLimiter)
 
 	// Routes
-	routes.Setup(app, cfg, authHandler, healthHandler, webhookHandler, moderationHandler, auraHandler, auraMatchHandler, streakHandler, legalHandler)
+	routes.Setup(app, cfg, authHandler, healthHandler, webhookHandler, moderationHandler, auraHandler, auraMatchHandler, streakHandler, legalHandler, mediaHandler, colorCatalogHandler, shareHandler, aiUsageHandler, promptHandler, friendHandler, inviteHandler)
+
+	// Background workers
+	scanJobService.Start()
+	colorCatalogService.Start()
+	promptService.Start()
+	readingPurgeService.Start()
+	analysisCache.Start()
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -93,6 +132,11 @@ func main() {
 	if err := app.Shutdown(); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
+	scanJobService.Stop()
+	colorCatalogService.Stop()
+	promptService.Stop()
+	readingPurgeService.Stop()
+	analysisCache.Stop()
 	log.Println("Server stopped")
 }
 
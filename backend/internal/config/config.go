@@ -1,7 +1,13 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,20 +33,109 @@ type Config struct {
 	GLMAPIKey             string
 	GLMAPIURL             string
 	GLMModel              string
+	GLMVisionModel        string
+	GLMImageMaxBytes      int
+	GLMImageMaxDim        int
 	DeepSeekAPIKey        string
 	DeepSeekAPIURL        string
 	DeepSeekModel         string
+	DeepSeekVisionModel   string
+	DeepSeekImageMaxBytes int
+	DeepSeekImageMaxDim   int
 	AuraAITimeout         time.Duration
 
+	// AuraAIProviders is the analyzer registry. When AURA_AI_PROVIDERS is unset it is
+	// derived from the GLM/DeepSeek settings above, in that order.
+	AuraAIProviders        []AuraProviderConfig
+	AuraAIBreakerThreshold int
+	AuraAIBreakerCooldown  time.Duration
+	AuraAIHedgeDelay       time.Duration
+
+	// AuraQualityGate rejects dark, blurry or tiny scan photos before analysis.
+	AuraQualityGate bool
+
+	// AuraNarratives asks the AI providers to write each reading's text.
+	AuraNarratives       bool
+	AuraNarrativeTimeout time.Duration
+
+	// CompareAIHourlyLimit caps AI-written comparison explanations per user per hour;
+	// cached ones do not count.
+	CompareAIHourlyLimit int
+
+	// AuraDuplicatePolicy decides what happens to a photo that perceptually matches one of
+	// the user's readings from the last AuraDuplicateWindow: "reuse" returns that reading
+	// without using a scan, "flag" scans it but marks the reading, "off" ignores it.
+	AuraDuplicatePolicy    string
+	AuraDuplicateWindow    time.Duration
+	AuraDuplicateThreshold int
+
+	// AuraAnalysisCache stores AI results per photo so a retried upload is not analyzed
+	// twice: "memory" keeps them in this instance, "postgres" shares them between
+	// replicas, "off" disables caching.
+	AuraAnalysisCache           string
+	AuraAnalysisCacheTTL        time.Duration
+	AuraAnalysisCacheMaxEntries int
+
+	// ScanPlanLimits maps plan names to daily scan limits (see plans.go).
+	ScanPlanLimits map[string]int
+
+	// Async scan worker pool
+	ScanJobWorkers      int
+	ScanJobPollInterval time.Duration
+	ScanJobLease        time.Duration
+	ScanJobMaxAttempts  int
+	ScanJobRetention    time.Duration
+
+	// ColorCatalogRefresh is how often each instance reloads the active color palette.
+	ColorCatalogRefresh time.Duration
+	// PromptRefresh is how often each instance reloads prompt template overrides.
+	PromptRefresh time.Duration
+
+	// ReadingRetention is how long a deleted reading can be restored before it is purged.
+	ReadingRetention     time.Duration
+	ReadingPurgeInterval time.Duration
+
 	OpenAIAPIKey string
 	OpenAIModel  string
 
+	// AIModelPrices estimates the cost of each recorded AI call (see prices.go).
+	AIModelPrices map[string]ModelPrice
+
+	StorageBackend  string
+	StorageLocalDir string
+	S3Endpoint      string
+	S3Region        string
+	S3Bucket        string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3PathStyle     bool
+	MediaSigningKey string
+	MediaURLTTL     time.Duration
+	PublicBaseURL   string
+
+	// Friend invites: a caller who fails InviteRedeemMaxFailures redemptions within
+	// InviteRedeemWindow is refused until older failures age out.
+	InviteRedeemMaxFailures int
+	InviteRedeemWindow      time.Duration
+	// Universal links: Apple app IDs (TEAMID.bundle) and Android SHA-256 signing cert
+	// fingerprints, comma separated, served from /.well-known.
+	AppleAppIDs             string
+	AndroidPackage          string
+	AndroidCertFingerprints string
+
 	Port        string
 	CORSOrigins string
+
+	// TrustedProxies lists the load balancer addresses or CIDR ranges, comma separated,
+	// whose ProxyHeader is believed for the client IP that rate limits are keyed by.
+	// Requests from anywhere else use the connecting address. The proxy must set the
+	// header itself, replacing any value the client sent, since its first address is used.
+	TrustedProxies string
+	ProxyHeader    string
 }
 
 func Load() *Config {
-	return &Config{
+	cfg := &Config{
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBUser:     getEnv("DB_USER", "postgres"),
@@ -63,18 +158,115 @@ func Load() *Config {
 		GLMAPIKey: getEnv("GLM_API_KEY", getEnv("AURA_GLM_API_KEY", "")),
 		GLMAPIURL: getEnv("GLM_API_URL", getEnv("AURA_GLM_API_URL", "https://api.z.ai/api/paas/v4/chat/completions")),
 		GLMModel:  getEnv("GLM_MODEL", getEnv("AURA_GLM_MODEL", "glm-4.7")),
+		// Vision model receives the photo itself; leave empty to keep GLM text-only.
+		GLMVisionModel:   getEnv("GLM_VISION_MODEL", "glm-4.6v"),
+		GLMImageMaxBytes: parseInt(getEnv("GLM_IMAGE_MAX_BYTES", "4194304"), 4*1024*1024),
+		GLMImageMaxDim:   parseInt(getEnv("GLM_IMAGE_MAX_DIM", "1536"), 1536),
 		// DeepSeek is secondary fallback provider.
-		DeepSeekAPIKey: getEnv("DEEPSEEK_API_KEY", getEnv("AURA_DEEPSEEK_API_KEY", "")),
-		DeepSeekAPIURL: getEnv("DEEPSEEK_API_URL", getEnv("AURA_DEEPSEEK_API_URL", "https://api.deepseek.com/chat/completions")),
-		DeepSeekModel:  getEnv("DEEPSEEK_MODEL", getEnv("AURA_DEEPSEEK_MODEL", "deepseek-chat")),
-		AuraAITimeout:  parseDuration(getEnv("AURA_AI_TIMEOUT", "20s")),
+		DeepSeekAPIKey:        getEnv("DEEPSEEK_API_KEY", getEnv("AURA_DEEPSEEK_API_KEY", "")),
+		DeepSeekAPIURL:        getEnv("DEEPSEEK_API_URL", getEnv("AURA_DEEPSEEK_API_URL", "https://api.deepseek.com/chat/completions")),
+		DeepSeekModel:         getEnv("DEEPSEEK_MODEL", getEnv("AURA_DEEPSEEK_MODEL", "deepseek-chat")),
+		DeepSeekVisionModel:   getEnv("DEEPSEEK_VISION_MODEL", ""),
+		DeepSeekImageMaxBytes: parseInt(getEnv("DEEPSEEK_IMAGE_MAX_BYTES", "1048576"), 1024*1024),
+		DeepSeekImageMaxDim:   parseInt(getEnv("DEEPSEEK_IMAGE_MAX_DIM", "1024"), 1024),
+		AuraAITimeout:         parseDuration(getEnv("AURA_AI_TIMEOUT", "20s")),
+
+		AuraAIBreakerThreshold: parseInt(getEnv("AURA_AI_BREAKER_THRESHOLD", "3"), 3),
+		AuraAIBreakerCooldown:  parseDuration(getEnv("AURA_AI_BREAKER_COOLDOWN", "60s")),
+		// Zero disables hedging: providers are tried strictly in order.
+		AuraAIHedgeDelay: parseDuration(getEnv("AURA_AI_HEDGE_DELAY", "0s")),
+
+		AuraQualityGate: parseBool(getEnv("AURA_QUALITY_GATE", "true")),
+
+		AuraNarratives:       parseBool(getEnv("AURA_NARRATIVES", "true")),
+		AuraNarrativeTimeout: parseDuration(getEnv("AURA_NARRATIVE_TIMEOUT", "15s")),
+
+		CompareAIHourlyLimit: parseInt(getEnv("COMPARE_AI_HOURLY_LIMIT", "20"), 20),
+
+		AuraDuplicatePolicy: getEnv("AURA_DUPLICATE_POLICY", "reuse"),
+		AuraDuplicateWindow: parseDuration(getEnv("AURA_DUPLICATE_WINDOW", "24h")),
+		// Maximum number of differing bits, out of 64, between two photo hashes.
+		AuraDuplicateThreshold: parseInt(getEnv("AURA_DUPLICATE_THRESHOLD", "6"), 6),
+
+		AuraAnalysisCache:           getEnv("AURA_ANALYSIS_CACHE", "memory"),
+		AuraAnalysisCacheTTL:        parseDuration(getEnv("AURA_ANALYSIS_CACHE_TTL", "24h")),
+		AuraAnalysisCacheMaxEntries: parseInt(getEnv("AURA_ANALYSIS_CACHE_MAX_ENTRIES", "10000"), 10000),
+
+		ScanJobWorkers:      parseInt(getEnv("SCAN_JOB_WORKERS", "4"), 4),
+		ScanJobPollInterval: parseDuration(getEnv("SCAN_JOB_POLL_INTERVAL", "2s")),
+		ScanJobLease:        parseDuration(getEnv("SCAN_JOB_LEASE", "2m")),
+		ScanJobMaxAttempts:  parseInt(getEnv("SCAN_JOB_MAX_ATTEMPTS", "3"), 3),
+		ScanJobRetention:    parseDuration(getEnv("SCAN_JOB_RETENTION", "168h")),
+
+		ColorCatalogRefresh: parseDuration(getEnv("COLOR_CATALOG_REFRESH", "1m")),
+		PromptRefresh:       parseDuration(getEnv("PROMPT_REFRESH", "1m")),
+
+		ReadingRetention:     parseDuration(getEnv("READING_RETENTION", "720h")),
+		ReadingPurgeInterval: parseDuration(getEnv("READING_PURGE_INTERVAL", "1h")),
 
 		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
 		OpenAIModel:  getEnv("OPENAI_MODEL", "gpt-4o-mini"),
 
+		// Scan photos and thumbnails: "local" (filesystem) or "s3" (any S3-compatible store).
+		StorageBackend:  getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir: getEnv("STORAGE_LOCAL_DIR", "./data/uploads"),
+		S3Endpoint:      getEnv("S3_ENDPOINT", ""),
+		S3Region:        getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:        getEnv("S3_BUCKET", ""),
+		S3AccessKey:     getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:     getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:     parseBool(getEnv("S3_PATH_STYLE", "true")),
+		// Without a dedicated key, signed media links use a key derived from the JWT
+		// secret (see mediaKeyFromJWTSecret), never the JWT secret itself.
+		MediaSigningKey: getEnv("MEDIA_SIGNING_KEY", ""),
+		MediaURLTTL:     parseDuration(getEnv("MEDIA_URL_TTL", "15m")),
+		PublicBaseURL:   getEnv("PUBLIC_BASE_URL", ""),
+
+		InviteRedeemMaxFailures: parseInt(getEnv("INVITE_REDEEM_MAX_FAILURES", "10"), 10),
+		InviteRedeemWindow:      parseDuration(getEnv("INVITE_REDEEM_WINDOW", "1h")),
+		AppleAppIDs:             getEnv("APPLE_APP_IDS", ""),
+		AndroidPackage:          getEnv("ANDROID_PACKAGE", "com.ahmetcoskunkizilkaya.aurasnap"),
+		AndroidCertFingerprints: getEnv("ANDROID_CERT_FINGERPRINTS", ""),
+
 		Port:        getEnv("PORT", "8080"),
 		CORSOrigins: getEnv("CORS_ORIGINS", "*"),
+
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+		ProxyHeader:    getEnv("PROXY_HEADER", "X-Real-IP"),
+	}
+
+	if raw := getEnv("AURA_AI_PROVIDERS", ""); raw != "" {
+		providers, err := parseAuraProviders(raw)
+		if err != nil {
+			log.Printf("Ignoring AURA_AI_PROVIDERS: %v", err)
+		} else {
+			cfg.AuraAIProviders = providers
+		}
 	}
+
+	limits, err := parsePlanLimits(getEnv("SCAN_PLAN_LIMITS", ""))
+	if err != nil {
+		log.Printf("Ignoring SCAN_PLAN_LIMITS: %v", err)
+		limits, _ = parsePlanLimits("")
+	}
+	cfg.ScanPlanLimits = limits
+
+	if cfg.MediaSigningKey == "" && cfg.JWTSecret != "" {
+		key, err := mediaKeyFromJWTSecret(cfg.JWTSecret)
+		if err != nil {
+			log.Fatalf("Failed to derive media signing key: %v", err)
+		}
+		cfg.MediaSigningKey = key
+	}
+
+	prices, err := parseModelPrices(getEnv("AI_MODEL_PRICES", ""))
+	if err != nil {
+		log.Printf("Ignoring AI_MODEL_PRICES: %v", err)
+		prices, _ = parseModelPrices("")
+	}
+	cfg.AIModelPrices = prices
+
+	return cfg
 }
 
 func (c *Config) DSN() string {
@@ -87,6 +279,28 @@ func (c *Config) DSN() string {
 		" TimeZone=UTC"
 }
 
+// TrustedProxyList splits TrustedProxies into addresses and ranges.
+func (c *Config) TrustedProxyList() []string {
+	var proxies []string
+	for _, p := range strings.Split(c.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// mediaKeyFromJWTSecret derives the media URL signing key from the JWT secret with HKDF,
+// so a leaked media signature says nothing about the token key. Rotating JWT_SECRET still
+// rotates the derived key; set MEDIA_SIGNING_KEY to rotate the two independently.
+func mediaKeyFromJWTSecret(secret string) (string, error) {
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "aurasnap media url signing", 32)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -101,3 +315,16 @@ func parseDuration(s string) time.Duration {
 	}
 	return d
 }
+
+func parseInt(s string, fallback int) int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func parseBool(s string) bool {
+	v, err := strconv.ParseBool(s)
+	return err == nil && v
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Scan plans. A subscription's product ID may also be used as a plan name in
+// SCAN_PLAN_LIMITS to give individual products their own limit.
+const (
+	PlanFree    = "free"
+	PlanPremium = "premium"
+)
+
+// UnlimitedScans marks a plan without a daily scan limit.
+const UnlimitedScans = -1
+
+var defaultScanPlanLimits = map[string]int{
+	PlanFree:    2,
+	PlanPremium: UnlimitedScans,
+}
+
+// parsePlanLimits reads SCAN_PLAN_LIMITS, a comma-separated list of plan=limit pairs such
+// as "free=2,premium=unlimited". Negative limits and "unlimited" both mean no limit.
+func parsePlanLimits(raw string) (map[string]int, error) {
+	limits := make(map[string]int, len(defaultScanPlanLimits))
+	for plan, limit := range defaultScanPlanLimits {
+		limits[plan] = limit
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected plan=limit", pair)
+		}
+		if value == "unlimited" {
+			limits[name] = UnlimitedScans
+			continue
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit for plan %q: %w", name, err)
+		}
+		if limit < 0 {
+			limit = UnlimitedScans
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// ScanLimit returns the daily scan limit for a plan, falling back to the free plan for
+// unknown names.
+func (c *Config) ScanLimit(plan string) int {
+	limits := c.ScanPlanLimits
+	if limits == nil {
+		limits = defaultScanPlanLimits
+	}
+	if limit, ok := limits[strings.ToLower(plan)]; ok {
+		return limit
+	}
+	return limits[PlanFree]
+}
+
+// HasPlan reports whether a plan name has its own configured limit.
+func (c *Config) HasPlan(plan string) bool {
+	limits := c.ScanPlanLimits
+	if limits == nil {
+		limits = defaultScanPlanLimits
+	}
+	_, ok := limits[strings.ToLower(plan)]
+	return ok
+}
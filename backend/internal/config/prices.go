@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ModelPrice is a model's list price in USD per million tokens.
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+var defaultModelPrices = map[string]ModelPrice{
+	"gpt-4o-mini": {Prompt: 0.15, Completion: 0.60},
+}
+
+// parseModelPrices reads AI_MODEL_PRICES, a comma-separated list of model=prompt/completion
+// pairs in USD per million tokens, such as "glm-4.7=0.6/2.2,deepseek-chat=0.28/0.42".
+// Entries override the built-in prices.
+func parseModelPrices(raw string) (map[string]ModelPrice, error) {
+	prices := make(map[string]ModelPrice, len(defaultModelPrices))
+	for model, price := range defaultModelPrices {
+		prices[model] = price
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		model, value, ok := strings.Cut(pair, "=")
+		model = strings.ToLower(strings.TrimSpace(model))
+		prompt, completion, okPrice := strings.Cut(value, "/")
+		if !ok || !okPrice || model == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected model=prompt/completion", pair)
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(prompt), 64)
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("invalid prompt price for model %q", model)
+		}
+		c, err := strconv.ParseFloat(strings.TrimSpace(completion), 64)
+		if err != nil || c < 0 {
+			return nil, fmt.Errorf("invalid completion price for model %q", model)
+		}
+		prices[model] = ModelPrice{Prompt: p, Completion: c}
+	}
+	return prices, nil
+}
+
+// ModelPrice returns the configured price for a model. Unknown models are reported so
+// their calls can be recorded without a cost estimate.
+func (c *Config) ModelPrice(model string) (ModelPrice, bool) {
+	prices := c.AIModelPrices
+	if prices == nil {
+		prices = defaultModelPrices
+	}
+	price, ok := prices[strings.ToLower(model)]
+	return price, ok
+}
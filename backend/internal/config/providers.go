@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// AuraProviderConfig describes one OpenAI-compatible aura analysis provider.
+//
+// AURA_AI_PROVIDERS holds a JSON array of these, e.g.
+//
+//	[{"name":"glm","url":"https://api.z.ai/api/paas/v4/chat/completions","model":"glm-4.7",
+//	  "vision_model":"glm-4.6v","key_env":"GLM_API_KEY","weight":10,"timeout":"15s"}]
+//
+// Providers are tried by descending weight. `key_env` names an environment variable holding
+// the API key so secrets stay out of the JSON; `key` may be used instead.
+type AuraProviderConfig struct {
+	Name          string
+	URL           string
+	Model         string
+	VisionModel   string
+	Key           string
+	Weight        int
+	Timeout       time.Duration
+	MaxImageBytes int
+	MaxImageDim   int
+}
+
+type auraProviderJSON struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Model         string `json:"model"`
+	VisionModel   string `json:"vision_model"`
+	Key           string `json:"key"`
+	KeyEnv        string `json:"key_env"`
+	Weight        int    `json:"weight"`
+	Timeout       string `json:"timeout"`
+	MaxImageBytes int    `json:"max_image_bytes"`
+	MaxImageDim   int    `json:"max_image_dim"`
+}
+
+func parseAuraProviders(raw string) ([]AuraProviderConfig, error) {
+	var entries []auraProviderJSON
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	providers := make([]AuraProviderConfig, 0, len(entries))
+	for i, e := range entries {
+		name := strings.ToLower(strings.TrimSpace(e.Name))
+		if name == "" || strings.TrimSpace(e.URL) == "" || strings.TrimSpace(e.Model) == "" {
+			return nil, fmt.Errorf("provider %d: name, url and model are required", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("provider %q listed twice", name)
+		}
+		seen[name] = true
+
+		key := strings.TrimSpace(e.Key)
+		if e.KeyEnv != "" {
+			key = strings.TrimSpace(os.Getenv(e.KeyEnv))
+		}
+
+		var timeout time.Duration
+		if e.Timeout != "" {
+			d, err := time.ParseDuration(e.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("provider %q: invalid timeout %q", name, e.Timeout)
+			}
+			timeout = d
+		}
+
+		weight := e.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		providers = append(providers, AuraProviderConfig{
+			Name:          name,
+			URL:           strings.TrimSpace(e.URL),
+			Model:         strings.TrimSpace(e.Model),
+			VisionModel:   strings.TrimSpace(e.VisionModel),
+			Key:           key,
+			Weight:        weight,
+			Timeout:       timeout,
+			MaxImageBytes: e.MaxImageBytes,
+			MaxImageDim:   e.MaxImageDim,
+		})
+	}
+
+	return providers, nil
+}
+
+// AuraProviders returns the configured provider list, falling back to the legacy
+// GLM (primary) and DeepSeek (secondary) settings. Providers without a key are skipped.
+func (c *Config) AuraProviders() []AuraProviderConfig {
+	var providers []AuraProviderConfig
+	if len(c.AuraAIProviders) > 0 {
+		providers = c.AuraAIProviders
+	} else {
+		providers = []AuraProviderConfig{
+			{
+				Name:          "glm",
+				URL:           strings.TrimSpace(c.GLMAPIURL),
+				Model:         strings.TrimSpace(c.GLMModel),
+				VisionModel:   strings.TrimSpace(c.GLMVisionModel),
+				Key:           strings.TrimSpace(c.GLMAPIKey),
+				Weight:        2,
+				MaxImageBytes: c.GLMImageMaxBytes,
+				MaxImageDim:   c.GLMImageMaxDim,
+			},
+			{
+				Name:          "deepseek",
+				URL:           strings.TrimSpace(c.DeepSeekAPIURL),
+				Model:         strings.TrimSpace(c.DeepSeekModel),
+				VisionModel:   strings.TrimSpace(c.DeepSeekVisionModel),
+				Key:           strings.TrimSpace(c.DeepSeekAPIKey),
+				Weight:        1,
+				MaxImageBytes: c.DeepSeekImageMaxBytes,
+				MaxImageDim:   c.DeepSeekImageMaxDim,
+			},
+		}
+	}
+
+	out := make([]AuraProviderConfig, 0, len(providers))
+	for _, p := range providers {
+		if p.Key == "" {
+			continue
+		}
+		if p.Timeout <= 0 {
+			p.Timeout = c.AuraAITimeout
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -51,11 +51,30 @@ func InitDB(cfg *config.Config) *gorm.DB {
 		&models.AuraReading{},
 		&models.AuraMatch{},
 		&models.AuraStreak{},
+		&models.ScanJob{},
+		&models.ScanQuotaDay{},
+		&models.ScanReservation{},
+		&models.ColorCatalog{},
+		&models.ShareLink{},
+		&models.ShareView{},
+		&models.AnalysisCacheEntry{},
+		&models.AICall{},
+		&models.PromptTemplate{},
+		&models.GroupScan{},
+		&models.Friendship{},
+		&models.FriendInvite{},
+		&models.FriendInviteRedemption{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	// One friendship row covers both directions, so the pair must be unique in either
+	// order. Struct tags cannot declare an expression index.
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_friendships_either_way ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id))").Error; err != nil {
+		log.Fatalf("Failed to index friendships: %v", err)
+	}
+
 	log.Println("Database connected and migrated successfully")
 	DB = db
 	return db
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// AIUsageRow aggregates AI calls for one day, provider and model, feature or user. Key is
+// the day (YYYY-MM-DD, UTC), provider name, feature or user ID; totals leave it empty.
+type AIUsageRow struct {
+	Key              string  `json:"key,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Calls            int64   `json:"calls"`
+	Failures         int64   `json:"failures"`
+	Cancelled        int64   `json:"cancelled"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	AvgLatencyMs     int64   `json:"avg_latency_ms"`
+}
+
+// AIUsageSummary is the response of the admin AI usage endpoints. Costs are estimates from
+// the configured model prices.
+type AIUsageSummary struct {
+	GroupBy string       `json:"group_by"`
+	Days    int          `json:"days"`
+	Since   time.Time    `json:"since"`
+	Totals  AIUsageRow   `json:"totals"`
+	Rows    []AIUsageRow `json:"rows"`
+}
@@ -14,29 +14,103 @@ type CreateAuraRequest struct {
 
 // AuraReadingResponse defines the response for an aura reading
 type AuraReadingResponse struct {
-	ID             uuid.UUID  `json:"id"`
-	UserID         uuid.UUID  `json:"user_id"`
-	AuraColor      string     `json:"aura_color"`
-	SecondaryColor *string    `json:"secondary_color,omitempty"`
-	EnergyLevel    int        `json:"energy_level"`
-	MoodScore      int        `json:"mood_score"`
-	Personality    string     `json:"personality"`
-	Strengths      []string   `json:"strengths"`
-	Challenges     []string   `json:"challenges"`
-	DailyAdvice    string     `json:"daily_advice"`
-	ImageURL       string     `json:"image_url"`
-	AnalyzedAt     time.Time  `json:"analyzed_at"`
-	CreatedAt      time.Time  `json:"created_at"`
-}
-
-// AuraListResponse defines the paginated list of aura readings
+	ID              uuid.UUID `json:"id"`
+	UserID          uuid.UUID `json:"user_id"`
+	AuraColor       string    `json:"aura_color"`
+	SecondaryColor  *string   `json:"secondary_color,omitempty"`
+	EnergyLevel     int       `json:"energy_level"`
+	MoodScore       int       `json:"mood_score"`
+	Personality     string    `json:"personality"`
+	Strengths       []string  `json:"strengths"`
+	Challenges      []string  `json:"challenges"`
+	DailyAdvice     string    `json:"daily_advice"`
+	NarrativeSource string    `json:"narrative_source"`
+	Language        string    `json:"language"`
+	ImageURL        string    `json:"image_url"`
+	ThumbnailURL    string    `json:"thumbnail_url,omitempty"`
+	AnalyzedAt      time.Time `json:"analyzed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	GroupScanID *uuid.UUID `json:"group_scan_id,omitempty"`
+}
+
+// AuraListQuery holds the history filters and cursor from the query string
+type AuraListQuery struct {
+	Cursor         string `query:"cursor"`
+	PageSize       int    `query:"page_size"`
+	Color          string `query:"color"`
+	SecondaryColor string `query:"secondary_color"`
+	From           string `query:"from"`
+	To             string `query:"to"`
+	MinEnergy      string `query:"min_energy"`
+	MaxEnergy      string `query:"max_energy"`
+	MinMood        string `query:"min_mood"`
+	MaxMood        string `query:"max_mood"`
+}
+
+// AuraListResponse defines a page of aura readings. Pass NextCursor back as cursor to
+// fetch the next page; it is empty on the last one.
 type AuraListResponse struct {
 	Data       []AuraReadingResponse `json:"data"`
-	Page       int                   `json:"page"`
 	PageSize   int                   `json:"page_size"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+	HasMore    bool                  `json:"has_more"`
 	TotalCount int64                 `json:"total_count"`
 }
 
+// BulkDeleteAuraRequest deletes readings by ID and/or by creation time in [from, to)
+type BulkDeleteAuraRequest struct {
+	IDs  []string   `json:"ids"`
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// BulkDeleteAuraResponse reports how many readings were moved to recently deleted
+type BulkDeleteAuraResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// DeletedAuraReadingResponse is a reading in recently deleted, with when it will be purged
+type DeletedAuraReadingResponse struct {
+	AuraReadingResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// DeletedAuraListResponse lists the readings that can still be restored
+type DeletedAuraListResponse struct {
+	Data []DeletedAuraReadingResponse `json:"data"`
+}
+
+// AuraComparisonResponse compares two of the user's readings, from A to B. AIExplanation
+// is only present when it was asked for and a provider answered.
+type AuraComparisonResponse struct {
+	A             AuraReadingResponse `json:"a"`
+	B             AuraReadingResponse `json:"b"`
+	Changes       AuraChanges         `json:"changes"`
+	Explanation   string              `json:"explanation"`
+	AIExplanation *string             `json:"ai_explanation,omitempty"`
+}
+
+// AuraChanges is the structured difference between two readings. Deltas are B minus A;
+// ColorRelation is how the two primary colors pair up (same, complementary, challenging
+// or neutral).
+type AuraChanges struct {
+	ColorChanged     bool     `json:"color_changed"`
+	FromColor        string   `json:"from_color"`
+	ToColor          string   `json:"to_color"`
+	ColorRelation    string   `json:"color_relation"`
+	FromSecondary    *string  `json:"from_secondary,omitempty"`
+	ToSecondary      *string  `json:"to_secondary,omitempty"`
+	EnergyDelta      int      `json:"energy_delta"`
+	MoodDelta        int      `json:"mood_delta"`
+	DaysApart        int      `json:"days_apart"`
+	StrengthsGained  []string `json:"strengths_gained"`
+	StrengthsLost    []string `json:"strengths_lost"`
+	ChallengesGained []string `json:"challenges_gained"`
+	ChallengesLost   []string `json:"challenges_lost"`
+}
+
 // AuraStatsResponse defines the aggregated stats for aura readings
 type AuraStatsResponse struct {
 	ColorDistribution map[string]int `json:"color_distribution"`
@@ -45,9 +119,86 @@ type AuraStatsResponse struct {
 	AverageMood       float64        `json:"average_mood"`
 }
 
-// ScanEligibilityResponse defines the response structure for scan eligibility checks
+// AuraTrendsResponse is the user's aura over time, oldest bucket first
+type AuraTrendsResponse struct {
+	Interval string            `json:"interval"`
+	Timezone string            `json:"timezone"`
+	Window   int               `json:"window"`
+	Buckets  []AuraTrendBucket `json:"buckets"`
+}
+
+// AuraTrendBucket holds one day, week or month. Averages are null for buckets without
+// readings; deltas compare against the previous bucket.
+type AuraTrendBucket struct {
+	Start           time.Time `json:"start"`
+	Readings        int       `json:"readings"`
+	AverageEnergy   *float64  `json:"average_energy"`
+	AverageMood     *float64  `json:"average_mood"`
+	DominantColor   *string   `json:"dominant_color"`
+	EnergyMovingAvg *float64  `json:"energy_moving_avg"`
+	MoodMovingAvg   *float64  `json:"mood_moving_avg"`
+	EnergyDelta     *float64  `json:"energy_delta"`
+	MoodDelta       *float64  `json:"mood_delta"`
+}
+
+// ScanEligibilityResponse defines the response structure for scan eligibility checks.
+// Remaining and Limit are -1 for unlimited plans. ResetsAt is the next midnight in the
+// user's timezone.
 type ScanEligibilityResponse struct {
-	CanScan      bool `json:"canScan"`
-	Remaining    int  `json:"remaining"`
-	IsSubscribed bool `json:"isSubscribed"`
+	CanScan      bool      `json:"canScan"`
+	Remaining    int       `json:"remaining"`
+	IsSubscribed bool      `json:"isSubscribed"`
+	Plan         string    `json:"plan"`
+	Limit        int       `json:"limit"`
+	Used         int       `json:"used"`
+	ResetsAt     time.Time `json:"resetsAt"`
+}
+
+// AIProviderHealth reports the circuit breaker state and call history of one AI provider
+type AIProviderHealth struct {
+	Name                string     `json:"name"`
+	Model               string     `json:"model"`
+	Weight              int        `json:"weight"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalCalls          int64      `json:"total_calls"`
+	TotalFailures       int64      `json:"total_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastLatencyMs       int64      `json:"last_latency_ms"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+// AnalysisCacheStats reports the AI analysis cache. Counters are per instance since it
+// started; each hit is a provider call that was not paid for.
+type AnalysisCacheStats struct {
+	Backend    string  `json:"backend"`
+	Entries    int64   `json:"entries"`
+	MaxEntries int     `json:"max_entries"`
+	TTLSeconds int64   `json:"ttl_seconds"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	Stores     int64   `json:"stores"`
+	Errors     int64   `json:"errors"`
+	HitRate    float64 `json:"hit_rate"`
+}
+
+// ImageQualityRejection is returned with 422 when a scan photo fails the quality gate.
+// Issue codes match the mobile app's QualityIssueCode values.
+type ImageQualityRejection struct {
+	Error   string             `json:"error"`
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Score   int                `json:"score"`
+	Issues  []string           `json:"issues"`
+	Metrics ImageQualityMetric `json:"metrics"`
+}
+
+// ImageQualityMetric holds the measurements behind a quality verdict
+type ImageQualityMetric struct {
+	Brightness   float64 `json:"brightness"`
+	Contrast     float64 `json:"contrast"`
+	BlurVariance float64 `json:"blur_variance"`
+	ShortSide    int     `json:"short_side"`
 }
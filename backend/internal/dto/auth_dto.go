@@ -5,6 +5,8 @@ import "github.com/google/uuid"
 type RegisterRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// InviteCode is the friend invite that brought the user in, if any.
+	InviteCode string `json:"invite_code,omitempty"`
 }
 
 type LoginRequest struct {
@@ -17,6 +19,12 @@ type ClaimGuestRequest struct {
 	Password string `json:"password"`
 }
 
+// UpdateProfileRequest changes user preferences; omitted fields are left as they are.
+type UpdateProfileRequest struct {
+	Timezone *string `json:"timezone"`
+	Language *string `json:"language"`
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
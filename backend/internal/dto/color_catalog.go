@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+
+// CreateColorCatalogRequest starts a new draft. Without colors the draft is a copy of the
+// active palette.
+type CreateColorCatalogRequest struct {
+	Note   string                   `json:"note"`
+	Colors []models.ColorDefinition `json:"colors,omitempty"`
+}
+
+// UpdateColorCatalogRequest replaces the fields that are present on a draft.
+type UpdateColorCatalogRequest struct {
+	Note   *string                   `json:"note,omitempty"`
+	Colors *[]models.ColorDefinition `json:"colors,omitempty"`
+}
+
+// ColorCatalogResponse is a catalog version with the problems that would block publishing it.
+type ColorCatalogResponse struct {
+	Catalog  *models.ColorCatalog `json:"catalog"`
+	Problems []string             `json:"problems"`
+}
+
+// ColorCatalogInvalidResponse is returned with 422 when a draft fails validation on publish.
+type ColorCatalogInvalidResponse struct {
+	Error    bool     `json:"error"`
+	Message  string   `json:"message"`
+	Problems []string `json:"problems"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SendFriendRequest asks another user to become friends.
+type SendFriendRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// FriendResponse is one accepted friend.
+type FriendResponse struct {
+	UserID       uuid.UUID `json:"user_id"`
+	FriendsSince time.Time `json:"friends_since"`
+}
+
+// FriendListResponse lists the user's friends, most recent first.
+type FriendListResponse struct {
+	Data []FriendResponse `json:"data"`
+}
+
+// FriendRequestResponse is a pending request; UserID is the other person.
+type FriendRequestResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FriendRequestsResponse splits pending requests into those the user received and those
+// they sent, newest first.
+type FriendRequestsResponse struct {
+	Incoming []FriendRequestResponse `json:"incoming"`
+	Outgoing []FriendRequestResponse `json:"outgoing"`
+}
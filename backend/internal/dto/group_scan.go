@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// GroupScanResponse is the result of a group photo scan: one reading per person found,
+// left to right, and how the group fits together.
+type GroupScanResponse struct {
+	ID            uuid.UUID          `json:"id"`
+	People        int                `json:"people"`
+	Members       []GroupScanMember  `json:"members"`
+	Compatibility GroupCompatibility `json:"compatibility"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// GroupScanMember is one person in the photo. Region is where they were found, as
+// fractions of the photo's width and height.
+type GroupScanMember struct {
+	Index   int                `json:"index"`
+	Region  GroupScanRegion    `json:"region"`
+	Reading models.AuraReading `json:"reading"`
+}
+
+type GroupScanRegion struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// GroupCompatibility scores every pair in the group. A and B are member indexes.
+type GroupCompatibility struct {
+	AverageScore  int              `json:"average_score"`
+	DominantColor string           `json:"dominant_color"`
+	Summary       string           `json:"summary"`
+	BestPair      *GroupPairScore  `json:"best_pair,omitempty"`
+	Pairs         []GroupPairScore `json:"pairs"`
+}
+
+type GroupPairScore struct {
+	A         int    `json:"a"`
+	B         int    `json:"b"`
+	Score     int    `json:"score"`
+	MatchType string `json:"match_type"`
+}
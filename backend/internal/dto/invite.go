@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateInviteRequest mints a friend invite. MaxUses defaults to 1 (single use) and may
+// be at most 100; ExpiresInDays defaults to 7 and may be at most 30.
+type CreateInviteRequest struct {
+	MaxUses       int `json:"max_uses"`
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// InviteResponse is a friend invite as seen by its owner. URL is a universal link that
+// opens the app when it is installed.
+type InviteResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Code      string     `json:"code"`
+	URL       string     `json:"url"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	Signups   int64      `json:"signups"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// InviteListResponse lists the user's invites, newest first
+type InviteListResponse struct {
+	Data []InviteResponse `json:"data"`
+}
+
+// RedeemInviteRequest redeems a code as typed or pasted; case, spaces and dashes are ignored
+type RedeemInviteRequest struct {
+	Code string `json:"code"`
+}
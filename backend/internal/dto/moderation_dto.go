@@ -44,5 +44,6 @@ type AppleSignInRequest struct {
 	IdentityToken string `json:"identity_token"` // JWT from Apple
 	AuthCode      string `json:"authorization_code"`
 	FullName      string `json:"full_name,omitempty"`
-	Email         string `json:"email,omitempty"` // Only sent on first sign-in
+	Email         string `json:"email,omitempty"`       // Only sent on first sign-in
+	InviteCode    string `json:"invite_code,omitempty"` // Applied only when the sign-in creates the account
 }
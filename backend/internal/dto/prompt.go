@@ -0,0 +1,45 @@
+package dto
+
+import "github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+
+// SavePromptVariantRequest stores a new version of a prompt variant. Body is a
+// text/template defining the prompt's blocks; Weight is the variant's share of users
+// (default 1) when several variants are active.
+type SavePromptVariantRequest struct {
+	Body   string `json:"body"`
+	Weight int    `json:"weight"`
+	Note   string `json:"note"`
+}
+
+// PromptResponse is a prompt's embedded default and the active variants replacing it.
+type PromptResponse struct {
+	Name     string                  `json:"name"`
+	Default  string                  `json:"default"`
+	Variants []models.PromptTemplate `json:"variants"`
+}
+
+// PromptInvalidResponse is returned with 422 when a prompt body does not parse or render.
+type PromptInvalidResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Problem string `json:"problem"`
+}
+
+// PromptVersionResult summarizes what one prompt version produced. Readings report
+// energy and mood; matches report the compatibility score.
+type PromptVersionResult struct {
+	Version       string   `json:"version"`
+	Results       int64    `json:"results"`
+	Users         int64    `json:"users"`
+	Shared        int64    `json:"shared"`
+	AverageEnergy *float64 `json:"average_energy,omitempty"`
+	AverageMood   *float64 `json:"average_mood,omitempty"`
+	AverageScore  *float64 `json:"average_score,omitempty"`
+}
+
+// PromptResultsResponse compares the versions of a prompt over the last Days days.
+type PromptResultsResponse struct {
+	Name     string                `json:"name"`
+	Days     int                   `json:"days"`
+	Versions []PromptVersionResult `json:"versions"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateShareRequest mints a share link for a reading or match. ExpiresInDays defaults
+// to 30 and may be at most 365.
+type CreateShareRequest struct {
+	TargetType    string `json:"target_type"`
+	TargetID      string `json:"target_id"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// ShareLinkResponse is a share link as seen by its owner
+type ShareLinkResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	TargetType   string     `json:"target_type"`
+	TargetID     uuid.UUID  `json:"target_id"`
+	Token        string     `json:"token"`
+	URL          string     `json:"url"`
+	CardURL      string     `json:"card_url"`
+	ViewCount    int64      `json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	Active       bool       `json:"active"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ShareListResponse lists the user's share links, newest first
+type ShareListResponse struct {
+	Data []ShareLinkResponse `json:"data"`
+}
+
+// SharedReadingResponse is the public part of a shared reading; the photo and owner stay private
+type SharedReadingResponse struct {
+	AuraColor      string    `json:"aura_color"`
+	SecondaryColor *string   `json:"secondary_color,omitempty"`
+	EnergyLevel    int       `json:"energy_level"`
+	MoodScore      int       `json:"mood_score"`
+	Personality    string    `json:"personality"`
+	Strengths      []string  `json:"strengths"`
+	Language       string    `json:"language"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SharedMatchResponse is the public part of a shared match
+type SharedMatchResponse struct {
+	CompatibilityScore int       `json:"compatibility_score"`
+	Synergy            string    `json:"synergy"`
+	Tension            string    `json:"tension"`
+	Advice             string    `json:"advice"`
+	UserAuraColor      string    `json:"user_aura_color"`
+	FriendAuraColor    string    `json:"friend_aura_color"`
+	Language           string    `json:"language"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// SharedContentResponse is what a share link opens to in the app
+type SharedContentResponse struct {
+	TargetType string                 `json:"target_type"`
+	CardURL    string                 `json:"card_url"`
+	Reading    *SharedReadingResponse `json:"reading,omitempty"`
+	Match      *SharedMatchResponse   `json:"match,omitempty"`
+}
@@ -24,3 +24,22 @@ type StreakUpdateResponse struct {
 	StreakBroken bool           `json:"streak_broken"`
 	Message      string         `json:"message"`
 }
+
+// PaletteResponse is the user's personal palette: every primary color, then each rare
+// streak color with whether the user has unlocked it. Readings come out in unlocked
+// colors only.
+type PaletteResponse struct {
+	Version int            `json:"version"`
+	Colors  []PaletteColor `json:"colors"`
+}
+
+type PaletteColor struct {
+	Key          string  `json:"key"`
+	Name         string  `json:"name"`
+	Hex          string  `json:"hex"`
+	Rare         bool    `json:"rare"`
+	Unlocked     bool    `json:"unlocked"`
+	UnlockStreak int     `json:"unlock_streak,omitempty"`
+	ScanChance   float64 `json:"scan_chance,omitempty"`
+	CardStyle    string  `json:"card_style,omitempty"`
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AIUsageHandler serves the admin AI spend reports.
+type AIUsageHandler struct {
+	usage *services.AIUsageService
+}
+
+func NewAIUsageHandler(usage *services.AIUsageService) *AIUsageHandler {
+	return &AIUsageHandler{usage: usage}
+}
+
+// Daily summarizes AI calls and estimated spend per UTC day. Query: days (default 30).
+func (h *AIUsageHandler) Daily(c *fiber.Ctx) error {
+	return h.summary(c, services.AIUsageByDay)
+}
+
+// Providers summarizes AI calls per provider and model.
+func (h *AIUsageHandler) Providers(c *fiber.Ctx) error {
+	return h.summary(c, services.AIUsageByProvider)
+}
+
+// Features summarizes AI calls per feature (analysis, narrative, match).
+func (h *AIUsageHandler) Features(c *fiber.Ctx) error {
+	return h.summary(c, services.AIUsageByFeature)
+}
+
+// Users lists the users with the highest AI spend.
+func (h *AIUsageHandler) Users(c *fiber.Ctx) error {
+	return h.summary(c, services.AIUsageByUser)
+}
+
+func (h *AIUsageHandler) summary(c *fiber.Ctx, group string) error {
+	days, err := strconv.Atoi(c.Query("days", "0"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_usage_days", services.MaxAIUsageDays)})
+	}
+
+	summary, err := h.usage.Summary(group, days)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUsageDays) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_usage_days", services.MaxAIUsageDays)})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.ai_usage_fetch_failed")})
+	}
+	return c.JSON(summary)
+}
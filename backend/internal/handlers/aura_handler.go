@@ -1,12 +1,18 @@
 package handlers
 
 import (
-	"encoding/base64"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/middleware"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,11 +21,13 @@ import (
 // AuraHandler handles HTTP requests related to Aura scanning
 type AuraHandler struct {
 	auraService *services.AuraService
+	scanJobs    *services.ScanJobService
+	cards       *services.AuraCardService
 }
 
 // NewAuraHandler creates a new AuraHandler instance
-func NewAuraHandler(auraService *services.AuraService) *AuraHandler {
-	return &AuraHandler{auraService: auraService}
+func NewAuraHandler(auraService *services.AuraService, scanJobs *services.ScanJobService, cards *services.AuraCardService) *AuraHandler {
+	return &AuraHandler{auraService: auraService, scanJobs: scanJobs, cards: cards}
 }
 
 // CheckScanEligibility checks if the user can perform a scan
@@ -27,20 +35,24 @@ func (h *AuraHandler) CheckScanEligibility(c *fiber.Ctx) error {
 	userIDStr := c.Locals("userID").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
 	isSubscribed := h.auraService.IsSubscribed(userID)
 
-	allowed, remaining, err := h.auraService.CanScan(userID, isSubscribed)
+	quota, err := h.auraService.ScanQuota(userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check eligibility"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.eligibility_check_failed")})
 	}
 
 	return c.JSON(dto.ScanEligibilityResponse{
-		CanScan:      allowed,
-		Remaining:    remaining,
+		CanScan:      quota.Remaining != 0,
+		Remaining:    quota.Remaining,
 		IsSubscribed: isSubscribed,
+		Plan:         quota.Plan,
+		Limit:        quota.Limit,
+		Used:         quota.Used,
+		ResetsAt:     quota.ResetsAt,
 	})
 }
 
@@ -49,41 +61,52 @@ func (h *AuraHandler) Scan(c *fiber.Ctx) error {
 	userIDStr := c.Locals("userID").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
-	}
-
-	// Rate limit check
-	isSubscribed := h.auraService.IsSubscribed(userID)
-	allowed, _, err := h.auraService.CanScan(userID, isSubscribed)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify scan eligibility"})
-	}
-	if !allowed {
-		return c.Status(429).JSON(fiber.Map{"error": "Daily scan limit reached. Upgrade to Premium for unlimited scans."})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
 	// Parse request
 	var req dto.CreateAuraRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_request_body")})
 	}
 
 	// Validate base64 size (max ~3MB base64 = ~2.25MB image)
 	if req.ImageData != "" && len(req.ImageData) > 3*1024*1024 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Image data too large. Maximum 3MB base64."})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.image_data_too_large")})
 	}
 
 	if req.ImageData == "" && req.ImageURL == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Either image_data or image_url is required"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.image_required")})
+	}
+
+	// Group mode: one reading per person in the photo, counted as a single scan
+	if c.QueryBool("group") {
+		if c.QueryBool("async") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.group_scan_async_unsupported")})
+		}
+		group, err := h.auraService.CreateGroup(userID, req, middleware.Localizer(c))
+		if err != nil {
+			return scanError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(group)
+	}
+
+	// Async mode: queue the scan and let the client poll or stream the job
+	if c.QueryBool("async") {
+		job, err := h.scanJobs.Enqueue(userID, req, middleware.Localizer(c))
+		if err != nil {
+			return scanError(c, err)
+		}
+		return jobAccepted(c, job)
 	}
 
 	// Create aura reading
-	reading, err := h.auraService.Create(userID, req)
+	reading, err := h.auraService.Create(userID, req, middleware.Localizer(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return scanError(c, err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(reading)
+	return c.Status(scanStatus(reading)).JSON(reading)
 }
 
 // ScanWithUpload handles multipart form upload for aura scan
@@ -91,61 +114,164 @@ func (h *AuraHandler) ScanWithUpload(c *fiber.Ctx) error {
 	userIDStr := c.Locals("userID").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
-	}
-
-	// Rate limit check
-	isSubscribed := h.auraService.IsSubscribed(userID)
-	allowed, _, err := h.auraService.CanScan(userID, isSubscribed)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify scan eligibility"})
-	}
-	if !allowed {
-		return c.Status(429).JSON(fiber.Map{"error": "Daily scan limit reached. Upgrade to Premium for unlimited scans."})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
 	// Get file from form
 	file, err := c.FormFile("image")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Image file is required"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.image_file_required")})
 	}
 
 	// Validate file type
 	contentType := file.Header.Get("Content-Type")
 	if !strings.HasPrefix(contentType, "image/jpeg") && !strings.HasPrefix(contentType, "image/png") {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Only JPEG and PNG images are supported"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.image_type_unsupported")})
 	}
 
 	// Validate file size (4MB max)
 	if file.Size > 4*1024*1024 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Image too large. Maximum 4MB."})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.image_too_large")})
 	}
 
 	// Read file content
 	f, err := file.Open()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read image"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.image_read_failed")})
 	}
 	defer f.Close()
 
 	fileBytes, err := io.ReadAll(f)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read image data"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.image_data_read_failed")})
 	}
 
-	// Encode to base64
-	b64Data := base64.StdEncoding.EncodeToString(fileBytes)
+	if c.QueryBool("group") {
+		if c.QueryBool("async") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.group_scan_async_unsupported")})
+		}
+		group, err := h.auraService.CreateGroupFromImage(userID, fileBytes, middleware.Localizer(c))
+		if err != nil {
+			return scanError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(group)
+	}
 
-	req := dto.CreateAuraRequest{
-		ImageData: b64Data,
+	if c.QueryBool("async") {
+		job, err := h.scanJobs.EnqueueImage(userID, fileBytes, middleware.Localizer(c))
+		if err != nil {
+			return scanError(c, err)
+		}
+		return jobAccepted(c, job)
 	}
 
-	reading, err := h.auraService.Create(userID, req)
+	reading, err := h.auraService.CreateFromImage(userID, fileBytes, middleware.Localizer(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return scanError(c, err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(reading)
+	return c.Status(scanStatus(reading)).JSON(reading)
+}
+
+// GetJob returns the status of an async scan, including the reading once it succeeds
+func (h *AuraHandler) GetJob(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_job_id")})
+	}
+
+	job, err := h.scanJobs.Get(userID, jobID)
+	if err != nil {
+		if errors.Is(err, services.ErrScanJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.job_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.job_fetch_failed")})
+	}
+
+	return c.JSON(job)
+}
+
+// JobEvents streams async scan progress as Server-Sent Events. Each event carries the
+// job as JSON; the stream ends after a "done" or "failed" event.
+func (h *AuraHandler) JobEvents(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_job_id")})
+	}
+
+	job, err := h.scanJobs.Get(userID, jobID)
+	if err != nil {
+		if errors.Is(err, services.ErrScanJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.job_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.job_fetch_failed")})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	updates, unsubscribe := h.scanJobs.Subscribe(jobID)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		last := *job
+		if writeJobEvent(w, &last) != nil || jobFinished(&last) {
+			return
+		}
+
+		// Polling covers jobs processed by another instance, whose updates never reach
+		// this process's subscribers.
+		poll := time.NewTicker(2 * time.Second)
+		defer poll.Stop()
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+		deadline := time.NewTimer(5 * time.Minute)
+		defer deadline.Stop()
+
+		for {
+			var next *models.ScanJob
+			select {
+			case update := <-updates:
+				next = &update
+			case <-poll.C:
+				fresh, err := h.scanJobs.Get(userID, jobID)
+				if err != nil {
+					return
+				}
+				if fresh.Status == last.Status && fresh.Stage == last.Stage && fresh.Progress == last.Progress {
+					continue
+				}
+				next = fresh
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil || w.Flush() != nil {
+					return
+				}
+				continue
+			case <-deadline.C:
+				return
+			}
+
+			last = *next
+			if writeJobEvent(w, &last) != nil || jobFinished(&last) {
+				return
+			}
+		}
+	})
+	return nil
 }
 
 // GetByID retrieves a single aura reading
@@ -153,77 +279,402 @@ func (h *AuraHandler) GetByID(c *fiber.Ctx) error {
 	userIDStr := c.Locals("userID").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
 	readingID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid reading ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_id")})
 	}
 
 	reading, err := h.auraService.GetByID(userID, readingID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Reading not found"})
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.reading_not_found")})
 	}
 
 	return c.JSON(reading)
 }
 
-// List returns paginated aura readings for the user
+// Compare diffs two of the user's readings, from a to b, with a written explanation.
+// Query: a, b (reading IDs), ai=true to also ask the AI providers for an explanation.
+func (h *AuraHandler) Compare(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	aID, err1 := uuid.Parse(c.Query("a"))
+	bID, err2 := uuid.Parse(c.Query("b"))
+	if err1 != nil || err2 != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_id")})
+	}
+
+	comparison, err := h.auraService.Compare(userID, aID, bID, c.QueryBool("ai"), middleware.Localizer(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCompareSameReading):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.compare_same_reading")})
+		case errors.Is(err, services.ErrReadingNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.reading_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.compare_failed")})
+	}
+
+	return c.JSON(dto.AuraComparisonResponse{
+		A:             readingResponse(*comparison.A),
+		B:             readingResponse(*comparison.B),
+		Changes:       comparison.Changes,
+		Explanation:   comparison.Explanation,
+		AIExplanation: comparison.AIExplanation,
+	})
+}
+
+// Card renders a shareable PNG card for a reading.
+// Query: size=story (1080x1920, default) or square (1080x1080).
+func (h *AuraHandler) Card(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	readingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_id")})
+	}
+
+	card, err := h.cards.Render(userID, readingID, c.Query("size"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCardSize):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_card_size")})
+		case errors.Is(err, services.ErrReadingNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.reading_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.card_render_failed")})
+	}
+
+	c.Set("ETag", card.ETag)
+	c.Set("Cache-Control", "private, max-age=3600")
+	if c.Get("If-None-Match") == card.ETag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set("Content-Type", "image/png")
+	return c.Send(card.PNG)
+}
+
+// List returns a page of the user's aura readings, newest first
 func (h *AuraHandler) List(c *fiber.Ctx) error {
 	userIDStr := c.Locals("userID").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("page_size", "20"))
+	var query dto.AuraListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_filter")})
+	}
 
-	readings, total, err := h.auraService.List(userID, page, pageSize)
+	page, err := h.auraService.List(userID, query)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch readings"})
+		switch {
+		case errors.Is(err, services.ErrInvalidCursor):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_cursor")})
+		case errors.Is(err, services.ErrInvalidReadingFilter):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_filter")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.readings_fetch_failed")})
 	}
 
-	items := make([]dto.AuraReadingResponse, 0, len(readings))
-	for _, r := range readings {
-		items = append(items, dto.AuraReadingResponse{
-			ID:             r.ID,
-			UserID:         r.UserID,
-			AuraColor:      r.AuraColor,
-			SecondaryColor: r.SecondaryColor,
-			EnergyLevel:    r.EnergyLevel,
-			MoodScore:      r.MoodScore,
-			Personality:    r.Personality,
-			Strengths:      r.Strengths,
-			Challenges:     r.Challenges,
-			DailyAdvice:    r.DailyAdvice,
-			ImageURL:       r.ImageURL,
-			AnalyzedAt:     r.AnalyzedAt,
-			CreatedAt:      r.CreatedAt,
-		})
+	items := make([]dto.AuraReadingResponse, 0, len(page.Readings))
+	for _, r := range page.Readings {
+		items = append(items, readingResponse(r))
 	}
 
 	return c.JSON(dto.AuraListResponse{
 		Data:       items,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalCount: total,
+		PageSize:   page.PageSize,
+		NextCursor: page.NextCursor,
+		HasMore:    page.NextCursor != "",
+		TotalCount: page.TotalCount,
 	})
 }
 
+// Delete moves a reading to recently deleted, where it can be restored until it is purged
+func (h *AuraHandler) Delete(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	readingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_id")})
+	}
+
+	if err := h.auraService.Delete(userID, readingID); err != nil {
+		return deleteError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// BulkDelete deletes readings by ID list and/or creation date range
+func (h *AuraHandler) BulkDelete(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	var req dto.BulkDeleteAuraRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_request_body")})
+	}
+
+	deleted, err := h.auraService.DeleteMany(userID, req)
+	if err != nil {
+		return deleteError(c, err)
+	}
+
+	return c.JSON(dto.BulkDeleteAuraResponse{Deleted: deleted})
+}
+
+// ListDeleted returns the user's recently deleted readings that can still be restored
+func (h *AuraHandler) ListDeleted(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	readings, err := h.auraService.ListDeleted(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.readings_fetch_failed")})
+	}
+
+	items := make([]dto.DeletedAuraReadingResponse, 0, len(readings))
+	for _, r := range readings {
+		items = append(items, dto.DeletedAuraReadingResponse{
+			AuraReadingResponse: readingResponse(r),
+			DeletedAt:           r.DeletedAt.Time,
+			PurgeAt:             h.auraService.PurgeAt(r.DeletedAt.Time),
+		})
+	}
+
+	return c.JSON(dto.DeletedAuraListResponse{Data: items})
+}
+
+// Restore brings a reading back from recently deleted
+func (h *AuraHandler) Restore(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
+	}
+
+	readingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_id")})
+	}
+
+	reading, err := h.auraService.Restore(userID, readingID)
+	if err != nil {
+		if errors.Is(err, services.ErrReadingNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.deleted_reading_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.reading_restore_failed")})
+	}
+
+	return c.JSON(reading)
+}
+
 // Stats returns aggregated stats for the user's aura readings
 func (h *AuraHandler) Stats(c *fiber.Ctx) error {
 	userIDStr := c.Locals("userID").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
 	stats, err := h.auraService.GetStats(userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch stats"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.stats_fetch_failed")})
+	}
+
+	return c.JSON(stats)
+}
+
+// Trends returns energy, mood and dominant color over time.
+// Query: interval=day|week|month, periods (bucket count), window (moving average size).
+func (h *AuraHandler) Trends(c *fiber.Ctx) error {
+	userIDStr := c.Locals("userID").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_user_id")})
 	}
 
+	periods, err1 := strconv.Atoi(c.Query("periods", "0"))
+	window, err2 := strconv.Atoi(c.Query("window", "0"))
+	if err1 != nil || err2 != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_trend_range")})
+	}
+
+	trends, err := h.auraService.GetTrends(userID, c.Query("interval"), periods, window)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTrendInterval):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_trend_interval")})
+		case errors.Is(err, services.ErrInvalidTrendRange):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_trend_range")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.stats_fetch_failed")})
+	}
+
+	return c.JSON(trends)
+}
+
+// ProviderHealth lists AI provider circuit breaker state (admin only)
+func (h *AuraHandler) ProviderHealth(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"providers": h.auraService.ProviderHealth()})
+}
+
+// AnalysisCache reports analysis cache hits and misses for cost tracking (admin only)
+func (h *AuraHandler) AnalysisCache(c *fiber.Ctx) error {
+	stats, err := h.auraService.AnalysisCacheStats()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.analysis_cache_stats_failed")})
+	}
 	return c.JSON(stats)
 }
+
+// deleteError maps AuraService deletion errors to HTTP responses.
+func deleteError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrReadingNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": tr(c, "errors.reading_not_found")})
+	case errors.Is(err, services.ErrInvalidReadingID):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_reading_id")})
+	case errors.Is(err, services.ErrBulkDeleteEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.bulk_delete_empty")})
+	case errors.Is(err, services.ErrBulkDeleteTooBig):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.bulk_delete_too_many", services.MaxBulkDeleteIDs)})
+	case errors.Is(err, services.ErrInvalidDateRange):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.invalid_date_range")})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.reading_delete_failed")})
+}
+
+func readingResponse(r models.AuraReading) dto.AuraReadingResponse {
+	return dto.AuraReadingResponse{
+		ID:              r.ID,
+		UserID:          r.UserID,
+		AuraColor:       r.AuraColor,
+		SecondaryColor:  r.SecondaryColor,
+		EnergyLevel:     r.EnergyLevel,
+		MoodScore:       r.MoodScore,
+		Personality:     r.Personality,
+		Strengths:       r.Strengths,
+		Challenges:      r.Challenges,
+		DailyAdvice:     r.DailyAdvice,
+		NarrativeSource: r.NarrativeSource,
+		Language:        r.Language,
+		ImageURL:        r.ImageURL,
+		ThumbnailURL:    r.ThumbnailURL,
+		AnalyzedAt:      r.AnalyzedAt,
+		CreatedAt:       r.CreatedAt,
+		GroupScanID:     r.GroupScanID,
+	}
+}
+
+// scanError maps AuraService scan errors to HTTP responses. Quality rejections use 422
+// with the same issue codes as the mobile gate so clients can show their own guidance;
+// an exhausted daily quota is 429, and a group photo without at least two people is 422.
+func scanError(c *fiber.Ctx, err error) error {
+	var qErr *services.ImageQualityError
+	if errors.As(err, &qErr) {
+		issues := make([]string, len(qErr.Result.Issues))
+		for i, code := range qErr.Result.Issues {
+			issues[i] = string(code)
+		}
+		message := qErr.Result.Message
+		if len(issues) > 0 {
+			message = tr(c, "quality."+issues[0])
+		}
+		m := qErr.Result.Metrics
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ImageQualityRejection{
+			Error:   message,
+			Code:    "IMAGE_QUALITY",
+			Message: message,
+			Score:   qErr.Result.Score,
+			Issues:  issues,
+			Metrics: dto.ImageQualityMetric{
+				Brightness:   m.Brightness,
+				Contrast:     m.Contrast,
+				BlurVariance: m.BlurVariance,
+				ShortSide:    m.ShortSide,
+			},
+		})
+	}
+	if errors.Is(err, services.ErrQuotaExceeded) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": tr(c, "errors.scan_limit_reached")})
+	}
+	if errors.Is(err, services.ErrInvalidImage) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "scan.invalid_image")})
+	}
+	if errors.Is(err, services.ErrImageRequired) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.image_required")})
+	}
+	if errors.Is(err, services.ErrGroupImageRequired) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tr(c, "errors.group_image_required")})
+	}
+	if errors.Is(err, services.ErrNoGroupFound) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": tr(c, "errors.group_not_found")})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": tr(c, "errors.scan_failed")})
+}
+
+// scanStatus is 201 for a new reading and 200 when a duplicate photo returned an earlier one.
+func scanStatus(reading *models.AuraReading) int {
+	if reading.Reused {
+		return fiber.StatusOK
+	}
+	return fiber.StatusCreated
+}
+
+// jobAccepted answers an async scan request with 202 and where to follow the job.
+func jobAccepted(c *fiber.Ctx, job *models.ScanJob) error {
+	location := "/api/aura/jobs/" + job.ID.String()
+	c.Location(location)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"job":        job,
+		"status_url": location,
+		"events_url": location + "/events",
+	})
+}
+
+func jobFinished(job *models.ScanJob) bool {
+	return job.Status == services.ScanJobSucceeded || job.Status == services.ScanJobFailed
+}
+
+func writeJobEvent(w *bufio.Writer, job *models.ScanJob) error {
+	event := "progress"
+	switch job.Status {
+	case services.ScanJobSucceeded:
+		event = "done"
+	case services.ScanJobFailed:
+		event = "failed"
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
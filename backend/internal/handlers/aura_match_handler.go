@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/middleware"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,17 +23,20 @@ func (h *AuraMatchHandler) CreateMatch(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	parsedUserID, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "Invalid user ID"})
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_user_id")})
 	}
 
 	var req dto.CreateMatchRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_request_body")})
 	}
 
-	match, err := h.matchService.Create(parsedUserID, req)
+	match, err := h.matchService.Create(parsedUserID, req, middleware.Localizer(c))
+	if errors.Is(err, services.ErrMatchNotFriends) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": matchErrorMessage(c, err)})
+	}
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": matchErrorMessage(c, err)})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(match)
@@ -40,12 +46,12 @@ func (h *AuraMatchHandler) GetMatches(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	parsedUserID, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "Invalid user ID"})
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_user_id")})
 	}
 
 	matches, err := h.matchService.List(parsedUserID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch matches"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": tr(c, "errors.matches_fetch_failed")})
 	}
 
 	return c.JSON(fiber.Map{"data": matches})
@@ -55,22 +61,39 @@ func (h *AuraMatchHandler) GetMatchByFriend(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	parsedUserID, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "Invalid user ID"})
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_user_id")})
 	}
 
 	friendIDStr := c.Params("friend_id")
 	friendID, err := uuid.Parse(friendIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid friend ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_friend_id")})
 	}
 
 	match, err := h.matchService.GetByFriend(parsedUserID, friendID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "No match found with this friend"})
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": tr(c, "errors.match_not_found")})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch match"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": tr(c, "errors.match_fetch_failed")})
 	}
 
 	return c.JSON(match)
 }
+
+// matchErrorMessage translates the reasons a match cannot be created.
+func matchErrorMessage(c *fiber.Ctx, err error) string {
+	switch {
+	case errors.Is(err, services.ErrInvalidFriendID):
+		return tr(c, "errors.invalid_friend_id")
+	case errors.Is(err, services.ErrSelfMatch):
+		return tr(c, "errors.self_match")
+	case errors.Is(err, services.ErrNoAuraReading):
+		return tr(c, "errors.own_reading_required")
+	case errors.Is(err, services.ErrFriendNoAuraReading):
+		return tr(c, "errors.friend_reading_missing")
+	case errors.Is(err, services.ErrMatchNotFriends):
+		return tr(c, "errors.match_friends_only")
+	}
+	return tr(c, "errors.match_create_failed")
+}
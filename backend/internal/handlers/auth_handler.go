@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,15 +24,18 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req dto.RegisterRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
 	}
 
-	resp, err := h.authService.Register(&req)
+	resp, err := h.authService.Register(&req, c.IP())
 	if err != nil {
 		if errors.Is(err, services.ErrEmailTaken) {
-			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.email_taken")})
 		}
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+		if errors.Is(err, services.ErrWeakCredentials) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.weak_credentials")})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.registration_failed")})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(resp)
@@ -40,15 +45,15 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req dto.LoginRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
 	}
 
 	resp, err := h.authService.Login(&req)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_credentials")})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: "Login failed"})
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.login_failed")})
 	}
 
 	return c.JSON(resp)
@@ -58,26 +63,29 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 func (h *AuthHandler) ClaimGuest(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: "Unauthorized"})
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.unauthorized")})
 	}
 
 	var req dto.ClaimGuestRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
 	}
 
 	resp, err := h.authService.ClaimGuest(userID, &req)
 	if err != nil {
 		if errors.Is(err, services.ErrEmailTaken) {
-			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.email_taken")})
 		}
 		if errors.Is(err, services.ErrGuestOnlyAction) {
-			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.guest_only")})
+		}
+		if errors.Is(err, services.ErrWeakCredentials) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.weak_credentials")})
 		}
 		if errors.Is(err, services.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: "User not found"})
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.user_not_found")})
 		}
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.claim_failed")})
 	}
 
 	return c.JSON(resp)
@@ -87,15 +95,15 @@ func (h *AuthHandler) ClaimGuest(c *fiber.Ctx) error {
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	var req dto.RefreshRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
 	}
 
 	resp, err := h.authService.Refresh(&req)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidToken) {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_refresh_token")})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: "Token refresh failed"})
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.token_refresh_failed")})
 	}
 
 	return c.JSON(resp)
@@ -105,21 +113,21 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	var req dto.LogoutRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
 	}
 
 	if err := h.authService.Logout(&req); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: "Logout failed"})
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.logout_failed")})
 	}
 
-	return c.JSON(fiber.Map{"message": "Logged out successfully"})
+	return c.JSON(fiber.Map{"message": tr(c, "messages.logged_out")})
 }
 
 // DeleteAccount implements Apple Guideline 5.1.1
 func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: "Unauthorized"})
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.unauthorized")})
 	}
 
 	var body struct {
@@ -129,27 +137,27 @@ func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 
 	if err := h.authService.DeleteAccount(userID, body.Password); err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: "Invalid password"})
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_password")})
 		}
 		if errors.Is(err, services.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: "User not found"})
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.user_not_found")})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: "Failed to delete account"})
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.account_delete_failed")})
 	}
 
-	return c.JSON(fiber.Map{"message": "Account deleted successfully"})
+	return c.JSON(fiber.Map{"message": tr(c, "messages.account_deleted")})
 }
 
 // AppleSignIn handles Sign in with Apple (Guideline 4.8)
 func (h *AuthHandler) AppleSignIn(c *fiber.Ctx) error {
 	var req dto.AppleSignInRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: "Invalid request body"})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
 	}
 
-	resp, err := h.authService.AppleSignIn(&req)
+	resp, err := h.authService.AppleSignIn(&req, c.IP())
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: err.Error()})
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.apple_sign_in_failed")})
 	}
 
 	return c.JSON(resp)
@@ -159,16 +167,50 @@ func (h *AuthHandler) AppleSignIn(c *fiber.Ctx) error {
 func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: "Unauthorized"})
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.unauthorized")})
 	}
 
 	profile, err := h.authService.GetProfile(userID)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: "User not found"})
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.user_not_found")})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: "Failed to fetch profile"})
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.profile_fetch_failed")})
 	}
 
 	return c.JSON(profile)
 }
+
+// UpdateProfile updates the user's preferences, such as their timezone and language
+func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.unauthorized")})
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
+	}
+
+	profile, err := h.authService.UpdateProfile(userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidLanguage) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_language", strings.Join(i18n.Supported(), ", "))})
+		}
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_timezone")})
+		}
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.user_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.profile_update_failed")})
+	}
+
+	return c.JSON(profile)
+}
+
+// PreferredLanguage looks up the user's saved language for the locale middleware
+func (h *AuthHandler) PreferredLanguage(userID string) string {
+	return h.authService.PreferredLanguage(userID)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ColorCatalogHandler serves the admin endpoints for the versioned aura palette.
+type ColorCatalogHandler struct {
+	colorCatalogService *services.ColorCatalogService
+}
+
+func NewColorCatalogHandler(colorCatalogService *services.ColorCatalogService) *ColorCatalogHandler {
+	return &ColorCatalogHandler{colorCatalogService: colorCatalogService}
+}
+
+// Active returns the palette currently used for readings, matches and streaks.
+func (h *ColorCatalogHandler) Active(c *fiber.Ctx) error {
+	catalog, err := h.colorCatalogService.Active()
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.JSON(catalog)
+}
+
+// ListVersions returns every catalog version without its colors.
+func (h *ColorCatalogHandler) ListVersions(c *fiber.Ctx) error {
+	catalogs, err := h.colorCatalogService.ListVersions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: tr(c, "errors.color_catalog_fetch_failed"),
+		})
+	}
+	return c.JSON(fiber.Map{"versions": catalogs})
+}
+
+// GetVersion returns one version and anything that would stop it being published.
+func (h *ColorCatalogHandler) GetVersion(c *fiber.Ctx) error {
+	version, ok := catalogVersion(c)
+	if !ok {
+		return invalidCatalogVersion(c)
+	}
+	catalog, err := h.colorCatalogService.GetVersion(version)
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.JSON(h.response(catalog))
+}
+
+// CreateDraft starts a new draft, copied from the active palette unless colors are sent.
+func (h *ColorCatalogHandler) CreateDraft(c *fiber.Ctx) error {
+	var req dto.CreateColorCatalogRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: tr(c, "errors.invalid_request_body"),
+			})
+		}
+	}
+
+	catalog, err := h.colorCatalogService.CreateDraft(req)
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.response(catalog))
+}
+
+// UpdateDraft replaces a draft's note and/or its full color list.
+func (h *ColorCatalogHandler) UpdateDraft(c *fiber.Ctx) error {
+	version, ok := catalogVersion(c)
+	if !ok {
+		return invalidCatalogVersion(c)
+	}
+	var req dto.UpdateColorCatalogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: tr(c, "errors.invalid_request_body"),
+		})
+	}
+
+	catalog, err := h.colorCatalogService.UpdateDraft(version, req)
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.JSON(h.response(catalog))
+}
+
+// DeleteDraft removes an unpublished version.
+func (h *ColorCatalogHandler) DeleteDraft(c *fiber.Ctx) error {
+	version, ok := catalogVersion(c)
+	if !ok {
+		return invalidCatalogVersion(c)
+	}
+	if err := h.colorCatalogService.DeleteDraft(version); err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UpsertColor adds or replaces one color in a draft.
+func (h *ColorCatalogHandler) UpsertColor(c *fiber.Ctx) error {
+	version, ok := catalogVersion(c)
+	if !ok {
+		return invalidCatalogVersion(c)
+	}
+	var color models.ColorDefinition
+	if err := c.BodyParser(&color); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: tr(c, "errors.invalid_request_body"),
+		})
+	}
+
+	catalog, err := h.colorCatalogService.UpsertColor(version, c.Params("key"), color)
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.JSON(h.response(catalog))
+}
+
+// DeleteColor removes one color from a draft.
+func (h *ColorCatalogHandler) DeleteColor(c *fiber.Ctx) error {
+	version, ok := catalogVersion(c)
+	if !ok {
+		return invalidCatalogVersion(c)
+	}
+	catalog, err := h.colorCatalogService.DeleteColor(version, c.Params("key"))
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.JSON(h.response(catalog))
+}
+
+// Publish makes a version the live palette. Publishing a retired version rolls back to it.
+func (h *ColorCatalogHandler) Publish(c *fiber.Ctx) error {
+	version, ok := catalogVersion(c)
+	if !ok {
+		return invalidCatalogVersion(c)
+	}
+	catalog, err := h.colorCatalogService.Publish(version)
+	if err != nil {
+		return h.catalogError(c, err)
+	}
+	return c.JSON(h.response(catalog))
+}
+
+func (h *ColorCatalogHandler) response(catalog *models.ColorCatalog) dto.ColorCatalogResponse {
+	return dto.ColorCatalogResponse{Catalog: catalog, Problems: h.colorCatalogService.Problems(catalog)}
+}
+
+func (h *ColorCatalogHandler) catalogError(c *fiber.Ctx, err error) error {
+	var invalid *services.ColorCatalogError
+	switch {
+	case errors.As(err, &invalid):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ColorCatalogInvalidResponse{
+			Error: true, Message: tr(c, "errors.color_catalog_invalid"), Problems: invalid.Problems,
+		})
+	case errors.Is(err, services.ErrColorCatalogNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: tr(c, "errors.color_catalog_not_found"),
+		})
+	case errors.Is(err, services.ErrColorCatalogPublished):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+			Error: true, Message: tr(c, "errors.color_catalog_published"),
+		})
+	case errors.Is(err, services.ErrColorNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: tr(c, "errors.color_not_found"),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: tr(c, "errors.color_catalog_save_failed"),
+	})
+}
+
+func catalogVersion(c *fiber.Ctx) (int, bool) {
+	version, err := strconv.Atoi(c.Params("version"))
+	return version, err == nil && version > 0
+}
+
+func invalidCatalogVersion(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+		Error: true, Message: tr(c, "errors.invalid_color_catalog_version"),
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// FriendHandler manages friend requests and the friend list
+type FriendHandler struct {
+	friends *services.FriendService
+}
+
+func NewFriendHandler(friends *services.FriendService) *FriendHandler {
+	return &FriendHandler{friends: friends}
+}
+
+// List returns the user's accepted friends
+func (h *FriendHandler) List(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	friends, err := h.friends.List(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.friends_fetch_failed")})
+	}
+	return c.JSON(dto.FriendListResponse{Data: friends})
+}
+
+// Requests returns the user's pending friend requests, received and sent
+func (h *FriendHandler) Requests(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	requests, err := h.friends.Requests(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.friends_fetch_failed")})
+	}
+	return c.JSON(requests)
+}
+
+// SendRequest asks another user to be friends. When they had already asked the user, the
+// two become friends straight away.
+func (h *FriendHandler) SendRequest(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	var req dto.SendFriendRequest
+	if err := c.BodyParser(&req); err != nil || req.UserID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
+	}
+
+	friendship, err := h.friends.SendRequest(userID, req.UserID)
+	if err != nil {
+		return h.friendError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(dto.FriendRequestResponse{
+		ID:        friendship.ID,
+		UserID:    req.UserID,
+		Status:    friendship.Status,
+		CreatedAt: friendship.CreatedAt,
+	})
+}
+
+// Accept accepts a friend request sent to the user
+func (h *FriendHandler) Accept(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidFriendRequestID(c)
+	}
+
+	friendship, err := h.friends.Accept(userID, requestID)
+	if err != nil {
+		return h.friendError(c, err)
+	}
+	return c.JSON(dto.FriendResponse{UserID: friendship.RequesterID, FriendsSince: *friendship.AcceptedAt})
+}
+
+// Decline turns down a friend request sent to the user
+func (h *FriendHandler) Decline(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidFriendRequestID(c)
+	}
+
+	if err := h.friends.Decline(userID, requestID); err != nil {
+		return h.friendError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": tr(c, "messages.friend_request_declined")})
+}
+
+// Cancel withdraws a friend request the user sent
+func (h *FriendHandler) Cancel(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidFriendRequestID(c)
+	}
+
+	if err := h.friends.Cancel(userID, requestID); err != nil {
+		return h.friendError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": tr(c, "messages.friend_request_cancelled")})
+}
+
+// Remove ends a friendship
+func (h *FriendHandler) Remove(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+	friendID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_friend_id")})
+	}
+
+	if err := h.friends.Remove(userID, friendID); err != nil {
+		return h.friendError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": tr(c, "messages.friend_removed")})
+}
+
+func (h *FriendHandler) friendError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSelfFriendRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.self_friend_request")})
+	case errors.Is(err, services.ErrFriendUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.user_not_found")})
+	case errors.Is(err, services.ErrFriendRequestExists):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.friend_request_exists")})
+	case errors.Is(err, services.ErrAlreadyFriends):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.already_friends")})
+	case errors.Is(err, services.ErrTooManyFriendRequests):
+		return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.too_many_friend_requests")})
+	case errors.Is(err, services.ErrFriendRequestNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.friend_request_not_found")})
+	case errors.Is(err, services.ErrNotFriends):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.not_friends")})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.friend_update_failed")})
+}
+
+func invalidFriendRequestID(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_friend_request_id")})
+}
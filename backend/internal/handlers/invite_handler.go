@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// invitePath is where invite links point. The app claims it as a universal link, so a
+// link opens the app when it is installed and the public invite page otherwise.
+const invitePath = "/api/invite/"
+
+// appScheme is the app's custom URL scheme, used by the invite page's open button.
+const appScheme = "aurasnap"
+
+// InviteHandler manages friend invites, redeems them, and serves the files that let
+// invite links open the app.
+type InviteHandler struct {
+	invites                 *services.InviteService
+	baseURL                 string
+	appleAppIDs             []string
+	androidPackage          string
+	androidCertFingerprints []string
+}
+
+func NewInviteHandler(invites *services.InviteService, cfg *config.Config) *InviteHandler {
+	return &InviteHandler{
+		invites:                 invites,
+		baseURL:                 strings.TrimRight(cfg.PublicBaseURL, "/"),
+		appleAppIDs:             splitList(cfg.AppleAppIDs),
+		androidPackage:          cfg.AndroidPackage,
+		androidCertFingerprints: splitList(cfg.AndroidCertFingerprints),
+	}
+}
+
+// Create mints an invite code and link for the user
+func (h *InviteHandler) Create(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	var req dto.CreateInviteRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
+		}
+	}
+
+	invite, err := h.invites.Create(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInviteUses):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_invite_uses", services.MaxInviteUses)})
+		case errors.Is(err, services.ErrInvalidInviteExpiry):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_invite_expiry", services.MaxInviteExpiryDays)})
+		case errors.Is(err, services.ErrTooManyInvites):
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.too_many_invites")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invite_create_failed")})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.inviteResponse(c, services.InviteSummary{Invite: *invite}))
+}
+
+// List returns the user's invites with how many signups each brought in
+func (h *InviteHandler) List(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	invites, err := h.invites.List(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invites_fetch_failed")})
+	}
+	data := make([]dto.InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		data = append(data, h.inviteResponse(c, invite))
+	}
+	return c.JSON(dto.InviteListResponse{Data: data})
+}
+
+// Revoke stops one of the user's invites from being redeemed
+func (h *InviteHandler) Revoke(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_invite_id")})
+	}
+
+	if err := h.invites.Revoke(userID, id); err != nil {
+		if errors.Is(err, services.ErrInviteNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invite_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invite_update_failed")})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Redeem makes the user friends with whoever shared the code
+func (h *InviteHandler) Redeem(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	var req dto.RedeemInviteRequest
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
+	}
+
+	friendship, err := h.invites.Redeem(userID, req.Code, c.IP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInviteRateLimited):
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invite_rate_limited")})
+		case errors.Is(err, services.ErrInviteNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invite_not_found")})
+		case errors.Is(err, services.ErrOwnInvite):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.own_invite")})
+		case errors.Is(err, services.ErrAlreadyFriends):
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.already_friends")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invite_redeem_failed")})
+	}
+	friendID := friendship.RequesterID
+	if friendID == userID {
+		friendID = friendship.AddresseeID
+	}
+	return c.JSON(dto.FriendResponse{UserID: friendID, FriendsSince: *friendship.AcceptedAt})
+}
+
+// Page is where an invite link lands when the app is not installed. It only echoes the
+// code so the visitor can enter it after installing; it never looks the code up, so it
+// cannot be used to test codes without the redemption limit.
+func (h *InviteHandler) Page(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/html; charset=utf-8")
+	e := html.EscapeString
+
+	code, ok := services.NormalizeInviteCode(c.Params("code"))
+	if !ok {
+		title := e(tr(c, "invite.unavailable_title"))
+		body := e(tr(c, "invite.unavailable_body"))
+		return c.Status(fiber.StatusNotFound).SendString(`<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1"><meta name="robots" content="noindex"><title>` + title + ` - AuraSnap</title><style>` + sharePageStyle + `</style></head><body><h1>` + title + `</h1><p>` + body + `</p></body></html>`)
+	}
+
+	display := services.FormatInviteCode(code)
+	title := tr(c, "invite.title")
+	description := tr(c, "invite.body", display)
+	appURL := appScheme + "://invite/" + url.PathEscape(code)
+
+	page := fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1"><meta name="robots" content="noindex"><title>%[1]s</title><meta name="description" content="%[2]s"><meta property="og:type" content="website"><meta property="og:site_name" content="AuraSnap"><meta property="og:title" content="%[1]s"><meta property="og:description" content="%[2]s"><meta property="og:url" content="%[3]s"><style>%[4]s</style></head><body><h1>%[1]s</h1><p>%[2]s</p><p><strong style="font-size:2em;letter-spacing:.1em">%[5]s</strong></p><p><a href="%[6]s">%[7]s</a></p></body></html>`,
+		e(title), e(description), e(h.inviteURL(c, code)), sharePageStyle, e(display), e(appURL), e(tr(c, "invite.open_app")))
+	return c.SendString(page)
+}
+
+// AppleAppSiteAssociation tells iOS which app may open invite links. It is not found
+// until APPLE_APP_IDS is configured.
+func (h *InviteHandler) AppleAppSiteAssociation(c *fiber.Ctx) error {
+	if len(h.appleAppIDs) == 0 {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(fiber.Map{
+		"applinks": fiber.Map{
+			"apps": []string{},
+			"details": []fiber.Map{{
+				"appIDs":     h.appleAppIDs,
+				"components": []fiber.Map{{"/": invitePath + "*"}},
+			}},
+		},
+	})
+}
+
+// AssetLinks tells Android which app may open invite links. It is not found until
+// ANDROID_CERT_FINGERPRINTS is configured.
+func (h *InviteHandler) AssetLinks(c *fiber.Ctx) error {
+	if h.androidPackage == "" || len(h.androidCertFingerprints) == 0 {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON([]fiber.Map{{
+		"relation": []string{"delegate_permission/common.handle_all_urls"},
+		"target": fiber.Map{
+			"namespace":                "android_app",
+			"package_name":             h.androidPackage,
+			"sha256_cert_fingerprints": h.androidCertFingerprints,
+		},
+	}})
+}
+
+// inviteURL is the universal link for a code, built from PUBLIC_BASE_URL when set.
+func (h *InviteHandler) inviteURL(c *fiber.Ctx, code string) string {
+	base := h.baseURL
+	if base == "" {
+		base = c.BaseURL()
+	}
+	return base + invitePath + code
+}
+
+func (h *InviteHandler) inviteResponse(c *fiber.Ctx, summary services.InviteSummary) dto.InviteResponse {
+	invite := summary.Invite
+	return dto.InviteResponse{
+		ID:        invite.ID,
+		Code:      services.FormatInviteCode(invite.Code),
+		URL:       h.inviteURL(c, invite.Code),
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		Signups:   summary.Signups,
+		ExpiresAt: invite.ExpiresAt,
+		RevokedAt: invite.RevokedAt,
+		Active:    invite.RevokedAt == nil && invite.Uses < invite.MaxUses && time.Now().Before(invite.ExpiresAt),
+		CreatedAt: invite.CreatedAt,
+	}
+}
+
+func splitList(csv string) []string {
+	var out []string
+	for _, part := range strings.Split(csv, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
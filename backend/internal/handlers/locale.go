@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// tr translates a catalog key into the language negotiated for the request.
+func tr(c *fiber.Ctx, key string, args ...interface{}) string {
+	return middleware.Localizer(c).T(key, args...)
+}
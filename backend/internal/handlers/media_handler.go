@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MediaHandler serves stored scan photos through signed, expiring links
+type MediaHandler struct {
+	mediaService *services.MediaService
+}
+
+// NewMediaHandler creates a new MediaHandler instance
+func NewMediaHandler(mediaService *services.MediaService) *MediaHandler {
+	return &MediaHandler{mediaService: mediaService}
+}
+
+// Serve streams a blob when the link's signature and expiry are valid
+func (h *MediaHandler) Serve(c *fiber.Ctx) error {
+	key := c.Params("*")
+	expires := c.Query("expires")
+
+	data, contentType, err := h.mediaService.Open(key, expires, c.Query("sig"))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.media_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.media_load_failed")})
+	}
+
+	// Let clients cache until the link itself expires.
+	maxAge := 0
+	if exp, err := strconv.ParseInt(expires, 10, 64); err == nil {
+		if remaining := time.Until(time.Unix(exp, 0)); remaining > 0 {
+			maxAge = int(remaining.Seconds())
+		}
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	return c.Send(data)
+}
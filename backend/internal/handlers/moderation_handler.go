@@ -26,21 +26,31 @@ func (h *ModerationHandler) CreateReport(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
-			Error: true, Message: "Unauthorized",
+			Error: true, Message: tr(c, "errors.unauthorized"),
 		})
 	}
 
 	var req dto.CreateReportRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: "Invalid request body",
+			Error: true, Message: tr(c, "errors.invalid_request_body"),
 		})
 	}
 
 	report, err := h.moderationService.CreateReport(userID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidContentType) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: tr(c, "errors.report_invalid_content_type"),
+			})
+		}
+		if errors.Is(err, services.ErrReportReasonMissing) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: tr(c, "errors.report_reason_required"),
+			})
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: err.Error(),
+			Error: true, Message: tr(c, "errors.report_create_failed"),
 		})
 	}
 
@@ -52,29 +62,34 @@ func (h *ModerationHandler) BlockUser(c *fiber.Ctx) error {
 	blockerID, err := extractUserID(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
-			Error: true, Message: "Unauthorized",
+			Error: true, Message: tr(c, "errors.unauthorized"),
 		})
 	}
 
 	var req dto.BlockUserRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: "Invalid request body",
+			Error: true, Message: tr(c, "errors.invalid_request_body"),
 		})
 	}
 
 	if err := h.moderationService.BlockUser(blockerID, req.BlockedID); err != nil {
-		if errors.Is(err, services.ErrSelfBlock) || errors.Is(err, services.ErrAlreadyBlocked) {
+		if errors.Is(err, services.ErrSelfBlock) {
 			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
-				Error: true, Message: err.Error(),
+				Error: true, Message: tr(c, "errors.self_block"),
+			})
+		}
+		if errors.Is(err, services.ErrAlreadyBlocked) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+				Error: true, Message: tr(c, "errors.already_blocked"),
 			})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
-			Error: true, Message: "Failed to block user",
+			Error: true, Message: tr(c, "errors.block_failed"),
 		})
 	}
 
-	return c.JSON(fiber.Map{"message": "User blocked successfully"})
+	return c.JSON(fiber.Map{"message": tr(c, "messages.user_blocked")})
 }
 
 // UnblockUser removes a block.
@@ -82,7 +97,7 @@ func (h *ModerationHandler) UnblockUser(c *fiber.Ctx) error {
 	blockerID, err := extractUserID(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
-			Error: true, Message: "Unauthorized",
+			Error: true, Message: tr(c, "errors.unauthorized"),
 		})
 	}
 
@@ -90,17 +105,17 @@ func (h *ModerationHandler) UnblockUser(c *fiber.Ctx) error {
 	blockedID, err := uuid.Parse(blockedIDStr)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: "Invalid user ID",
+			Error: true, Message: tr(c, "errors.invalid_user_id"),
 		})
 	}
 
 	if err := h.moderationService.UnblockUser(blockerID, blockedID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
-			Error: true, Message: "Failed to unblock user",
+			Error: true, Message: tr(c, "errors.unblock_failed"),
 		})
 	}
 
-	return c.JSON(fiber.Map{"message": "User unblocked successfully"})
+	return c.JSON(fiber.Map{"message": tr(c, "messages.user_unblocked")})
 }
 
 // --- Admin endpoints ---
@@ -118,7 +133,7 @@ func (h *ModerationHandler) ListReports(c *fiber.Ctx) error {
 	reports, total, err := h.moderationService.ListReports(status, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
-			Error: true, Message: "Failed to fetch reports",
+			Error: true, Message: tr(c, "errors.reports_fetch_failed"),
 		})
 	}
 
@@ -136,29 +151,34 @@ func (h *ModerationHandler) ActionReport(c *fiber.Ctx) error {
 	reportID, err := uuid.Parse(reportIDStr)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: "Invalid report ID",
+			Error: true, Message: tr(c, "errors.invalid_report_id"),
 		})
 	}
 
 	var req dto.ActionReportRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: "Invalid request body",
+			Error: true, Message: tr(c, "errors.invalid_request_body"),
 		})
 	}
 
 	if err := h.moderationService.ActionReport(reportID, &req); err != nil {
 		if errors.Is(err, services.ErrReportNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
-				Error: true, Message: err.Error(),
+				Error: true, Message: tr(c, "errors.report_not_found"),
+			})
+		}
+		if errors.Is(err, services.ErrInvalidReportStatus) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: tr(c, "errors.report_invalid_status"),
 			})
 		}
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: err.Error(),
+			Error: true, Message: tr(c, "errors.report_update_failed"),
 		})
 	}
 
-	return c.JSON(fiber.Map{"message": "Report updated successfully"})
+	return c.JSON(fiber.Map{"message": tr(c, "messages.report_updated")})
 }
 
 // extractUserID gets the user UUID from the JWT claims in context.
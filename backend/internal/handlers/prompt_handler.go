@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PromptHandler serves the admin endpoints for prompt templates and their experiments.
+type PromptHandler struct {
+	prompts *services.PromptService
+}
+
+func NewPromptHandler(prompts *services.PromptService) *PromptHandler {
+	return &PromptHandler{prompts: prompts}
+}
+
+// List returns every prompt with its embedded default and active variants.
+func (h *PromptHandler) List(c *fiber.Ctx) error {
+	prompts, err := h.prompts.List()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.prompts_fetch_failed")})
+	}
+	return c.JSON(fiber.Map{"prompts": prompts})
+}
+
+// SaveVariant stores a new version of a variant and puts it in rotation.
+func (h *PromptHandler) SaveVariant(c *fiber.Ctx) error {
+	var req dto.SavePromptVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
+	}
+
+	row, err := h.prompts.SaveVariant(c.Params("name"), c.Params("variant"), req)
+	if err != nil {
+		var invalid *services.PromptTemplateError
+		switch {
+		case errors.As(err, &invalid):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.PromptInvalidResponse{
+				Error: true, Message: tr(c, "errors.prompt_invalid"), Problem: invalid.Problem,
+			})
+		case errors.Is(err, services.ErrInvalidPromptVariant):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_prompt_variant")})
+		case errors.Is(err, services.ErrInvalidPromptWeight):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_prompt_weight", services.MaxPromptWeight)})
+		}
+		return h.promptError(c, err, "errors.prompt_save_failed")
+	}
+	return c.Status(fiber.StatusCreated).JSON(row)
+}
+
+// DeactivateVariant takes a variant out of rotation.
+func (h *PromptHandler) DeactivateVariant(c *fiber.Ctx) error {
+	if err := h.prompts.DeactivateVariant(c.Params("name"), c.Params("variant")); err != nil {
+		if errors.Is(err, services.ErrPromptVariantNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.prompt_variant_not_found")})
+		}
+		return h.promptError(c, err, "errors.prompt_save_failed")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Results compares the versions of a prompt. Query: days (default 30).
+func (h *PromptHandler) Results(c *fiber.Ctx) error {
+	days, err := strconv.Atoi(c.Query("days", "0"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_usage_days", services.MaxPromptResultDays)})
+	}
+
+	results, err := h.prompts.Results(c.Params("name"), days)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPromptDays) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_usage_days", services.MaxPromptResultDays)})
+		}
+		return h.promptError(c, err, "errors.prompts_fetch_failed")
+	}
+	return c.JSON(results)
+}
+
+// promptError maps an unknown prompt name to 404 and anything else to 500 with fallbackKey.
+func (h *PromptHandler) promptError(c *fiber.Ctx, err error, fallbackKey string) error {
+	if errors.Is(err, services.ErrUnknownPrompt) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.prompt_not_found")})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, fallbackKey)})
+}
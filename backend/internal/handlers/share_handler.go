@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/middleware"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ShareHandler manages share links and serves the public pages they open.
+type ShareHandler struct {
+	shares  *services.ShareService
+	baseURL string
+}
+
+func NewShareHandler(shares *services.ShareService, cfg *config.Config) *ShareHandler {
+	return &ShareHandler{shares: shares, baseURL: strings.TrimRight(cfg.PublicBaseURL, "/")}
+}
+
+// Create mints a share link for one of the user's readings or matches
+func (h *ShareHandler) Create(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	var req dto.CreateShareRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_request_body")})
+	}
+
+	link, err := h.shares.Create(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidShareTarget):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_share_target")})
+		case errors.Is(err, services.ErrInvalidShareExpiry):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_share_expiry", services.MaxShareExpiryDays)})
+		case errors.Is(err, services.ErrShareTargetNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.share_target_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.share_create_failed")})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(h.linkResponse(c, link))
+}
+
+// List returns the user's share links with their view counts
+func (h *ShareHandler) List(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	links, err := h.shares.List(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.shares_fetch_failed")})
+	}
+
+	items := make([]dto.ShareLinkResponse, 0, len(links))
+	for i := range links {
+		items = append(items, h.linkResponse(c, &links[i]))
+	}
+	return c.JSON(dto.ShareListResponse{Data: items})
+}
+
+// Revoke disables a share link immediately
+func (h *ShareHandler) Revoke(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_share_id")})
+	}
+
+	if err := h.shares.Revoke(userID, id); err != nil {
+		if errors.Is(err, services.ErrShareNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.share_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.share_revoke_failed")})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Open returns shared content to a signed-in user who followed a link in the app
+func (h *ShareHandler) Open(c *fiber.Ctx) error {
+	viewerID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_user_id")})
+	}
+
+	content, err := h.shares.Open(c.Params("token"), &viewerID, c.IP(), c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		if errors.Is(err, services.ErrShareNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.share_not_found")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.shares_fetch_failed")})
+	}
+
+	resp := dto.SharedContentResponse{
+		TargetType: content.Link.TargetType,
+		CardURL:    h.pageURL(c, content.Link.Token) + "/card.png",
+	}
+	if r := content.Reading; r != nil {
+		resp.Reading = &dto.SharedReadingResponse{
+			AuraColor:      r.AuraColor,
+			SecondaryColor: r.SecondaryColor,
+			EnergyLevel:    r.EnergyLevel,
+			MoodScore:      r.MoodScore,
+			Personality:    r.Personality,
+			Strengths:      r.Strengths,
+			Language:       r.Language,
+			CreatedAt:      r.CreatedAt,
+		}
+	}
+	if m := content.Match; m != nil {
+		resp.Match = &dto.SharedMatchResponse{
+			CompatibilityScore: m.CompatibilityScore,
+			Synergy:            m.Synergy,
+			Tension:            m.Tension,
+			Advice:             m.Advice,
+			UserAuraColor:      content.UserAura.AuraColor,
+			FriendAuraColor:    content.FriendAura.AuraColor,
+			Language:           m.Language,
+			CreatedAt:          m.CreatedAt,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// Page serves the public share page with OpenGraph and Twitter card tags
+func (h *ShareHandler) Page(c *fiber.Ctx) error {
+	token := c.Params("token")
+	c.Set("Content-Type", "text/html; charset=utf-8")
+	c.Set("Cache-Control", "no-cache")
+
+	content, err := h.shares.Open(token, nil, c.IP(), c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrShareNotFound) {
+			status = fiber.StatusNotFound
+		}
+		title := html.EscapeString(tr(c, "share.unavailable_title"))
+		body := html.EscapeString(tr(c, "share.unavailable_body"))
+		return c.Status(status).SendString(`<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1"><meta name="robots" content="noindex"><title>` + title + ` - AuraSnap</title><style>` + sharePageStyle + `</style></head><body><h1>` + title + `</h1><p>` + body + `</p></body></html>`)
+	}
+
+	title, description := h.shares.Describe(content, middleware.Localizer(c))
+	pageURL := h.pageURL(c, token)
+	imageURL := pageURL + "/card.png?size=square"
+	e := html.EscapeString
+
+	page := fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1"><meta name="robots" content="noindex"><title>%[1]s</title><meta name="description" content="%[2]s"><meta property="og:type" content="website"><meta property="og:site_name" content="AuraSnap"><meta property="og:title" content="%[1]s"><meta property="og:description" content="%[2]s"><meta property="og:url" content="%[3]s"><meta property="og:image" content="%[4]s"><meta property="og:image:width" content="1080"><meta property="og:image:height" content="1080"><meta name="twitter:card" content="summary_large_image"><meta name="twitter:title" content="%[1]s"><meta name="twitter:description" content="%[2]s"><meta name="twitter:image" content="%[4]s"><style>%[6]s</style></head><body><h1>%[1]s</h1><img src="%[5]s" alt="%[1]s"><p>%[2]s</p><p><strong>%[7]s</strong></p></body></html>`,
+		e(title), e(description), e(pageURL), e(imageURL), e(pageURL+"/card.png"), sharePageStyle, e(tr(c, "share.cta")))
+	return c.SendString(page)
+}
+
+// Card serves the image a share page points at. Query: size=story (default) or square.
+func (h *ShareHandler) Card(c *fiber.Ctx) error {
+	card, err := h.shares.Card(c.Params("token"), c.Query("size"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShareNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.share_not_found")})
+		case errors.Is(err, services.ErrInvalidCardSize):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.invalid_card_size")})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: true, Message: tr(c, "errors.card_render_failed")})
+	}
+
+	// Short-lived so a revoked link stops showing its card soon after.
+	c.Set("ETag", card.ETag)
+	c.Set("Cache-Control", "public, max-age=300")
+	if c.Get("If-None-Match") == card.ETag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set("Content-Type", "image/png")
+	return c.Send(card.PNG)
+}
+
+const sharePageStyle = `body{font-family:-apple-system,system-ui,sans-serif;max-width:540px;margin:0 auto;padding:20px;color:#333;line-height:1.6;text-align:center}h1{color:#8B5CF6}img{width:100%;border-radius:16px}`
+
+// pageURL is the public URL of a share page, built from PUBLIC_BASE_URL when set.
+func (h *ShareHandler) pageURL(c *fiber.Ctx, token string) string {
+	base := h.baseURL
+	if base == "" {
+		base = c.BaseURL()
+	}
+	return base + "/api/share/" + token
+}
+
+func (h *ShareHandler) linkResponse(c *fiber.Ctx, link *models.ShareLink) dto.ShareLinkResponse {
+	targetID := link.ReadingID
+	if link.MatchID != nil {
+		targetID = link.MatchID
+	}
+	url := h.pageURL(c, link.Token)
+	return dto.ShareLinkResponse{
+		ID:           link.ID,
+		TargetType:   link.TargetType,
+		TargetID:     *targetID,
+		Token:        link.Token,
+		URL:          url,
+		CardURL:      url + "/card.png",
+		ViewCount:    link.ViewCount,
+		LastViewedAt: link.LastViewedAt,
+		ExpiresAt:    link.ExpiresAt,
+		RevokedAt:    link.RevokedAt,
+		Active:       link.RevokedAt == nil && time.Now().Before(link.ExpiresAt),
+		CreatedAt:    link.CreatedAt,
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/middleware"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -18,12 +19,12 @@ func (h *StreakHandler) GetStreak(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	parsedUserID, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "Invalid user ID"})
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_user_id")})
 	}
 
 	streak, err := h.streakService.Get(parsedUserID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch streak"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": tr(c, "errors.streak_fetch_failed")})
 	}
 
 	return c.JSON(streak)
@@ -33,13 +34,30 @@ func (h *StreakHandler) UpdateStreak(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	parsedUserID, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "Invalid user ID"})
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_user_id")})
 	}
 
-	result, err := h.streakService.Update(parsedUserID)
+	result, err := h.streakService.Update(parsedUserID, middleware.Localizer(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update streak"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": tr(c, "errors.streak_update_failed")})
 	}
 
 	return c.JSON(result)
 }
+
+// GetPalette lists the colors the user's readings can come out in, including the rare
+// streak colors they have unlocked.
+func (h *StreakHandler) GetPalette(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": tr(c, "errors.invalid_user_id")})
+	}
+
+	palette, err := h.streakService.Palette(parsedUserID, middleware.Localizer(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": tr(c, "errors.palette_fetch_failed")})
+	}
+
+	return c.JSON(palette)
+}
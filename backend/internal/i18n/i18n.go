@@ -0,0 +1,219 @@
+// Package i18n holds the translated message catalogs and picks a language for each
+// request. Catalogs are JSON files embedded from locales/, one per language, mapping
+// dotted keys to either a string or a list of strings. Strings are fmt format strings;
+// translations may reorder arguments with explicit indexes such as %[2]s.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// Default is the fallback language and the source of every message.
+const Default = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+type entry struct {
+	text string
+	list []string
+}
+
+type catalog struct {
+	lang     string
+	name     string
+	messages map[string]entry
+}
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]*catalog
+	matcher  language.Matcher
+	tags     []language.Tag
+)
+
+func load() {
+	loadOnce.Do(func() {
+		catalogs = make(map[string]*catalog)
+
+		files, err := localeFS.ReadDir("locales")
+		if err != nil {
+			log.Fatalf("i18n: failed to read embedded locales: %v", err)
+		}
+		for _, f := range files {
+			lang := strings.TrimSuffix(f.Name(), ".json")
+			raw, err := localeFS.ReadFile(path.Join("locales", f.Name()))
+			if err != nil {
+				log.Fatalf("i18n: failed to read %s: %v", f.Name(), err)
+			}
+			c, err := parseCatalog(lang, raw)
+			if err != nil {
+				log.Fatalf("i18n: %s: %v", f.Name(), err)
+			}
+			catalogs[lang] = c
+		}
+		if catalogs[Default] == nil {
+			log.Fatalf("i18n: missing %s catalog", Default)
+		}
+
+		// The default language goes first so the matcher falls back to it.
+		langs := make([]string, 0, len(catalogs))
+		for lang := range catalogs {
+			if lang != Default {
+				langs = append(langs, lang)
+			}
+		}
+		sort.Strings(langs)
+		tags = []language.Tag{language.Make(Default)}
+		for _, lang := range langs {
+			tags = append(tags, language.Make(lang))
+		}
+		matcher = language.NewMatcher(tags)
+	})
+}
+
+func parseCatalog(lang string, raw []byte) (*catalog, error) {
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	c := &catalog{lang: lang, messages: make(map[string]entry, len(data))}
+	for key, value := range data {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			c.messages[key] = entry{text: text}
+			continue
+		}
+		var list []string
+		if err := json.Unmarshal(value, &list); err != nil {
+			return nil, fmt.Errorf("key %q must be a string or a list of strings", key)
+		}
+		c.messages[key] = entry{list: list}
+	}
+	c.name = c.messages["language.name"].text
+	if c.name == "" {
+		return nil, fmt.Errorf("missing language.name")
+	}
+	return c, nil
+}
+
+// Supported lists the available languages, default first.
+func Supported() []string {
+	load()
+	langs := make([]string, len(tags))
+	for i, tag := range tags {
+		base, _ := tag.Base()
+		langs[i] = base.String()
+	}
+	return langs
+}
+
+// IsSupported reports whether lang names an available catalog.
+func IsSupported(lang string) bool {
+	load()
+	_, ok := catalogs[strings.ToLower(strings.TrimSpace(lang))]
+	return ok
+}
+
+// Negotiate picks the catalog for a request: an explicit user preference wins, then
+// the best match for the Accept-Language header, then the default.
+func Negotiate(preferred, acceptLanguage string) string {
+	load()
+	if IsSupported(preferred) {
+		return strings.ToLower(strings.TrimSpace(preferred))
+	}
+	if acceptLanguage == "" {
+		return Default
+	}
+	accepted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(accepted) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(accepted...)
+	if confidence == language.No {
+		return Default
+	}
+	base, _ := tags[index].Base()
+	return base.String()
+}
+
+// Localizer looks up messages in one language, falling back to the default catalog
+// and finally to the key itself.
+type Localizer struct {
+	primary  *catalog
+	fallback *catalog
+}
+
+// For returns the localizer for lang, or for the default language if lang is unknown.
+func For(lang string) *Localizer {
+	load()
+	c, ok := catalogs[strings.ToLower(strings.TrimSpace(lang))]
+	if !ok {
+		c = catalogs[Default]
+	}
+	return &Localizer{primary: c, fallback: catalogs[Default]}
+}
+
+// Lang is the language code of the localizer, e.g. "tr".
+func (l *Localizer) Lang() string {
+	if l == nil {
+		return Default
+	}
+	return l.primary.lang
+}
+
+// LanguageName is the English name of the language, for instructing AI providers.
+func (l *Localizer) LanguageName() string {
+	if l == nil {
+		return For(Default).LanguageName()
+	}
+	return l.primary.name
+}
+
+func (l *Localizer) lookup(key string) (entry, bool) {
+	if l == nil {
+		l = For(Default)
+	}
+	if e, ok := l.primary.messages[key]; ok {
+		return e, true
+	}
+	e, ok := l.fallback.messages[key]
+	return e, ok
+}
+
+// T formats the message for key with args.
+func (l *Localizer) T(key string, args ...interface{}) string {
+	e, ok := l.lookup(key)
+	if !ok || e.text == "" {
+		return key
+	}
+	if len(args) == 0 {
+		return e.text
+	}
+	return fmt.Sprintf(e.text, args...)
+}
+
+// Has reports whether key exists in this language or the default one.
+func (l *Localizer) Has(key string) bool {
+	_, ok := l.lookup(key)
+	return ok
+}
+
+// List returns a copy of the list message for key.
+func (l *Localizer) List(key string) []string {
+	e, ok := l.lookup(key)
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), e.list...)
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		preferred, accept, want string
+	}{
+		{"", "", "en"},
+		{"", "tr-TR,tr;q=0.9,en;q=0.8", "tr"},
+		{"", "de-DE,es;q=0.7", "es"},
+		{"", "ja-JP", "en"},
+		{"", "not a header", "en"},
+		{"es", "tr-TR", "es"},
+		{"xx", "tr-TR", "tr"},
+	}
+	for _, tc := range cases {
+		if got := Negotiate(tc.preferred, tc.accept); got != tc.want {
+			t.Errorf("Negotiate(%q, %q) = %q, want %q", tc.preferred, tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestCatalogsMatchDefault(t *testing.T) {
+	load()
+	base := catalogs[Default]
+	for lang, c := range catalogs {
+		for key, want := range base.messages {
+			got, ok := c.messages[key]
+			if !ok {
+				t.Errorf("%s: missing %q", lang, key)
+				continue
+			}
+			if (want.list == nil) != (got.list == nil) {
+				t.Errorf("%s: %q must be a %s", lang, key, kind(want))
+			}
+			if strings.Count(want.text, "%") != strings.Count(got.text, "%") {
+				t.Errorf("%s: %q has different format verbs", lang, key)
+			}
+		}
+		for key := range c.messages {
+			if _, ok := base.messages[key]; !ok {
+				t.Errorf("%s: %q is not in the %s catalog", lang, key, Default)
+			}
+		}
+	}
+}
+
+func TestLocalizerFallsBack(t *testing.T) {
+	l := For("tr")
+	if got := l.T("streak.days.other", 3); got != "3 gün" {
+		t.Fatalf("unexpected translation %q", got)
+	}
+	if got := l.T("no.such.key"); got != "no.such.key" {
+		t.Fatalf("expected the key for a missing message, got %q", got)
+	}
+	if got := For("xx").Lang(); got != Default {
+		t.Fatalf("expected default language for unknown code, got %q", got)
+	}
+	var nilLocalizer *Localizer
+	if got := nilLocalizer.List("traits.red.strengths"); len(got) != 3 {
+		t.Fatalf("expected default list from nil localizer, got %v", got)
+	}
+}
+
+func kind(e entry) string {
+	if e.list != nil {
+		return "list"
+	}
+	return "string"
+}
@@ -43,11 +43,11 @@ func AdminOnly(cfg *config.Config) fiber.Handler {
 
 		token, ok := c.Locals("user").(*jwt.Token)
 		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Error: true, Message: "Forbidden"})
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Error: true, Message: Localizer(c).T("errors.forbidden")})
 		}
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Error: true, Message: "Forbidden"})
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Error: true, Message: Localizer(c).T("errors.forbidden")})
 		}
 
 		email, _ := claims["email"].(string)
@@ -64,6 +64,6 @@ func AdminOnly(cfg *config.Config) fiber.Handler {
 			}
 		}
 
-		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Error: true, Message: "Forbidden"})
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Error: true, Message: Localizer(c).T("errors.forbidden")})
 	}
 }
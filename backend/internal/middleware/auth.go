@@ -16,21 +16,21 @@ func JWTProtected(cfg *config.Config) fiber.Handler {
 			if !ok {
 				return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 					Error:   true,
-					Message: "Unauthorized: invalid or expired token",
+					Message: Localizer(c).T("errors.token_invalid"),
 				})
 			}
 			claims, ok := token.Claims.(jwt.MapClaims)
 			if !ok {
 				return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 					Error:   true,
-					Message: "Unauthorized: invalid or expired token",
+					Message: Localizer(c).T("errors.token_invalid"),
 				})
 			}
 			sub, _ := claims["sub"].(string)
 			if sub == "" {
 				return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 					Error:   true,
-					Message: "Unauthorized: invalid or expired token",
+					Message: Localizer(c).T("errors.token_invalid"),
 				})
 			}
 
@@ -41,7 +41,7 @@ func JWTProtected(cfg *config.Config) fiber.Handler {
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 				Error:   true,
-				Message: "Unauthorized: invalid or expired token",
+				Message: Localizer(c).T("errors.token_invalid"),
 			})
 		},
 	})
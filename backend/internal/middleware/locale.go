@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/gofiber/fiber/v2"
+)
+
+const localeKey = "locale"
+
+// LanguageLookup returns a user's saved language preference, or "" if they have none.
+type LanguageLookup func(userID string) string
+
+// Locale negotiates the response language and stores its localizer in Fiber locals.
+// Without a lookup only Accept-Language is used; with one, an authenticated user's saved
+// preference wins, so mount it after JWTProtected on protected routes.
+func Locale(lookup LanguageLookup) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		preferred := ""
+		if lookup != nil {
+			if userID, ok := c.Locals("userID").(string); ok && userID != "" {
+				preferred = lookup(userID)
+			}
+		}
+
+		lang := i18n.Negotiate(preferred, c.Get(fiber.HeaderAcceptLanguage))
+		c.Locals(localeKey, i18n.For(lang))
+		c.Set(fiber.HeaderContentLanguage, lang)
+		c.Vary(fiber.HeaderAcceptLanguage)
+		return c.Next()
+	}
+}
+
+// Localizer returns the request's localizer, or the default language's if Locale did
+// not run.
+func Localizer(c *fiber.Ctx) *i18n.Localizer {
+	if l, ok := c.Locals(localeKey).(*i18n.Localizer); ok {
+		return l
+	}
+	return i18n.For(i18n.Default)
+}
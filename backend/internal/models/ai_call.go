@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AICall records one request to an AI provider for usage and cost reporting. UserID is
+// nil for calls not made on behalf of a user and is cleared when the account is deleted.
+type AICall struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID           *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Feature          string     `gorm:"type:varchar(20);not null;index" json:"feature"`
+	Provider         string     `gorm:"type:varchar(50);not null" json:"provider"`
+	Model            string     `gorm:"type:varchar(100);not null" json:"model"`
+	PromptTokens     int        `gorm:"not null;default:0" json:"prompt_tokens"`
+	CompletionTokens int        `gorm:"not null;default:0" json:"completion_tokens"`
+	LatencyMs        int64      `gorm:"not null" json:"latency_ms"`
+	Outcome          string     `gorm:"type:varchar(20);not null" json:"outcome"`
+	Error            string     `gorm:"type:varchar(500)" json:"error,omitempty"`
+	CostUSD          float64    `gorm:"type:numeric(12,6);not null;default:0" json:"cost_usd"`
+	CreatedAt        time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (AICall) TableName() string {
+	return "ai_calls"
+}
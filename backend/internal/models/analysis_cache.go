@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AnalysisCacheEntry is a cached AI analysis shared by every backend instance. Key is a
+// hash of the photo bytes and the prompt, palette and provider versions it was analyzed
+// with; Result is the provider's JSON answer.
+type AnalysisCacheEntry struct {
+	Key       string    `gorm:"type:varchar(64);primaryKey" json:"key"`
+	Result    string    `gorm:"type:jsonb;not null" json:"result"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (AnalysisCacheEntry) TableName() string {
+	return "analysis_cache_entries"
+}
@@ -16,8 +16,12 @@ type AuraMatch struct {
 	Synergy            string    `gorm:"type:text" json:"synergy"`
 	Tension            string    `gorm:"type:text" json:"tension"`
 	Advice             string    `gorm:"type:text" json:"advice"`
+	Language           string    `gorm:"type:varchar(8);not null;default:'en'" json:"language"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
+
+	// PromptVersion is the match prompt template version when the text was AI-written.
+	PromptVersion string `gorm:"type:varchar(100)" json:"-"`
 }
 
 func (AuraMatch) TableName() string {
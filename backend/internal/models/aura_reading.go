@@ -8,21 +8,43 @@ import (
 )
 
 type AuraReading struct {
-	ID             uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
-	UserID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	ImageURL       string         `gorm:"type:text;not null" json:"image_url"`
-	AuraColor      string         `gorm:"type:varchar(50);not null" json:"aura_color"`
-	SecondaryColor *string        `gorm:"type:varchar(50);default:NULL" json:"secondary_color,omitempty"`
-	EnergyLevel    int            `gorm:"type:integer;check:energy_level >= 1 AND energy_level <= 100" json:"energy_level"`
-	MoodScore      int            `gorm:"type:integer;check:mood_score >= 1 AND mood_score <= 10" json:"mood_score"`
-	Personality    string         `gorm:"type:text" json:"personality"`
-	Strengths      []string       `gorm:"type:jsonb;serializer:json" json:"strengths"`
-	Challenges     []string       `gorm:"type:jsonb;serializer:json" json:"challenges"`
-	DailyAdvice    string         `gorm:"type:text" json:"daily_advice"`
-	AnalyzedAt     time.Time      `gorm:"not null" json:"analyzed_at"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index;index:idx_aura_readings_user_created,priority:1" json:"user_id"`
+	ImageURL       string    `gorm:"type:text;not null" json:"image_url"`
+	ImageKey       string    `gorm:"type:text" json:"-"`
+	ThumbnailKey   string    `gorm:"type:text" json:"-"`
+	ThumbnailURL   string    `gorm:"-" json:"thumbnail_url,omitempty"`
+	AuraColor      string    `gorm:"type:varchar(50);not null" json:"aura_color"`
+	SecondaryColor *string   `gorm:"type:varchar(50);default:NULL" json:"secondary_color,omitempty"`
+	EnergyLevel    int       `gorm:"type:integer;check:energy_level >= 1 AND energy_level <= 100" json:"energy_level"`
+	MoodScore      int       `gorm:"type:integer;check:mood_score >= 1 AND mood_score <= 10" json:"mood_score"`
+	Personality    string    `gorm:"type:text" json:"personality"`
+	Strengths      []string  `gorm:"type:jsonb;serializer:json" json:"strengths"`
+	Challenges     []string  `gorm:"type:jsonb;serializer:json" json:"challenges"`
+	DailyAdvice    string    `gorm:"type:text" json:"daily_advice"`
+	// NarrativeSource is "ai" when the text above was written for this reading, "traits" otherwise.
+	NarrativeSource string         `gorm:"type:varchar(20);not null;default:'traits'" json:"narrative_source"`
+	Language        string         `gorm:"type:varchar(8);not null;default:'en'" json:"language"`
+	AnalyzedAt      time.Time      `gorm:"not null" json:"analyzed_at"`
+	CreatedAt       time.Time      `gorm:"index:idx_aura_readings_user_created,priority:2,sort:desc" json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// ImageHash is the 64-bit difference hash of the scanned photo, used to spot re-uploads.
+	ImageHash *int64 `gorm:"type:bigint" json:"-"`
+	// DuplicateOf is the earlier reading whose photo matched this one's, when duplicates
+	// are flagged rather than reused.
+	DuplicateOf *uuid.UUID `gorm:"type:uuid" json:"duplicate_of,omitempty"`
+	// Reused marks a scan response that returned an earlier reading for a duplicate photo.
+	Reused bool `gorm:"-" json:"reused,omitempty"`
+
+	// PromptVersions maps each prompt whose AI answer shaped this reading ("analysis",
+	// "narrative") to the template version used, for comparing prompt variants.
+	PromptVersions map[string]string `gorm:"type:jsonb;serializer:json" json:"-"`
+
+	// GroupScanID links a reading to the group photo it was cut from. Group readings
+	// describe the people in that photo, so they are kept out of the user's own aura.
+	GroupScanID *uuid.UUID `gorm:"type:uuid;index" json:"group_scan_id,omitempty"`
 }
 
 func (AuraReading) TableName() string {
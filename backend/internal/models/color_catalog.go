@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ColorCatalog is one version of the aura palette. Drafts can be edited freely; a
+// published version is immutable and exactly one version is active at a time.
+type ColorCatalog struct {
+	ID          uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Version     int               `gorm:"not null;uniqueIndex" json:"version"`
+	Status      string            `gorm:"size:20;not null;default:'draft';index" json:"status"` // draft, active, retired
+	Note        string            `gorm:"type:text" json:"note"`
+	Colors      []ColorDefinition `gorm:"type:jsonb;serializer:json;not null" json:"colors"`
+	PublishedAt *time.Time        `json:"published_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+func (ColorCatalog) TableName() string {
+	return "color_catalogs"
+}
+
+// ColorDefinition is one palette color. Primary colors can be a reading's main color and
+// carry traits and match pairings; secondary colors are accents; a positive UnlockStreak
+// makes the color a streak reward. A streak reward with a ScanChance is a rare color: once
+// unlocked, each of the owner's scans comes out in it with that probability. CardStyle
+// picks a special share card look. Text is keyed by language code.
+type ColorDefinition struct {
+	Key          string                 `json:"key"`
+	Hex          string                 `json:"hex"`
+	Primary      bool                   `json:"primary"`
+	Secondary    bool                   `json:"secondary"`
+	UnlockStreak int                    `json:"unlock_streak,omitempty"`
+	ScanChance   float64                `json:"scan_chance,omitempty"`
+	CardStyle    string                 `json:"card_style,omitempty"`
+	Complement   string                 `json:"complement,omitempty"`
+	Challenging  []string               `json:"challenging,omitempty"`
+	Names        map[string]string      `json:"names"`
+	MatchDetail  map[string]string      `json:"match_detail,omitempty"`
+	Traits       map[string]ColorTraits `json:"traits,omitempty"`
+}
+
+// ColorTraits is the static reading text for a primary or secondary color in one language.
+type ColorTraits struct {
+	Personality string   `json:"personality"`
+	Strengths   []string `json:"strengths"`
+	Challenges  []string `json:"challenges"`
+	DailyAdvice string   `json:"daily_advice"`
+}
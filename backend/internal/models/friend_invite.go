@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FriendInvite is a short code a user hands out so others can add them as a friend
+// without exchanging user IDs. MaxUses is 1 for a single-use invite.
+type FriendInvite struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Code      string     `gorm:"type:varchar(16);not null;uniqueIndex" json:"code"`
+	MaxUses   int        `gorm:"not null;default:1" json:"max_uses"`
+	Uses      int        `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (FriendInvite) TableName() string {
+	return "friend_invites"
+}
+
+// FriendInviteRedemption records who redeemed an invite, and whether they redeemed it
+// while signing up, so invites that bring in new users can be attributed.
+type FriendInviteRedemption struct {
+	InviteID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"invite_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	Signup    bool      `gorm:"not null;default:false" json:"signup"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (FriendInviteRedemption) TableName() string {
+	return "friend_invite_redemptions"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Friendship is a friend request from RequesterID to AddresseeID, and the friendship
+// itself once the addressee accepts. Declined, cancelled and removed friendships are
+// deleted, so the pair can start over. A pair has at most one row, whichever of them
+// sent the request.
+type Friendship struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RequesterID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_friendships_pair" json:"requester_id"`
+	AddresseeID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_friendships_pair;index" json:"addressee_id"`
+	Status      string     `gorm:"size:20;not null;default:'pending';index" json:"status"` // pending, accepted
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Friendship) TableName() string {
+	return "friendships"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupScan is one group photo scan. Each person found in the photo gets an AuraReading
+// pointing back here through GroupScanID; the scan counts once against the quota.
+type GroupScan struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	ImageURL     string    `gorm:"type:text;not null" json:"image_url"`
+	People       int       `gorm:"not null" json:"people"`
+	AverageScore int       `gorm:"not null" json:"average_score"`
+	Summary      string    `gorm:"type:text" json:"summary"`
+	Language     string    `gorm:"type:varchar(8);not null;default:'en'" json:"language"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (GroupScan) TableName() string {
+	return "group_scans"
+}
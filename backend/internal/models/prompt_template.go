@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptTemplate is one version of a prompt variant stored in the database. Editing a
+// variant adds a new version and deactivates the old one, so the version stamped on a
+// reading or match always identifies the exact text used. Active variants of a prompt
+// replace its embedded default and split users between them by weight.
+type PromptTemplate struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_prompt_templates_version,priority:1" json:"name"`
+	Variant   string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_prompt_templates_version,priority:2" json:"variant"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_prompt_templates_version,priority:3" json:"version"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	Weight    int       `gorm:"not null;default:1" json:"weight"`
+	Active    bool      `gorm:"not null;default:true;index" json:"active"`
+	Note      string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScanJob is an aura scan queued for the background worker pool. Jobs are claimed
+// with a lease so that work interrupted by a restart is picked up again.
+type ScanJob struct {
+	ID             uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID         uuid.UUID    `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status         string       `gorm:"type:varchar(20);not null;default:'queued';index" json:"status"` // queued, running, succeeded, failed
+	Stage          string       `gorm:"type:varchar(30)" json:"stage"`
+	Progress       int          `gorm:"not null;default:0" json:"progress"`
+	ImageURL       string       `gorm:"type:text" json:"-"`
+	InputKey       string       `gorm:"type:text" json:"-"`
+	ReservationID  *uuid.UUID   `gorm:"type:uuid" json:"-"`
+	Language       string       `gorm:"type:varchar(8);not null;default:'en'" json:"-"`
+	ReadingID      *uuid.UUID   `gorm:"type:uuid" json:"reading_id,omitempty"`
+	Reading        *AuraReading `gorm:"-" json:"reading,omitempty"`
+	Error          string       `gorm:"type:text" json:"error,omitempty"`
+	Attempts       int          `gorm:"not null;default:0" json:"attempts"`
+	LeaseExpiresAt *time.Time   `gorm:"index" json:"-"`
+	NextAttemptAt  *time.Time   `gorm:"index" json:"-"` // a retried job waits until then
+	StartedAt      *time.Time   `json:"started_at,omitempty"`
+	FinishedAt     *time.Time   `json:"finished_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+func (ScanJob) TableName() string {
+	return "scan_jobs"
+}
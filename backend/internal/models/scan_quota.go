@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScanQuotaDay counts the scans a user has reserved on one calendar day in their own
+// timezone. Day holds that local date at UTC midnight.
+type ScanQuotaDay struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Day       time.Time `gorm:"type:date;primaryKey" json:"day"`
+	Used      int       `gorm:"not null;default:0" json:"used"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ScanQuotaDay) TableName() string {
+	return "scan_quota_days"
+}
+
+// ScanReservation is one entry in the scan quota ledger. A reservation is committed
+// when its reading is saved and refunded when the scan fails.
+type ScanReservation struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_scan_reservations_user_day" json:"user_id"`
+	Day       time.Time  `gorm:"type:date;not null;index:idx_scan_reservations_user_day" json:"day"`
+	Plan      string     `gorm:"size:255;not null" json:"plan"`
+	Status    string     `gorm:"size:20;not null;default:'reserved';index" json:"status"` // reserved, committed, refunded
+	ReadingID *uuid.UUID `gorm:"type:uuid" json:"reading_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (ScanReservation) TableName() string {
+	return "scan_reservations"
+}
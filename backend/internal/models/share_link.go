@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink is a public, revocable link to one of a user's readings or matches. Exactly
+// one of ReadingID and MatchID is set, matching TargetType.
+type ShareLink struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Token        string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"token"`
+	TargetType   string     `gorm:"type:varchar(20);not null" json:"target_type"`
+	ReadingID    *uuid.UUID `gorm:"type:uuid;index" json:"reading_id,omitempty"`
+	MatchID      *uuid.UUID `gorm:"type:uuid;index" json:"match_id,omitempty"`
+	ViewCount    int64      `gorm:"not null;default:0" json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (ShareLink) TableName() string {
+	return "share_links"
+}
+
+// ShareView records a signed-in user who opened a share link, so the link can be revoked
+// if its owner later blocks them.
+type ShareView struct {
+	ShareID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"share_id"`
+	ViewerID      uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"viewer_id"`
+	FirstViewedAt time.Time `gorm:"not null" json:"first_viewed_at"`
+}
+
+func (ShareView) TableName() string {
+	return "share_views"
+}
@@ -12,6 +12,8 @@ type User struct {
 	Email     string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
 	AppleSub  *string        `gorm:"uniqueIndex;size:255" json:"-"`
 	Password  string         `gorm:"not null" json:"-"`
+	Timezone  string         `gorm:"size:64;not null;default:'UTC'" json:"timezone"`
+	Language  string         `gorm:"size:8;not null;default:''" json:"language"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -8,8 +8,12 @@ import (
 )
 
 // Setup configures all API routes for the application
-func Setup(app *fiber.App, cfg *config.Config, authHandler *handlers.AuthHandler, healthHandler *handlers.HealthHandler, webhookHandler *handlers.WebhookHandler, moderationHandler *handlers.ModerationHandler, auraHandler *handlers.AuraHandler, auraMatchHandler *handlers.AuraMatchHandler, streakHandler *handlers.StreakHandler, legalHandler *handlers.LegalHandler) {
-	api := app.Group("/api")
+func Setup(app *fiber.App, cfg *config.Config, authHandler *handlers.AuthHandler, healthHandler *handlers.HealthHandler, webhookHandler *handlers.WebhookHandler, moderationHandler *handlers.ModerationHandler, auraHandler *handlers.AuraHandler, auraMatchHandler *handlers.AuraMatchHandler, streakHandler *handlers.StreakHandler, legalHandler *handlers.LegalHandler, mediaHandler *handlers.MediaHandler, colorCatalogHandler *handlers.ColorCatalogHandler, shareHandler *handlers.ShareHandler, aiUsageHandler *handlers.AIUsageHandler, promptHandler *handlers.PromptHandler, friendHandler *handlers.FriendHandler, inviteHandler *handlers.InviteHandler) {
+	// Universal link association files for invite links
+	app.Get("/.well-known/apple-app-site-association", inviteHandler.AppleAppSiteAssociation)
+	app.Get("/.well-known/assetlinks.json", inviteHandler.AssetLinks)
+
+	api := app.Group("/api", middleware.Locale(nil))
 
 	// Health check
 	api.Get("/health", healthHandler.Check)
@@ -25,17 +29,29 @@ func Setup(app *fiber.App, cfg *config.Config, authHandler *handlers.AuthHandler
 	auth.Post("/refresh", authHandler.RefreshToken)
 	auth.Post("/apple", authHandler.AppleSignIn)
 
+	// Scan photos (public but signature verified)
+	api.Get("/media/*", mediaHandler.Serve)
+
 	// Webhooks (public but auth-header verified)
 	api.Post("/webhooks/revenuecat", webhookHandler.HandleRevenueCat)
 
+	// Public share pages (the token is the credential)
+	api.Get("/share/:token", shareHandler.Page)
+	api.Get("/share/:token/card.png", shareHandler.Card)
+
+	// Public invite page, opened when the app is not installed
+	api.Get("/invite/:code", inviteHandler.Page)
+
 	// Protected routes (require JWT)
-	protected := api.Group("", middleware.JWTProtected(cfg))
+	// A signed-in user's saved language overrides Accept-Language.
+	protected := api.Group("", middleware.JWTProtected(cfg), middleware.Locale(authHandler.PreferredLanguage))
 
 	// Auth (protected)
 	protected.Post("/auth/logout", authHandler.Logout)
 	protected.Post("/auth/claim", authHandler.ClaimGuest)
 	protected.Delete("/auth/account", authHandler.DeleteAccount)
 	protected.Get("/auth/profile", authHandler.GetProfile)
+	protected.Patch("/auth/profile", authHandler.UpdateProfile)
 
 	// Aura routes
 	aura := protected.Group("/aura")
@@ -43,7 +59,16 @@ func Setup(app *fiber.App, cfg *config.Config, authHandler *handlers.AuthHandler
 	aura.Post("/scan", auraHandler.Scan)
 	aura.Post("/scan/upload", auraHandler.ScanWithUpload)
 	aura.Get("/stats", auraHandler.Stats)
+	aura.Get("/trends", auraHandler.Trends)
+	aura.Get("/compare", auraHandler.Compare)
+	aura.Get("/jobs/:id", auraHandler.GetJob)
+	aura.Get("/jobs/:id/events", auraHandler.JobEvents)
+	aura.Get("/deleted", auraHandler.ListDeleted)
+	aura.Post("/bulk-delete", auraHandler.BulkDelete)
+	aura.Post("/:id/restore", auraHandler.Restore)
+	aura.Get("/:id/card.png", auraHandler.Card)
 	aura.Get("/:id", auraHandler.GetByID)
+	aura.Delete("/:id", auraHandler.Delete)
 	aura.Get("", auraHandler.List)
 
 	// Aura Match routes
@@ -52,10 +77,34 @@ func Setup(app *fiber.App, cfg *config.Config, authHandler *handlers.AuthHandler
 	match.Get("", auraMatchHandler.GetMatches)
 	match.Get("/:friend_id", auraMatchHandler.GetMatchByFriend)
 
+	// Friends
+	friends := protected.Group("/friends")
+	friends.Get("", friendHandler.List)
+	friends.Get("/requests", friendHandler.Requests)
+	friends.Post("/requests", friendHandler.SendRequest)
+	friends.Post("/requests/:id/accept", friendHandler.Accept)
+	friends.Post("/requests/:id/decline", friendHandler.Decline)
+	friends.Delete("/requests/:id", friendHandler.Cancel)
+	friends.Delete("/:id", friendHandler.Remove)
+
+	// Friend invites
+	invites := protected.Group("/invites")
+	invites.Post("", inviteHandler.Create)
+	invites.Get("", inviteHandler.List)
+	invites.Post("/redeem", inviteHandler.Redeem)
+	invites.Delete("/:id", inviteHandler.Revoke)
+
+	// Share links
+	protected.Post("/shares", shareHandler.Create)
+	protected.Get("/shares", shareHandler.List)
+	protected.Get("/shares/open/:token", shareHandler.Open)
+	protected.Delete("/shares/:id", shareHandler.Revoke)
+
 	// Streak routes
 	streak := protected.Group("/streak")
 	streak.Get("", streakHandler.GetStreak)
 	streak.Post("/update", streakHandler.UpdateStreak)
+	streak.Get("/palette", streakHandler.GetPalette)
 
 	// Moderation routes
 	protected.Post("/reports", moderationHandler.CreateReport)
@@ -66,4 +115,25 @@ func Setup(app *fiber.App, cfg *config.Config, authHandler *handlers.AuthHandler
 	admin := protected.Group("/admin", middleware.AdminOnly(cfg))
 	admin.Get("/moderation/reports", moderationHandler.ListReports)
 	admin.Put("/moderation/reports/:id", moderationHandler.ActionReport)
+	admin.Get("/ai/providers", auraHandler.ProviderHealth)
+	admin.Get("/ai/cache", auraHandler.AnalysisCache)
+	admin.Get("/ai/usage/daily", aiUsageHandler.Daily)
+	admin.Get("/ai/usage/providers", aiUsageHandler.Providers)
+	admin.Get("/ai/usage/features", aiUsageHandler.Features)
+	admin.Get("/ai/usage/users", aiUsageHandler.Users)
+	admin.Get("/prompts", promptHandler.List)
+	admin.Put("/prompts/:name/variants/:variant", promptHandler.SaveVariant)
+	admin.Delete("/prompts/:name/variants/:variant", promptHandler.DeactivateVariant)
+	admin.Get("/prompts/:name/results", promptHandler.Results)
+
+	// Color catalog: edit drafts, then publish one to make it the live palette
+	admin.Get("/colors", colorCatalogHandler.Active)
+	admin.Get("/colors/versions", colorCatalogHandler.ListVersions)
+	admin.Post("/colors/versions", colorCatalogHandler.CreateDraft)
+	admin.Get("/colors/versions/:version", colorCatalogHandler.GetVersion)
+	admin.Put("/colors/versions/:version", colorCatalogHandler.UpdateDraft)
+	admin.Delete("/colors/versions/:version", colorCatalogHandler.DeleteDraft)
+	admin.Post("/colors/versions/:version/publish", colorCatalogHandler.Publish)
+	admin.Put("/colors/versions/:version/colors/:key", colorCatalogHandler.UpsertColor)
+	admin.Delete("/colors/versions/:version/colors/:key", colorCatalogHandler.DeleteColor)
 }
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AI features, the part of the app a call is made for.
+const (
+	AIFeatureAnalysis  = "analysis"
+	AIFeatureNarrative = "narrative"
+	AIFeatureMatch     = "match"
+	AIFeatureCompare   = "compare"
+	AIFeatureOther     = "other"
+)
+
+// AI call outcomes.
+const (
+	AICallSucceeded = "success"
+	AICallFailed    = "error"
+	// AICallCancelled is a hedged call abandoned because another provider answered first.
+	AICallCancelled = "cancelled"
+)
+
+// Usage summary groupings served by the admin endpoints.
+const (
+	AIUsageByDay      = "day"
+	AIUsageByProvider = "provider"
+	AIUsageByFeature  = "feature"
+	AIUsageByUser     = "user"
+)
+
+const (
+	defaultAIUsageDays = 30
+	MaxAIUsageDays     = 366
+	maxAIUsageUsers    = 50
+	maxAICallErrorLen  = 500
+)
+
+var (
+	ErrInvalidUsageGroup = errors.New("group must be day, provider, feature or user")
+	ErrInvalidUsageDays  = errors.New("days out of range")
+)
+
+// aiTokenUsage is the `usage` object of a chat completion response.
+type aiTokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type aiCallScope struct {
+	feature string
+	userID  *uuid.UUID
+}
+
+type aiCallScopeKey struct{}
+
+// withAICall attributes AI calls made under ctx to a feature and, when set, a user.
+func withAICall(ctx context.Context, feature string, userID *uuid.UUID) context.Context {
+	return context.WithValue(ctx, aiCallScopeKey{}, aiCallScope{feature: feature, userID: userID})
+}
+
+func aiCallScopeFrom(ctx context.Context) aiCallScope {
+	if scope, ok := ctx.Value(aiCallScopeKey{}).(aiCallScope); ok {
+		return scope
+	}
+	return aiCallScope{feature: AIFeatureOther}
+}
+
+// AIUsageService records every AI provider call with its tokens, latency, outcome and
+// estimated cost, and summarizes spend for admins.
+type AIUsageService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewAIUsageService(db *gorm.DB, cfg *config.Config) *AIUsageService {
+	return &AIUsageService{db: db, cfg: cfg}
+}
+
+// record stores one finished call. Accounting must never break the feature making the
+// call, so failures are logged rather than returned. A nil service records nothing.
+func (s *AIUsageService) record(ctx context.Context, provider, model string, usage aiTokenUsage, latency time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	scope := aiCallScopeFrom(ctx)
+	call := models.AICall{
+		UserID:           scope.userID,
+		Feature:          scope.feature,
+		Provider:         provider,
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		LatencyMs:        latency.Milliseconds(),
+		Outcome:          AICallSucceeded,
+	}
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.Canceled):
+		call.Outcome = AICallCancelled
+	default:
+		call.Outcome = AICallFailed
+		call.Error = err.Error()
+		if len(call.Error) > maxAICallErrorLen {
+			call.Error = call.Error[:maxAICallErrorLen]
+		}
+	}
+	if price, ok := s.cfg.ModelPrice(model); ok {
+		call.CostUSD = aiCallCost(price, usage)
+	}
+
+	if err := s.db.Create(&call).Error; err != nil {
+		log.Printf("Failed to record %s AI call: %v", provider, err)
+	}
+}
+
+func aiCallCost(price config.ModelPrice, usage aiTokenUsage) float64 {
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+}
+
+// aiUsageGroups maps a summary grouping to its key expression, ordering and row limit.
+var aiUsageGroups = map[string]struct {
+	key   string
+	group string
+	order string
+	limit int
+}{
+	AIUsageByDay:      {key: "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS key", group: "1", order: "key DESC"},
+	AIUsageByProvider: {key: "provider AS key, model", group: "provider, model", order: "cost_usd DESC, calls DESC"},
+	AIUsageByFeature:  {key: "feature AS key", group: "feature", order: "cost_usd DESC, calls DESC"},
+	AIUsageByUser:     {key: "user_id::text AS key", group: "user_id", order: "cost_usd DESC, calls DESC", limit: maxAIUsageUsers},
+}
+
+const aiUsageAggregates = `COUNT(*) AS calls,
+	COUNT(*) FILTER (WHERE outcome = @failed) AS failures,
+	COUNT(*) FILTER (WHERE outcome = @cancelled) AS cancelled,
+	COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+	COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+	COALESCE(SUM(cost_usd), 0)::float8 AS cost_usd,
+	COALESCE(ROUND(AVG(latency_ms) FILTER (WHERE outcome = @succeeded)), 0)::bigint AS avg_latency_ms`
+
+// Summary aggregates the last `days` days of calls (UTC) by day, provider and model,
+// feature, or user. The user grouping lists the top spenders only.
+func (s *AIUsageService) Summary(group string, days int) (*dto.AIUsageSummary, error) {
+	g, ok := aiUsageGroups[group]
+	if !ok {
+		return nil, ErrInvalidUsageGroup
+	}
+	if days == 0 {
+		days = defaultAIUsageDays
+	}
+	if days < 1 || days > MaxAIUsageDays {
+		return nil, ErrInvalidUsageDays
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+	outcomes := map[string]interface{}{
+		"failed":    AICallFailed,
+		"cancelled": AICallCancelled,
+		"succeeded": AICallSucceeded,
+	}
+
+	summary := &dto.AIUsageSummary{GroupBy: group, Days: days, Since: since, Rows: []dto.AIUsageRow{}}
+	if err := s.db.Model(&models.AICall{}).
+		Select(aiUsageAggregates, outcomes).
+		Where("created_at >= ?", since).
+		Scan(&summary.Totals).Error; err != nil {
+		return nil, err
+	}
+
+	q := s.db.Model(&models.AICall{}).
+		Select(g.key+", "+aiUsageAggregates, outcomes).
+		Where("created_at >= ?", since).
+		Group(g.group).
+		Order(g.order)
+	if group == AIUsageByUser {
+		q = q.Where("user_id IS NOT NULL")
+	}
+	if g.limit > 0 {
+		q = q.Limit(g.limit)
+	}
+	if err := q.Scan(&summary.Rows).Error; err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
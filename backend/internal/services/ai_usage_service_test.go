@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/google/uuid"
+)
+
+func TestAICallCost(t *testing.T) {
+	price := config.ModelPrice{Prompt: 0.15, Completion: 0.60}
+	got := aiCallCost(price, aiTokenUsage{PromptTokens: 2000, CompletionTokens: 500})
+	if want := 0.0006; math.Abs(got-want) > 1e-12 {
+		t.Errorf("cost = %v, want %v", got, want)
+	}
+}
+
+func TestAICallScope(t *testing.T) {
+	if scope := aiCallScopeFrom(context.Background()); scope.feature != AIFeatureOther || scope.userID != nil {
+		t.Errorf("default scope = %+v", scope)
+	}
+	userID := uuid.New()
+	ctx, cancel := context.WithCancel(withAICall(context.Background(), AIFeatureNarrative, &userID))
+	defer cancel()
+	scope := aiCallScopeFrom(ctx)
+	if scope.feature != AIFeatureNarrative || scope.userID == nil || *scope.userID != userID {
+		t.Errorf("scope = %+v", scope)
+	}
+}
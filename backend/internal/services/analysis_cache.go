@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Analysis cache backends, see config.AuraAnalysisCache.
+const (
+	AnalysisCacheMemory   = "memory"
+	AnalysisCachePostgres = "postgres"
+	AnalysisCacheOff      = "off"
+)
+
+const (
+	defaultAnalysisCacheTTL        = 24 * time.Hour
+	defaultAnalysisCacheMaxEntries = 10000
+	analysisCachePruneInterval     = 10 * time.Minute
+)
+
+// analysisCacheStore is a cache backend. get reports expired entries as misses.
+type analysisCacheStore interface {
+	get(ctx context.Context, key string) (auraAnalysisResult, bool, error)
+	set(ctx context.Context, key string, result auraAnalysisResult, expiresAt time.Time) error
+	count(ctx context.Context) (int64, error)
+}
+
+// AnalysisCache remembers AI provider results per photo so retries and double taps do not
+// pay for a second analysis. Keys cover the photo bytes, the prompt version, the palette
+// version and the configured provider models, so changing any of them misses the cache.
+// Counters are kept per instance and reset on restart.
+type AnalysisCache struct {
+	backend    string
+	store      analysisCacheStore
+	ttl        time.Duration
+	maxEntries int
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	stores atomic.Int64
+	errors atomic.Int64
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func NewAnalysisCache(db *gorm.DB, cfg *config.Config) *AnalysisCache {
+	c := &AnalysisCache{
+		backend:    cfg.AuraAnalysisCache,
+		ttl:        cfg.AuraAnalysisCacheTTL,
+		maxEntries: cfg.AuraAnalysisCacheMaxEntries,
+	}
+	if c.ttl <= 0 {
+		c.ttl = defaultAnalysisCacheTTL
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = defaultAnalysisCacheMaxEntries
+	}
+	switch c.backend {
+	case AnalysisCacheMemory:
+		c.store = newMemoryAnalysisStore(c.maxEntries)
+	case AnalysisCachePostgres:
+		c.store = &postgresAnalysisStore{db: db, maxEntries: c.maxEntries}
+	case AnalysisCacheOff:
+	default:
+		log.Printf("Unknown AURA_ANALYSIS_CACHE %q, using %q", c.backend, AnalysisCacheMemory)
+		c.backend = AnalysisCacheMemory
+		c.store = newMemoryAnalysisStore(c.maxEntries)
+	}
+	return c
+}
+
+// Start prunes expired and excess rows from the shared store on every interval. The
+// in-process store bounds itself.
+func (c *AnalysisCache) Start() {
+	store, ok := c.store.(*postgresAnalysisStore)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stop = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(analysisCachePruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := store.prune(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to prune analysis cache: %v", err)
+			}
+		}
+	}()
+}
+
+// Stop waits for an in-progress prune to finish.
+func (c *AnalysisCache) Stop() {
+	if c.stop == nil {
+		return
+	}
+	c.stop()
+	c.wg.Wait()
+}
+
+func (c *AnalysisCache) enabled() bool {
+	return c != nil && c.store != nil
+}
+
+// get looks up a result; an empty key is not a lookup. A failing backend is treated as a
+// miss so scans never depend on the cache.
+func (c *AnalysisCache) get(key string) (auraAnalysisResult, bool) {
+	if !c.enabled() || key == "" {
+		return auraAnalysisResult{}, false
+	}
+	result, ok, err := c.store.get(context.Background(), key)
+	if err != nil {
+		c.errors.Add(1)
+		log.Printf("Failed to read analysis cache: %v", err)
+	}
+	if !ok {
+		c.misses.Add(1)
+		return auraAnalysisResult{}, false
+	}
+	c.hits.Add(1)
+	return result, true
+}
+
+func (c *AnalysisCache) set(key string, result auraAnalysisResult) {
+	if !c.enabled() || key == "" {
+		return
+	}
+	if err := c.store.set(context.Background(), key, result, time.Now().Add(c.ttl)); err != nil {
+		c.errors.Add(1)
+		log.Printf("Failed to write analysis cache: %v", err)
+		return
+	}
+	c.stores.Add(1)
+}
+
+// Stats reports the cache configuration, size and this instance's counters. Each hit is a
+// provider call that was not made.
+func (c *AnalysisCache) Stats() (dto.AnalysisCacheStats, error) {
+	stats := dto.AnalysisCacheStats{
+		Backend:    c.backend,
+		MaxEntries: c.maxEntries,
+		TTLSeconds: int64(c.ttl.Seconds()),
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Stores:     c.stores.Load(),
+		Errors:     c.errors.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	if c.store != nil {
+		n, err := c.store.count(context.Background())
+		if err != nil {
+			return stats, err
+		}
+		stats.Entries = n
+	}
+	return stats, nil
+}
+
+// analysisCacheKey identifies one analysis: the exact photo bytes and everything that
+// shapes the provider's answer to them. prompt is the analysis template version ID.
+func analysisCacheKey(img *auraImage, palette *ColorPalette, prompt, providers string) string {
+	h := sha256.New()
+	h.Write(img.raw)
+	fmt.Fprintf(h, "|prompt=%s|palette=%d|providers=%s", prompt, palette.Version, providers)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// --- In-process store ---
+
+type memoryAnalysisEntry struct {
+	result    auraAnalysisResult
+	expiresAt time.Time
+}
+
+// memoryAnalysisStore is an LRU bounded by entry count. Expired entries are dropped when
+// they are read or evicted.
+type memoryAnalysisStore struct {
+	entries *lruCache[memoryAnalysisEntry]
+	now     func() time.Time
+}
+
+func newMemoryAnalysisStore(maxEntries int) *memoryAnalysisStore {
+	return &memoryAnalysisStore{entries: newLRUCache[memoryAnalysisEntry](maxEntries), now: time.Now}
+}
+
+func (m *memoryAnalysisStore) get(_ context.Context, key string) (auraAnalysisResult, bool, error) {
+	entry, ok := m.entries.get(key)
+	if !ok {
+		return auraAnalysisResult{}, false, nil
+	}
+	if !m.now().Before(entry.expiresAt) {
+		m.entries.remove(key)
+		return auraAnalysisResult{}, false, nil
+	}
+	return entry.result, true, nil
+}
+
+func (m *memoryAnalysisStore) set(_ context.Context, key string, result auraAnalysisResult, expiresAt time.Time) error {
+	m.entries.add(key, memoryAnalysisEntry{result: result, expiresAt: expiresAt})
+	return nil
+}
+
+func (m *memoryAnalysisStore) count(context.Context) (int64, error) {
+	return int64(m.entries.len()), nil
+}
+
+// --- Postgres store ---
+
+// postgresAnalysisStore shares results between replicas. The size bound is enforced when
+// pruning, so the table can briefly hold more than maxEntries rows.
+type postgresAnalysisStore struct {
+	db         *gorm.DB
+	maxEntries int
+}
+
+func (p *postgresAnalysisStore) get(ctx context.Context, key string) (auraAnalysisResult, bool, error) {
+	var entry models.AnalysisCacheEntry
+	res := p.db.WithContext(ctx).Where("key = ? AND expires_at > ?", key, time.Now()).Limit(1).Find(&entry)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return auraAnalysisResult{}, false, res.Error
+	}
+	var result auraAnalysisResult
+	if err := json.Unmarshal([]byte(entry.Result), &result); err != nil {
+		return auraAnalysisResult{}, false, err
+	}
+	return result, true, nil
+}
+
+func (p *postgresAnalysisStore) set(ctx context.Context, key string, result auraAnalysisResult, expiresAt time.Time) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	entry := models.AnalysisCacheEntry{Key: key, Result: string(raw), ExpiresAt: expiresAt, CreatedAt: time.Now()}
+	return p.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"result", "expires_at", "created_at"}),
+	}).Create(&entry).Error
+}
+
+func (p *postgresAnalysisStore) prune(ctx context.Context) (int64, error) {
+	db := p.db.WithContext(ctx)
+	expired := db.Where("expires_at <= ?", time.Now()).Delete(&models.AnalysisCacheEntry{})
+	if expired.Error != nil {
+		return 0, expired.Error
+	}
+	excess := db.Where("key IN (?)",
+		db.Model(&models.AnalysisCacheEntry{}).Select("key").Order("created_at DESC").Offset(p.maxEntries),
+	).Delete(&models.AnalysisCacheEntry{})
+	return expired.RowsAffected + excess.RowsAffected, excess.Error
+}
+
+func (p *postgresAnalysisStore) count(ctx context.Context) (int64, error) {
+	var n int64
+	err := p.db.WithContext(ctx).Model(&models.AnalysisCacheEntry{}).Where("expires_at > ?", time.Now()).Count(&n).Error
+	return n, err
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryAnalysisStoreExpiresAndEvicts(t *testing.T) {
+	now := time.Now()
+	store := newMemoryAnalysisStore(2)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = store.set(ctx, "a", auraAnalysisResult{AuraColor: "blue"}, now.Add(time.Minute))
+	_ = store.set(ctx, "b", auraAnalysisResult{AuraColor: "red"}, now.Add(time.Hour))
+	if got, ok, _ := store.get(ctx, "a"); !ok || got.AuraColor != "blue" {
+		t.Fatalf("get a = %+v, %v", got, ok)
+	}
+
+	// "b" is now least recently used and makes room for "c".
+	_ = store.set(ctx, "c", auraAnalysisResult{AuraColor: "green"}, now.Add(time.Hour))
+	if _, ok, _ := store.get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := store.get(ctx, "a"); ok {
+		t.Error("expected a to have expired")
+	}
+	if n, _ := store.count(ctx); n != 1 {
+		t.Errorf("count = %d, want 1", n)
+	}
+}
+
+func TestAnalysisCacheKey(t *testing.T) {
+	img := &auraImage{raw: []byte("photo")}
+	palette := &ColorPalette{Version: 3}
+	key := analysisCacheKey(img, palette, "analysis/builtin", "glm:glm-4.7::1;")
+	if len(key) != 64 {
+		t.Fatalf("key length = %d", len(key))
+	}
+	if analysisCacheKey(img, palette, "analysis/builtin", "glm:glm-4.7::1;") != key {
+		t.Error("key is not stable")
+	}
+	if analysisCacheKey(img, &ColorPalette{Version: 4}, "analysis/builtin", "glm:glm-4.7::1;") == key {
+		t.Error("a new palette version must change the key")
+	}
+	if analysisCacheKey(img, palette, "analysis/builtin", "glm:glm-5::1;") == key {
+		t.Error("a new model must change the key")
+	}
+	if analysisCacheKey(img, palette, "analysis/terse@2", "glm:glm-4.7::1;") == key {
+		t.Error("a new prompt version must change the key")
+	}
+	if analysisCacheKey(&auraImage{raw: []byte("other")}, palette, "analysis/builtin", "glm:glm-4.7::1;") == key {
+		t.Error("different photos must not share a key")
+	}
+}
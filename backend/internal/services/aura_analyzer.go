@@ -0,0 +1,549 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+)
+
+// AuraAnalyzer refines a baseline aura reading using an external model.
+// Implementations must be safe for concurrent use.
+type AuraAnalyzer interface {
+	Name() string
+	Analyze(ctx context.Context, input auraAnalysisInput, base auraAnalysisResult) (auraAnalysisResult, error)
+}
+
+// auraTextCompleter is implemented by analyzers that can also answer free-form JSON
+// prompts, such as reading narratives. check, when set, validates the answer before the
+// call is recorded, so an answer the caller cannot use counts as a failed call.
+type auraTextCompleter interface {
+	CompleteText(ctx context.Context, messages []auraChatMessage, check func(content string) error) (string, error)
+}
+
+var errNoAuraProviderAvailable = errors.New("no aura ai provider available")
+
+// auraAIAnalyzer is the provider registry: analyzers ordered by weight, each guarded by its
+// own circuit breaker. With a hedge delay the next provider is started when the current one
+// has not answered in time, and the first success wins.
+type auraAIAnalyzer struct {
+	providers  []*auraProviderEntry
+	hedgeDelay time.Duration
+	// signature identifies the configured providers and models for analysis cache keys.
+	signature string
+}
+
+type auraProviderEntry struct {
+	name     string
+	model    string
+	weight   int
+	analyzer AuraAnalyzer
+	breaker  *circuitBreaker
+
+	mu            sync.Mutex
+	totalCalls    int64
+	totalFailures int64
+	lastError     string
+	lastSuccessAt time.Time
+	lastFailureAt time.Time
+	lastLatency   time.Duration
+}
+
+func newAuraAIAnalyzer(cfg *config.Config, usage *AIUsageService) *auraAIAnalyzer {
+	threshold := cfg.AuraAIBreakerThreshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+	cooldown := cfg.AuraAIBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+
+	providerCfgs := cfg.AuraProviders()
+	providers := make([]*auraProviderEntry, 0, len(providerCfgs))
+	var signature strings.Builder
+	for _, p := range providerCfgs {
+		fmt.Fprintf(&signature, "%s:%s:%s:%d;", p.Name, p.Model, p.VisionModel, p.Weight)
+		providers = append(providers, &auraProviderEntry{
+			name:     p.Name,
+			model:    p.Model,
+			weight:   p.Weight,
+			analyzer: newOpenAIAnalyzer(p, usage),
+			breaker:  newCircuitBreaker(threshold, cooldown),
+		})
+	}
+
+	// Stable so equal weights keep configuration order.
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].weight > providers[j].weight
+	})
+
+	return &auraAIAnalyzer{
+		providers:  providers,
+		hedgeDelay: cfg.AuraAIHedgeDelay,
+		signature:  signature.String(),
+	}
+}
+
+// analyze runs the providers for one photo. ctx carries the AI call attribution.
+func (a *auraAIAnalyzer) analyze(ctx context.Context, input auraAnalysisInput, base auraAnalysisResult) (auraAnalysisResult, error) {
+	if a == nil || len(a.providers) == 0 {
+		return base, errors.New("aura ai analyzer disabled")
+	}
+
+	return a.run(ctx, a.providers, input, base)
+}
+
+type auraProviderOutcome struct {
+	result auraAnalysisResult
+	err    error
+	entry  *auraProviderEntry
+}
+
+func (a *auraAIAnalyzer) run(ctx context.Context, candidates []*auraProviderEntry, input auraAnalysisInput, base auraAnalysisResult) (auraAnalysisResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make(chan auraProviderOutcome, len(candidates))
+	next, inFlight := 0, 0
+	// launch starts the next candidate whose breaker admits a call and reports whether it
+	// found one. Breakers are asked only here, so a half-open provider's single trial is
+	// taken by a call that actually runs, never by one that a faster success made moot.
+	launch := func() bool {
+		for next < len(candidates) {
+			entry := candidates[next]
+			next++
+			if !entry.breaker.allow() {
+				continue
+			}
+			inFlight++
+			go func() {
+				result, err := entry.call(ctx, input, base)
+				outcomes <- auraProviderOutcome{result: result, err: err, entry: entry}
+			}()
+			return true
+		}
+		return false
+	}
+
+	var hedge <-chan time.Time
+	var timer *time.Timer
+	if a.hedgeDelay > 0 {
+		timer = time.NewTimer(a.hedgeDelay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	if !launch() {
+		return base, errors.New("all aura ai providers are circuit-broken")
+	}
+	lastErr := errNoAuraProviderAvailable
+	for inFlight > 0 {
+		select {
+		case <-hedge:
+			if launch() {
+				timer.Reset(a.hedgeDelay)
+			}
+		case o := <-outcomes:
+			inFlight--
+			if o.err == nil {
+				return o.result, nil
+			}
+			lastErr = fmt.Errorf("%s provider failed: %w", o.entry.name, o.err)
+			if launch() && timer != nil {
+				timer.Reset(a.hedgeDelay)
+			}
+		}
+	}
+
+	return base, lastErr
+}
+
+// complete sends a text prompt to the first available provider that supports it, in
+// registry order, and returns the answer with the provider's name. An answer that fails
+// check moves on to the next provider. Narratives are a nice-to-have, so there is no
+// hedging; the caller's context bounds the whole attempt.
+func (a *auraAIAnalyzer) complete(ctx context.Context, messages []auraChatMessage, check func(content string) error) (string, string, error) {
+	if a == nil || len(a.providers) == 0 {
+		return "", "", errors.New("aura ai analyzer disabled")
+	}
+
+	lastErr := errNoAuraProviderAvailable
+	for _, p := range a.providers {
+		completer, ok := p.analyzer.(auraTextCompleter)
+		if !ok || !p.breaker.allow() {
+			continue
+		}
+
+		start := time.Now()
+		content, err := completer.CompleteText(ctx, messages, check)
+		p.record(ctx, time.Since(start), err)
+		if err == nil {
+			return content, p.name, nil
+		}
+		lastErr = fmt.Errorf("%s provider failed: %w", p.name, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return "", "", lastErr
+}
+
+// call runs one provider and feeds the breaker. Calls cancelled because another hedged
+// provider already won are not counted against this one.
+func (e *auraProviderEntry) call(ctx context.Context, input auraAnalysisInput, base auraAnalysisResult) (auraAnalysisResult, error) {
+	start := time.Now()
+	result, err := e.analyzer.Analyze(ctx, input, base)
+	e.record(ctx, time.Since(start), err)
+	if err != nil {
+		return base, err
+	}
+	return result, nil
+}
+
+// record updates call statistics and the breaker for one finished call.
+func (e *auraProviderEntry) record(ctx context.Context, latency time.Duration, err error) {
+	if err != nil && ctx.Err() != nil {
+		e.breaker.release()
+		return
+	}
+
+	e.mu.Lock()
+	e.totalCalls++
+	e.lastLatency = latency
+	if err != nil {
+		e.totalFailures++
+		e.lastError = err.Error()
+		e.lastFailureAt = time.Now()
+	} else {
+		e.lastSuccessAt = time.Now()
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		e.breaker.failure()
+	} else {
+		e.breaker.success()
+	}
+}
+
+func (e *auraProviderEntry) health() dto.AIProviderHealth {
+	state, failures, openUntil := e.breaker.snapshot()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	h := dto.AIProviderHealth{
+		Name:                e.name,
+		Model:               e.model,
+		Weight:              e.weight,
+		State:               state,
+		ConsecutiveFailures: failures,
+		TotalCalls:          e.totalCalls,
+		TotalFailures:       e.totalFailures,
+		LastError:           e.lastError,
+		LastLatencyMs:       e.lastLatency.Milliseconds(),
+	}
+	if !e.lastSuccessAt.IsZero() {
+		t := e.lastSuccessAt
+		h.LastSuccessAt = &t
+	}
+	if !e.lastFailureAt.IsZero() {
+		t := e.lastFailureAt
+		h.LastFailureAt = &t
+	}
+	if !openUntil.IsZero() {
+		h.OpenUntil = &openUntil
+	}
+	return h
+}
+
+// healthReport lists every registered provider in fallback order.
+func (a *auraAIAnalyzer) healthReport() []dto.AIProviderHealth {
+	if a == nil {
+		return []dto.AIProviderHealth{}
+	}
+	report := make([]dto.AIProviderHealth, 0, len(a.providers))
+	for _, p := range a.providers {
+		report = append(report, p.health())
+	}
+	return report
+}
+
+// --- Circuit breaker ---
+
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// circuitBreaker opens after `threshold` consecutive failures and rejects calls until the
+// cooldown passes, then lets a single trial call through (half-open) to decide whether to close.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     breakerClosed,
+	}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.trial = true
+		return true
+	case breakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release gives back a half-open trial slot without recording an outcome.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *circuitBreaker) snapshot() (string, int, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var openUntil time.Time
+	if b.state == breakerOpen {
+		openUntil = b.openedAt.Add(b.cooldown)
+	}
+	return b.state, b.failures, openUntil
+}
+
+// --- OpenAI-compatible provider ---
+
+type auraChatCompletionRequest struct {
+	Model          string            `json:"model"`
+	Messages       []auraChatMessage `json:"messages"`
+	Temperature    float64           `json:"temperature,omitempty"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+// auraChatMessage content is either a plain string or []auraChatContentPart.
+type auraChatMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+type auraChatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage aiTokenUsage `json:"usage"`
+}
+
+// openAIAnalyzer talks to any chat-completions endpoint (GLM, DeepSeek, OpenAI, ...).
+type openAIAnalyzer struct {
+	name   string
+	apiURL string
+	apiKey string
+	model  string
+
+	// visionModel accepts image_url content parts; empty means the provider is text-only.
+	visionModel   string
+	maxImageBytes int
+	maxImageDim   int
+
+	client *http.Client
+	usage  *AIUsageService
+}
+
+func newOpenAIAnalyzer(p config.AuraProviderConfig, usage *AIUsageService) *openAIAnalyzer {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 20 * time.Second
+	}
+	return &openAIAnalyzer{
+		name:          p.Name,
+		apiURL:        p.URL,
+		apiKey:        p.Key,
+		model:         p.Model,
+		visionModel:   p.VisionModel,
+		maxImageBytes: p.MaxImageBytes,
+		maxImageDim:   p.MaxImageDim,
+		client:        &http.Client{Timeout: timeout},
+		usage:         usage,
+	}
+}
+
+func (p *openAIAnalyzer) Name() string { return p.name }
+
+// Analyze sends the photo to vision-capable providers and the pixel statistics to text-only
+// ones. A vision request the provider rejects (bad or oversized image) is retried once as
+// text so a single provider can still answer.
+func (p *openAIAnalyzer) Analyze(ctx context.Context, input auraAnalysisInput, base auraAnalysisResult) (auraAnalysisResult, error) {
+	if p.visionModel != "" {
+		imageURL, err := input.visionImageURL(p.maxImageDim, p.maxImageBytes)
+		if err == nil {
+			messages, err := auraVisionMessages(input, imageURL, base)
+			if err != nil {
+				return base, err
+			}
+			result, err := p.analyzeWith(ctx, p.visionModel, messages, input.palette, base)
+			var statusErr *auraAIStatusError
+			if err == nil || !errors.As(err, &statusErr) || !statusErr.clientError() {
+				return result, err
+			}
+		}
+	}
+
+	messages, err := auraTextMessages(input, base)
+	if err != nil {
+		return base, err
+	}
+	return p.analyzeWith(ctx, p.model, messages, input.palette, base)
+}
+
+func (p *openAIAnalyzer) analyzeWith(ctx context.Context, model string, messages []auraChatMessage, palette *ColorPalette, base auraAnalysisResult) (auraAnalysisResult, error) {
+	var parsed auraAnalysisResult
+	_, err := p.complete(ctx, model, messages, 0.2, func(content string) error {
+		var err error
+		parsed, err = parseAuraAIContent(content, palette)
+		return err
+	})
+	if err != nil {
+		return base, err
+	}
+
+	return mergeAuraAnalysis(palette, base, parsed), nil
+}
+
+// auraAIStatusError is a non-2xx response from a provider.
+type auraAIStatusError struct {
+	status int
+}
+
+func (e *auraAIStatusError) Error() string {
+	return fmt.Sprintf("aura ai request failed: status=%d", e.status)
+}
+
+func (e *auraAIStatusError) clientError() bool {
+	return e.status >= 400 && e.status < 500 && e.status != http.StatusUnauthorized && e.status != http.StatusTooManyRequests
+}
+
+// CompleteText answers a JSON-mode text prompt with the provider's text model. A higher
+// temperature than analysis keeps narratives from reading alike.
+func (p *openAIAnalyzer) CompleteText(ctx context.Context, messages []auraChatMessage, check func(content string) error) (string, error) {
+	return p.complete(ctx, p.model, messages, 0.8, check)
+}
+
+// complete runs one JSON-mode chat completion, checks the content when check is set,
+// records the call for usage accounting and returns the content. A call only counts as
+// a success once its content has passed the check.
+func (p *openAIAnalyzer) complete(ctx context.Context, model string, messages []auraChatMessage, temperature float64, check func(content string) error) (string, error) {
+	start := time.Now()
+	content, usage, err := p.request(ctx, model, messages, temperature)
+	if err == nil && check != nil {
+		err = check(content)
+	}
+	p.usage.record(ctx, p.name, model, usage, time.Since(start), err)
+	return content, err
+}
+
+func (p *openAIAnalyzer) request(ctx context.Context, model string, messages []auraChatMessage, temperature float64) (string, aiTokenUsage, error) {
+	reqBody := auraChatCompletionRequest{
+		Model:          model,
+		Messages:       messages,
+		Temperature:    temperature,
+		ResponseFormat: map[string]string{"type": "json_object"},
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", aiTokenUsage{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", aiTokenUsage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", aiTokenUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", aiTokenUsage{}, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", aiTokenUsage{}, &auraAIStatusError{status: resp.StatusCode}
+	}
+
+	var completion auraChatCompletionResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return "", aiTokenUsage{}, err
+	}
+	if len(completion.Choices) == 0 {
+		return "", completion.Usage, errors.New("aura ai returned no choices")
+	}
+
+	return strings.TrimSpace(completion.Choices[0].Message.Content), completion.Usage, nil
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+)
+
+// Card sizes served by GET /api/aura/:id/card.png.
+const (
+	CardSizeStory  = "story"
+	CardSizeSquare = "square"
+)
+
+// cardLayout positions the card elements; y values are the tops of each block.
+type cardLayout struct {
+	width, height int
+	margin        int
+	headerY       int
+	badgeY        int
+	orbY, orbR    int
+	nameY         int
+	nameMaxScale  int
+	energyY       int
+	moodY         int
+	textY         int
+	textLines     int
+	textScale     int
+}
+
+var cardLayouts = map[string]cardLayout{
+	CardSizeStory: {
+		width: 1080, height: 1920, margin: 120,
+		headerY: 110, badgeY: 190,
+		orbY: 720, orbR: 340,
+		nameY: 1160, nameMaxScale: 12,
+		energyY: 1330, moodY: 1480,
+		textY: 1640, textLines: 3, textScale: 5,
+	},
+	CardSizeSquare: {
+		width: 1080, height: 1080, margin: 100,
+		headerY: 50, badgeY: 110,
+		orbY: 400, orbR: 200,
+		nameY: 660, nameMaxScale: 10,
+		energyY: 770, moodY: 880,
+		textY: 990, textLines: 1, textScale: 4,
+	},
+}
+
+// auraCard is everything drawn on a card.
+type auraCard struct {
+	primary     color.RGBA
+	secondary   color.RGBA
+	title       string
+	energy      int
+	mood        int
+	personality string
+	streak      int
+	// style is the color's CardStyle*, "" for the plain orb.
+	style string
+}
+
+var (
+	cardBackground = color.RGBA{R: 0x0b, G: 0x06, B: 0x18, A: 0xff}
+	cardWhite      = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	cardMuted      = color.RGBA{R: 0xc4, G: 0xb5, B: 0xfd, A: 0xff}
+	cardTrack      = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x26}
+)
+
+// renderAuraCard draws a card and encodes it as PNG. Labels are written with locale.
+func renderAuraCard(layout cardLayout, card auraCard, locale *i18n.Localizer) ([]byte, error) {
+	img := newCardCanvas(layout, card.primary)
+	if card.style == CardStyleNebula {
+		drawStarfield(img, layout, card.primary)
+	}
+	if card.streak > 0 {
+		drawStreakBadge(img, layout, card, locale)
+	}
+	drawOrb(img, layout.width/2, layout.orbY, layout.orbR, card.primary, card.secondary)
+	switch card.style {
+	case CardStylePrism:
+		drawPrism(img, layout.width/2, layout.orbY, layout.orbR)
+	case CardStyleHalo:
+		drawHalo(img, layout.width/2, layout.orbY, layout.orbR)
+	}
+
+	// Color name, as large as fits between the margins.
+	name := foldCardText(card.title)
+	scale := layout.nameMaxScale
+	for scale > 3 && cardTextWidth(name, scale) > layout.width-2*layout.margin {
+		scale--
+	}
+	drawCardTextCentered(img, layout.nameY, scale, cardWhite, name)
+
+	inner := layout.width - 2*layout.margin
+
+	// Energy meter.
+	drawCardText(img, layout.margin, layout.energyY, 5, cardMuted, foldCardText(locale.T("card.energy")))
+	value := strconv.Itoa(card.energy) + "%"
+	drawCardText(img, layout.width-layout.margin-cardTextWidth(value, 5), layout.energyY, 5, cardWhite, value)
+	barY := layout.energyY + 50
+	fillRoundRect(img, layout.margin, barY, inner, 32, 16, cardTrack)
+	if filled := inner * clamp(card.energy, 0, 100) / 100; filled > 0 {
+		fillRoundRect(img, layout.margin, barY, max(filled, 32), 32, 16, card.primary)
+	}
+
+	// Mood as ten dots.
+	drawCardText(img, layout.margin, layout.moodY, 5, cardMuted, foldCardText(locale.T("card.mood")))
+	value = strconv.Itoa(card.mood) + "/10"
+	drawCardText(img, layout.width-layout.margin-cardTextWidth(value, 5), layout.moodY, 5, cardWhite, value)
+	step := inner / 10
+	for i := 0; i < 10; i++ {
+		c := cardTrack
+		if i < card.mood {
+			c = card.secondary
+		}
+		fillCircle(img, layout.margin+step*i+step/2, layout.moodY+68, 14, c)
+	}
+
+	// First sentence of the personality text.
+	drawCardParagraph(img, layout, card.personality)
+	return encodeCard(img)
+}
+
+// matchCard is everything drawn on a match card.
+type matchCard struct {
+	userColor   color.RGBA
+	friendColor color.RGBA
+	colorNames  string
+	score       int
+	synergy     string
+}
+
+// renderMatchCard draws two overlapping orbs with the compatibility score beneath.
+func renderMatchCard(layout cardLayout, card matchCard, locale *i18n.Localizer) ([]byte, error) {
+	img := newCardCanvas(layout, mixColor(card.userColor, card.friendColor, 0.5))
+
+	r := layout.orbR * 7 / 10
+	offset := layout.orbR * 11 / 20
+	drawOrb(img, layout.width/2-offset, layout.orbY, r, card.userColor, mixColor(card.userColor, card.friendColor, 0.4))
+	drawOrb(img, layout.width/2+offset, layout.orbY, r, card.friendColor, mixColor(card.friendColor, card.userColor, 0.4))
+
+	drawCardTextCentered(img, layout.nameY, layout.nameMaxScale, cardWhite, strconv.Itoa(clamp(card.score, 0, 100))+"%")
+	drawCardTextCentered(img, layout.energyY, 5, cardMuted, foldCardText(locale.T("card.match")))
+
+	names := foldCardText(card.colorNames)
+	scale := 6
+	for scale > 3 && cardTextWidth(names, scale) > layout.width-2*layout.margin {
+		scale--
+	}
+	drawCardTextCentered(img, layout.moodY, scale, cardWhite, names)
+
+	drawCardParagraph(img, layout, card.synergy)
+	return encodeCard(img)
+}
+
+// newCardCanvas paints the background, a dark vertical gradient tinted by the aura
+// color, and the header.
+func newCardCanvas(layout cardLayout, tint color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, layout.width, layout.height))
+	top := mixColor(cardBackground, tint, 0.22)
+	bottom := mixColor(cardBackground, color.RGBA{A: 0xff}, 0.5)
+	for y := 0; y < layout.height; y++ {
+		fillRect(img, 0, y, layout.width, 1, mixColor(top, bottom, float64(y)/float64(layout.height-1)))
+	}
+	drawCardTextCentered(img, layout.headerY, 5, cardMuted, "AURASNAP")
+	return img
+}
+
+// drawCardParagraph writes the first sentence of text in the layout's text block.
+func drawCardParagraph(img *image.RGBA, layout cardLayout, text string) {
+	maxChars := (layout.width - 2*layout.margin) / (cardGlyphAdvance * layout.textScale)
+	lineHeight := (cardGlyphHeight + 5) * layout.textScale
+	for i, line := range wrapCardText(foldCardText(firstSentence(text)), maxChars, layout.textLines) {
+		drawCardTextCentered(img, layout.textY+i*lineHeight, layout.textScale, cardWhite, line)
+	}
+}
+
+func encodeCard(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawStreakBadge(img *image.RGBA, layout cardLayout, card auraCard, locale *i18n.Localizer) {
+	label := foldCardText(locale.T("card.streak", card.streak))
+	const scale, padX, padY = 4, 28, 16
+	w := cardTextWidth(label, scale) + 2*padX
+	h := cardGlyphHeight*scale + 2*padY
+	x := (layout.width - w) / 2
+	fillRoundRect(img, x, layout.badgeY, w, h, h/2, mixColor(card.primary, cardBackground, 0.35))
+	drawCardText(img, x+padX, layout.badgeY+padY, scale, cardWhite, label)
+}
+
+// drawOrb paints a sphere shaded from a light primary core to the secondary color at the
+// rim, surrounded by a soft primary glow.
+func drawOrb(img *image.RGBA, cx, cy, r int, primary, secondary color.RGBA) {
+	core := mixColor(primary, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, 0.35)
+	glow := float64(r) * 1.4
+	b := img.Bounds()
+	for y := max(cy-int(glow), b.Min.Y); y < min(cy+int(glow), b.Max.Y); y++ {
+		for x := max(cx-int(glow), b.Min.X); x < min(cx+int(glow), b.Max.X); x++ {
+			d := math.Hypot(float64(x-cx), float64(y-cy)) / float64(r)
+			switch {
+			case d <= 1:
+				c := mixColor(core, secondary, math.Pow(d, 1.6))
+				// Antialias the last pixel of the rim.
+				edge := math.Min(1, (1-d)*float64(r))
+				blendPixel(img, x, y, c, math.Max(edge, 0))
+				if edge < 1 {
+					blendPixel(img, x, y, primary, 0.55*(1-edge))
+				}
+			case d < 1.4:
+				t := 1 - (d-1)/0.4
+				blendPixel(img, x, y, primary, 0.55*t*t)
+			}
+		}
+	}
+}
+
+// cardStarCount is how many stars the nebula style scatters over the card.
+const cardStarCount = 140
+
+// drawStarfield scatters small stars over the background for the nebula style. Positions
+// come from a fixed xorshift sequence, so a card always renders the same.
+func drawStarfield(img *image.RGBA, layout cardLayout, tint color.RGBA) {
+	seed := uint32(2463534242)
+	next := func(n int) int {
+		seed ^= seed << 13
+		seed ^= seed >> 17
+		seed ^= seed << 5
+		return int(seed % uint32(n))
+	}
+	star := mixColor(cardWhite, tint, 0.25)
+	for i := 0; i < cardStarCount; i++ {
+		x, y, r := next(layout.width), next(layout.height), 1+next(3)
+		fillCircle(img, x, y, r, star)
+	}
+}
+
+// drawPrism sweeps a full hue wheel around the orb for the prism style, strongest toward
+// the rim so the core keeps the aura color.
+func drawPrism(img *image.RGBA, cx, cy, r int) {
+	b := img.Bounds()
+	for y := max(cy-r, b.Min.Y); y < min(cy+r+1, b.Max.Y); y++ {
+		for x := max(cx-r, b.Min.X); x < min(cx+r+1, b.Max.X); x++ {
+			dx, dy := float64(x-cx), float64(y-cy)
+			d := math.Hypot(dx, dy) / float64(r)
+			if d > 1 {
+				continue
+			}
+			hue := math.Atan2(dy, dx)*180/math.Pi + 180
+			edge := math.Min(1, (1-d)*float64(r))
+			blendPixel(img, x, y, hueColor(hue), 0.6*d*edge)
+		}
+	}
+}
+
+// drawHalo rings the orb with two thin bright circles for the halo style.
+func drawHalo(img *image.RGBA, cx, cy, r int) {
+	rings := []struct {
+		radius, width, alpha float64
+	}{
+		{radius: 1.12 * float64(r), width: float64(r) / 40, alpha: 0.9},
+		{radius: 1.24 * float64(r), width: float64(r) / 80, alpha: 0.5},
+	}
+	outer := int(1.3 * float64(r))
+	b := img.Bounds()
+	for y := max(cy-outer, b.Min.Y); y < min(cy+outer, b.Max.Y); y++ {
+		for x := max(cx-outer, b.Min.X); x < min(cx+outer, b.Max.X); x++ {
+			d := math.Hypot(float64(x-cx), float64(y-cy))
+			for _, ring := range rings {
+				blendPixel(img, x, y, cardWhite, ring.alpha*coverage(math.Abs(d-ring.radius), ring.width))
+			}
+		}
+	}
+}
+
+// hueColor is the fully saturated, full brightness color at hue degrees.
+func hueColor(hue float64) color.RGBA {
+	channel := func(n float64) uint8 {
+		k := math.Mod(n+hue/60, 6)
+		return uint8(math.Round(255 * (1 - math.Max(0, math.Min(k, math.Min(4-k, 1))))))
+	}
+	return color.RGBA{R: channel(5), G: channel(3), B: channel(1), A: 0xff}
+}
+
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, ".!?"); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
+
+func parseHexColor(hex string) (color.RGBA, bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
+
+func mixColor(a, b color.RGBA, t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	lerp := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t)) }
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: lerp(a.A, b.A)}
+}
+
+// blendPixel composites c over the pixel at (x, y) with c.A scaled by alpha.
+func blendPixel(img *image.RGBA, x, y int, c color.RGBA, alpha float64) {
+	a := float64(c.A) / 0xff * alpha
+	if a <= 0 {
+		return
+	}
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+4 : i+4]
+	p[0] = uint8(math.Round(float64(p[0])*(1-a) + float64(c.R)*a))
+	p[1] = uint8(math.Round(float64(p[1])*(1-a) + float64(c.G)*a))
+	p[2] = uint8(math.Round(float64(p[2])*(1-a) + float64(c.B)*a))
+	p[3] = 0xff
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, c color.RGBA) {
+	r := image.Rect(x, y, x+w, y+h).Intersect(img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			blendPixel(img, px, py, c, 1)
+		}
+	}
+}
+
+func fillRoundRect(img *image.RGBA, x, y, w, h, radius int, c color.RGBA) {
+	radius = min(radius, w/2, h/2)
+	r := image.Rect(x, y, x+w, y+h).Intersect(img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			// Distance outside the rectangle shrunk by the corner radius.
+			dx := max(x+radius-px, px-(x+w-1-radius), 0)
+			dy := max(y+radius-py, py-(y+h-1-radius), 0)
+			if dx == 0 || dy == 0 {
+				blendPixel(img, px, py, c, 1)
+				continue
+			}
+			blendPixel(img, px, py, c, coverage(math.Hypot(float64(dx), float64(dy)), float64(radius)))
+		}
+	}
+}
+
+func fillCircle(img *image.RGBA, cx, cy, radius int, c color.RGBA) {
+	r := image.Rect(cx-radius-1, cy-radius-1, cx+radius+2, cy+radius+2).Intersect(img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			blendPixel(img, px, py, c, coverage(math.Hypot(float64(px-cx), float64(py-cy)), float64(radius)))
+		}
+	}
+}
+
+// coverage antialiases a shape edge: 1 inside radius, fading to 0 one pixel beyond it.
+func coverage(d, radius float64) float64 {
+	return math.Max(0, math.Min(1, radius+0.5-d))
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/color"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const auraCardCacheSize = 128
+
+var ErrInvalidCardSize = errors.New("card size must be story or square")
+
+// AuraCard is a rendered PNG and the ETag identifying its content.
+type AuraCard struct {
+	PNG  []byte
+	ETag string
+}
+
+// AuraCardService renders shareable PNG cards for readings and matches. Rendered cards are kept in a
+// small LRU keyed by everything drawn on them, so an edited reading, a new streak or a
+// published color catalog produces a fresh card.
+type AuraCardService struct {
+	db     *gorm.DB
+	colors *ColorCatalogService
+	cache  *lruCache[*AuraCard]
+}
+
+func NewAuraCardService(db *gorm.DB, colors *ColorCatalogService) *AuraCardService {
+	return &AuraCardService{
+		db:     db,
+		colors: colors,
+		cache:  newLRUCache[*AuraCard](auraCardCacheSize),
+	}
+}
+
+// Render returns the card for one of the user's readings at the given size ("story" when
+// empty).
+func (s *AuraCardService) Render(userID, readingID uuid.UUID, size string) (*AuraCard, error) {
+	if _, err := cardLayoutFor(size); err != nil {
+		return nil, err
+	}
+	var reading models.AuraReading
+	if err := s.db.Where("id = ? AND user_id = ?", readingID, userID).First(&reading).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReadingNotFound
+		}
+		return nil, err
+	}
+	return s.RenderReading(reading, size)
+}
+
+// RenderReading draws a reading card. Labels use the reading's language so they match
+// its text, and the streak badge shows the owner's current streak.
+func (s *AuraCardService) RenderReading(reading models.AuraReading, size string) (*AuraCard, error) {
+	layout, err := cardLayoutFor(size)
+	if err != nil {
+		return nil, err
+	}
+	var streak models.AuraStreak
+	if err := s.db.Where("user_id = ?", reading.UserID).Limit(1).Find(&streak).Error; err != nil {
+		return nil, err
+	}
+
+	palette := s.colors.Palette()
+	secondary := ""
+	if reading.SecondaryColor != nil {
+		secondary = *reading.SecondaryColor
+	}
+	key := fmt.Sprintf("reading|%s|%s|%d|%d|%s|%s|%d",
+		reading.ID, size, reading.UpdatedAt.UnixNano(), streak.CurrentStreak, reading.Language, secondary, palette.Version)
+	if card, ok := s.cache.get(key); ok {
+		return card, nil
+	}
+
+	locale := i18n.For(reading.Language)
+	card := auraCard{
+		primary:     s.rgb(palette, reading.AuraColor),
+		title:       locale.T("card.aura", palette.Name(locale, reading.AuraColor)),
+		energy:      reading.EnergyLevel,
+		mood:        reading.MoodScore,
+		personality: reading.Personality,
+		streak:      streak.CurrentStreak,
+		style:       palette.CardStyle(reading.AuraColor),
+	}
+	card.secondary = card.primary
+	if secondary != "" {
+		card.secondary = s.rgb(palette, secondary)
+	}
+
+	png, err := renderAuraCard(layout, card, locale)
+	if err != nil {
+		return nil, err
+	}
+	return s.store(key, png), nil
+}
+
+// RenderMatch draws a match card from the match and the two readings it compared, in the
+// match's language.
+func (s *AuraCardService) RenderMatch(match models.AuraMatch, userAura, friendAura models.AuraReading, size string) (*AuraCard, error) {
+	layout, err := cardLayoutFor(size)
+	if err != nil {
+		return nil, err
+	}
+
+	palette := s.colors.Palette()
+	key := fmt.Sprintf("match|%s|%s|%d|%s|%s|%s|%d",
+		match.ID, size, match.UpdatedAt.UnixNano(), match.Language, userAura.AuraColor, friendAura.AuraColor, palette.Version)
+	if card, ok := s.cache.get(key); ok {
+		return card, nil
+	}
+
+	locale := i18n.For(match.Language)
+	png, err := renderMatchCard(layout, matchCard{
+		userColor:   s.rgb(palette, userAura.AuraColor),
+		friendColor: s.rgb(palette, friendAura.AuraColor),
+		colorNames:  palette.Name(locale, userAura.AuraColor) + " + " + palette.Name(locale, friendAura.AuraColor),
+		score:       match.CompatibilityScore,
+		synergy:     match.Synergy,
+	}, locale)
+	if err != nil {
+		return nil, err
+	}
+	return s.store(key, png), nil
+}
+
+// cardLayoutFor resolves a requested size, defaulting to story.
+func cardLayoutFor(size string) (cardLayout, error) {
+	if size == "" {
+		size = CardSizeStory
+	}
+	layout, ok := cardLayouts[size]
+	if !ok {
+		return cardLayout{}, ErrInvalidCardSize
+	}
+	return layout, nil
+}
+
+// rgb resolves a color key to RGB, falling back to the palette's fallback color for keys
+// retired from the catalog.
+func (s *AuraCardService) rgb(palette *ColorPalette, key string) color.RGBA {
+	if c, ok := palette.Color(key); ok {
+		if rgb, ok := parseHexColor(c.Hex); ok {
+			return rgb
+		}
+	}
+	if c, ok := palette.Color(palette.FallbackColor()); ok {
+		if rgb, ok := parseHexColor(c.Hex); ok {
+			return rgb
+		}
+	}
+	return cardMuted
+}
+
+// store caches a rendered card under key and returns it with its ETag.
+func (s *AuraCardService) store(key string, png []byte) *AuraCard {
+	sum := sha256.Sum256([]byte(key))
+	card := &AuraCard{PNG: png, ETag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+	s.cache.add(key, card)
+	return card
+}
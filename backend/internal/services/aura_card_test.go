@@ -0,0 +1,122 @@
+package services
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+)
+
+func TestCardFontParses(t *testing.T) {
+	font := loadCardFont()
+	for _, r := range "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 .,!?'-%/:" {
+		if _, ok := font[r]; !ok {
+			t.Errorf("missing glyph %q", r)
+		}
+	}
+	if _, err := parseCardFont("AB\n#####\n"); err == nil {
+		t.Error("expected a bad header to fail")
+	}
+}
+
+func TestFoldCardText(t *testing.T) {
+	cases := map[string]string{
+		"Çok güçlü ı":     "COK GUCLU I",
+		"Energía  ✨ alta": "ENERGIA ALTA",
+		"Straße 100%":     "STRASSE 100%",
+		"  ruh\thali  ":   "RUH HALI",
+	}
+	for in, want := range cases {
+		if got := foldCardText(in); got != want {
+			t.Errorf("foldCardText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWrapCardText(t *testing.T) {
+	got := wrapCardText("YOU ARE WARM AND CURIOUS", 12, 3)
+	if want := []string{"YOU ARE WARM", "AND CURIOUS"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrap = %q, want %q", got, want)
+	}
+	got = wrapCardText("YOU ARE WARM AND CURIOUS ABOUT PEOPLE", 12, 2)
+	if want := []string{"YOU ARE WARM", "AND CURIO..."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("truncated wrap = %q, want %q", got, want)
+	}
+}
+
+func TestRenderAuraCardSizes(t *testing.T) {
+	card := auraCard{
+		primary:     color.RGBA{R: 0x8b, G: 0x5c, B: 0xf6, A: 0xff},
+		secondary:   color.RGBA{R: 0xec, G: 0x48, B: 0x99, A: 0xff},
+		title:       "Violet Aura",
+		energy:      72,
+		mood:        8,
+		personality: "You are intuitive and imaginative. Others rely on you.",
+		streak:      5,
+	}
+	for size, layout := range cardLayouts {
+		raw, err := renderAuraCard(layout, card, i18n.For("en"))
+		if err != nil {
+			t.Fatalf("%s: %v", size, err)
+		}
+		img, err := png.Decode(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", size, err)
+		}
+		if b := img.Bounds(); b.Dx() != layout.width || b.Dy() != layout.height {
+			t.Errorf("%s: bounds %v", size, b)
+		}
+	}
+}
+
+func TestRenderAuraCardStyles(t *testing.T) {
+	layout := cardLayouts[CardSizeSquare]
+	card := auraCard{
+		primary:   color.RGBA{R: 0x4c, G: 0x1d, B: 0x95, A: 0xff},
+		secondary: color.RGBA{R: 0x4c, G: 0x1d, B: 0x95, A: 0xff},
+		title:     "Cosmic Aura",
+		energy:    64,
+		mood:      7,
+	}
+	plain, err := renderAuraCard(layout, card, i18n.For("en"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{CardStylePrism, CardStyleNebula, CardStyleHalo} {
+		card.style = style
+		styled, err := renderAuraCard(layout, card, i18n.For("en"))
+		if err != nil {
+			t.Fatalf("%s: %v", style, err)
+		}
+		if bytes.Equal(styled, plain) {
+			t.Errorf("%s card looks like the plain card", style)
+		}
+	}
+
+	if got := hueColor(0); got != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("hue 0 = %v", got)
+	}
+	if got := hueColor(240); got != (color.RGBA{B: 0xff, A: 0xff}) {
+		t.Errorf("hue 240 = %v", got)
+	}
+}
+
+func TestRenderMatchCard(t *testing.T) {
+	card := matchCard{
+		userColor:   color.RGBA{R: 0x3b, G: 0x82, B: 0xf6, A: 0xff},
+		friendColor: color.RGBA{R: 0xf9, G: 0x73, B: 0x16, A: 0xff},
+		colorNames:  "Blue + Orange",
+		score:       87,
+		synergy:     "You balance each other out.",
+	}
+	raw, err := renderMatchCard(cardLayouts[CardSizeSquare], card, i18n.For("en"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+}
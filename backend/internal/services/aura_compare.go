@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrCompareSameReading = errors.New("choose two different readings to compare")
+
+const (
+	// Energy changes below compareEnergyStep points read as steady.
+	compareEnergyStep         = 5
+	compareMaxAIExplanation   = 800
+	compareTraitListSeparator = ", "
+
+	defaultCompareAIHourlyLimit = 20
+	compareAICacheSize          = 1000
+)
+
+// AuraComparison is two of a user's readings and how the second differs from the first.
+type AuraComparison struct {
+	A, B          *models.AuraReading
+	Changes       dto.AuraChanges
+	Explanation   string
+	AIExplanation *string
+}
+
+// Compare diffs two of the user's readings, from a to b, and explains the change in the
+// localizer's language. Readings that are missing or belong to someone else are both
+// ErrReadingNotFound. With withAI, providers are also asked for a written
+// explanation; it is left out when they fail or the user reached the hourly limit,
+// since the built-in one always exists. Written explanations are cached per pair of
+// readings and language, so asking again costs no provider call.
+func (s *AuraService) Compare(userID, aID, bID uuid.UUID, withAI bool, locale *i18n.Localizer) (*AuraComparison, error) {
+	if aID == bID {
+		return nil, ErrCompareSameReading
+	}
+	a, err := s.GetByID(userID, aID)
+	if err != nil {
+		return nil, compareLookupError(err)
+	}
+	b, err := s.GetByID(userID, bID)
+	if err != nil {
+		return nil, compareLookupError(err)
+	}
+
+	palette := s.colors.Palette()
+	changes := compareReadings(palette, *a, *b)
+	result := &AuraComparison{
+		A:           a,
+		B:           b,
+		Changes:     changes,
+		Explanation: explainChanges(palette, changes, locale),
+	}
+	if withAI {
+		result.AIExplanation = s.cachedExplainChangesAI(userID, *a, *b, changes, locale)
+	}
+	return result, nil
+}
+
+// cachedExplainChangesAI returns the cached AI explanation for the pair, or asks the
+// providers when the user is under the hourly limit. It returns nil when there is none.
+func (s *AuraService) cachedExplainChangesAI(userID uuid.UUID, a, b models.AuraReading, changes dto.AuraChanges, locale *i18n.Localizer) *string {
+	key := compareCacheKey(a, b, locale.Lang())
+	if text, ok := s.compareCache.get(key); ok {
+		return &text
+	}
+
+	now := time.Now()
+	limitKey := "user:" + userID.String()
+	if !s.compareLimiter.allow(now, limitKey) {
+		log.Printf("AI comparison explanation skipped for %s: hourly limit reached", userID)
+		return nil
+	}
+	// Counted before the call, so a failed or timed-out call still costs an attempt.
+	s.compareLimiter.hit(now, limitKey)
+
+	text, err := s.explainChangesAI(userID, a, b, changes, locale)
+	if err != nil {
+		log.Printf("AI comparison explanation failed: %v", err)
+		return nil
+	}
+	s.compareCache.add(key, text)
+	return &text
+}
+
+// compareCacheKey identifies an explanation by both readings, as last edited, and its
+// language.
+func compareCacheKey(a, b models.AuraReading, lang string) string {
+	return fmt.Sprintf("%s:%d:%s:%d:%s", a.ID, a.UpdatedAt.UnixNano(), b.ID, b.UpdatedAt.UnixNano(), lang)
+}
+
+func compareLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReadingNotFound
+	}
+	return err
+}
+
+func compareReadings(palette *ColorPalette, a, b models.AuraReading) dto.AuraChanges {
+	changes := dto.AuraChanges{
+		ColorChanged:  a.AuraColor != b.AuraColor,
+		FromColor:     a.AuraColor,
+		ToColor:       b.AuraColor,
+		ColorRelation: compatibilityType(palette, a.AuraColor, b.AuraColor),
+		FromSecondary: a.SecondaryColor,
+		ToSecondary:   b.SecondaryColor,
+		EnergyDelta:   b.EnergyLevel - a.EnergyLevel,
+		MoodDelta:     b.MoodScore - a.MoodScore,
+		DaysApart:     int(math.Abs(b.CreatedAt.Sub(a.CreatedAt).Hours()) / 24),
+	}
+	changes.StrengthsGained, changes.StrengthsLost = traitChanges(a.Strengths, b.Strengths)
+	changes.ChallengesGained, changes.ChallengesLost = traitChanges(a.Challenges, b.Challenges)
+	return changes
+}
+
+// traitChanges returns the traits only in to (gained) and only in from (lost), ignoring
+// case and surrounding space.
+func traitChanges(from, to []string) ([]string, []string) {
+	return traitsMissing(to, from), traitsMissing(from, to)
+}
+
+func traitsMissing(traits, other []string) []string {
+	seen := make(map[string]bool, len(other))
+	for _, t := range other {
+		seen[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	missing := []string{}
+	for _, t := range traits {
+		if key := strings.ToLower(strings.TrimSpace(t)); key != "" && !seen[key] {
+			seen[key] = true
+			missing = append(missing, strings.TrimSpace(t))
+		}
+	}
+	return missing
+}
+
+// explainChanges writes the built-in explanation, one sentence per kind of change.
+func explainChanges(palette *ColorPalette, c dto.AuraChanges, locale *i18n.Localizer) string {
+	from, to := palette.Name(locale, c.FromColor), palette.Name(locale, c.ToColor)
+	var parts []string
+	if c.ColorChanged {
+		parts = append(parts, locale.T("compare.color_shift."+c.ColorRelation, from, to))
+	} else {
+		parts = append(parts, locale.T("compare.color_same", to))
+	}
+
+	switch {
+	case c.FromSecondary == nil && c.ToSecondary != nil:
+		parts = append(parts, locale.T("compare.secondary_gained", palette.Name(locale, *c.ToSecondary)))
+	case c.FromSecondary != nil && c.ToSecondary == nil:
+		parts = append(parts, locale.T("compare.secondary_lost", palette.Name(locale, *c.FromSecondary)))
+	case c.FromSecondary != nil && *c.FromSecondary != *c.ToSecondary:
+		parts = append(parts, locale.T("compare.secondary_changed", palette.Name(locale, *c.FromSecondary), palette.Name(locale, *c.ToSecondary)))
+	}
+
+	switch {
+	case c.EnergyDelta >= compareEnergyStep:
+		parts = append(parts, locale.T("compare.energy_up", c.EnergyDelta))
+	case c.EnergyDelta <= -compareEnergyStep:
+		parts = append(parts, locale.T("compare.energy_down", -c.EnergyDelta))
+	default:
+		parts = append(parts, locale.T("compare.energy_steady"))
+	}
+	switch {
+	case c.MoodDelta > 0:
+		parts = append(parts, locale.T("compare.mood_up", c.MoodDelta))
+	case c.MoodDelta < 0:
+		parts = append(parts, locale.T("compare.mood_down", -c.MoodDelta))
+	default:
+		parts = append(parts, locale.T("compare.mood_steady"))
+	}
+
+	if len(c.StrengthsGained) > 0 {
+		parts = append(parts, locale.T("compare.strengths_gained", strings.Join(c.StrengthsGained, compareTraitListSeparator)))
+	}
+	if len(c.StrengthsLost) > 0 {
+		parts = append(parts, locale.T("compare.strengths_lost", strings.Join(c.StrengthsLost, compareTraitListSeparator)))
+	}
+	if len(c.ChallengesGained) > 0 {
+		parts = append(parts, locale.T("compare.challenges_gained", strings.Join(c.ChallengesGained, compareTraitListSeparator)))
+	}
+	if len(c.ChallengesLost) > 0 {
+		parts = append(parts, locale.T("compare.challenges_lost", strings.Join(c.ChallengesLost, compareTraitListSeparator)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// compareReadingSummary is what the compare prompt sees of each reading.
+type compareReadingSummary struct {
+	Date           string             `json:"date"`
+	AuraColor      string             `json:"aura_color"`
+	SecondaryColor *string            `json:"secondary_color,omitempty"`
+	EnergyLevel    int                `json:"energy_level"`
+	MoodScore      int                `json:"mood_score"`
+	ColorMeaning   models.ColorTraits `json:"color_meaning"`
+}
+
+func (s *AuraService) explainChangesAI(userID uuid.UUID, a, b models.AuraReading, changes dto.AuraChanges, locale *i18n.Localizer) (string, error) {
+	palette := s.colors.Palette()
+	summary := func(r models.AuraReading) string {
+		raw, _ := json.Marshal(compareReadingSummary{
+			Date:           r.CreatedAt.Format(narrativeHistoryDateLayout),
+			AuraColor:      r.AuraColor,
+			SecondaryColor: r.SecondaryColor,
+			EnergyLevel:    r.EnergyLevel,
+			MoodScore:      r.MoodScore,
+			ColorMeaning:   palette.Traits(locale, r.AuraColor),
+		})
+		return string(raw)
+	}
+	diff, _ := json.Marshal(changes)
+
+	prompt := s.prompts.Select(PromptCompare, userID)
+	data := comparePromptData{First: summary(a), Second: summary(b), Changes: string(diff), Language: locale.LanguageName()}
+	system, err := prompt.render("system", data)
+	if err != nil {
+		return "", err
+	}
+	user, err := prompt.render("user", data)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(withAICall(context.Background(), AIFeatureCompare, &userID), s.narrativeTimeout)
+	defer cancel()
+	var explanation string
+	if _, _, err := s.analyzer.complete(ctx, []auraChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	}, func(content string) error {
+		var err error
+		explanation, err = parseCompareExplanation(content)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	return explanation, nil
+}
+
+func parseCompareExplanation(content string) (string, error) {
+	var out struct {
+		Explanation string `json:"explanation"`
+	}
+	raw := strings.TrimSpace(content)
+	if start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}"); start >= 0 && end > start {
+		raw = raw[start : end+1]
+	}
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return "", fmt.Errorf("explanation is not JSON: %w", err)
+	}
+	out.Explanation = strings.TrimSpace(out.Explanation)
+	if out.Explanation == "" || utf8.RuneCountInString(out.Explanation) > compareMaxAIExplanation {
+		return "", errors.New("explanation missing or too long")
+	}
+	return out.Explanation, nil
+}
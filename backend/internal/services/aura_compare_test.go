@@ -0,0 +1,101 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestCompareReadings(t *testing.T) {
+	palette := builtinPalette()
+	gold := "gold"
+	now := time.Now()
+	a := models.AuraReading{
+		AuraColor: "blue", EnergyLevel: 40, MoodScore: 6, CreatedAt: now.AddDate(0, 0, -3),
+		Strengths:  []string{"Calm", "Loyal"},
+		Challenges: []string{"Overthinking"},
+	}
+	b := models.AuraReading{
+		AuraColor: "orange", SecondaryColor: &gold, EnergyLevel: 72, MoodScore: 6, CreatedAt: now,
+		Strengths:  []string{"calm ", "Bold"},
+		Challenges: []string{"Overthinking", "Impatience"},
+	}
+
+	c := compareReadings(palette, a, b)
+	if !c.ColorChanged || c.ColorRelation != MatchTypeComplementary {
+		t.Errorf("color change = %v %s", c.ColorChanged, c.ColorRelation)
+	}
+	if c.EnergyDelta != 32 || c.MoodDelta != 0 || c.DaysApart != 3 {
+		t.Errorf("deltas = %d %d %d", c.EnergyDelta, c.MoodDelta, c.DaysApart)
+	}
+	if !reflect.DeepEqual(c.StrengthsGained, []string{"Bold"}) || !reflect.DeepEqual(c.StrengthsLost, []string{"Loyal"}) {
+		t.Errorf("strengths = +%v -%v", c.StrengthsGained, c.StrengthsLost)
+	}
+	if !reflect.DeepEqual(c.ChallengesGained, []string{"Impatience"}) || len(c.ChallengesLost) != 0 {
+		t.Errorf("challenges = +%v -%v", c.ChallengesGained, c.ChallengesLost)
+	}
+
+	for _, lang := range []string{"en", "tr", "es"} {
+		text := explainChanges(palette, c, i18n.For(lang))
+		if strings.Contains(text, "%!") || !strings.Contains(text, "32") || !strings.Contains(text, "Bold") {
+			t.Errorf("%s explanation = %q", lang, text)
+		}
+	}
+}
+
+func TestParseCompareExplanation(t *testing.T) {
+	got, err := parseCompareExplanation("Sure!\n{\"explanation\": \" Your blue calm gave way to orange drive. \"}")
+	if err != nil || got != "Your blue calm gave way to orange drive." {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if _, err := parseCompareExplanation(`{"explanation": ""}`); err == nil {
+		t.Error("expected empty explanation to be rejected")
+	}
+	if _, err := parseCompareExplanation("no json here"); err == nil {
+		t.Error("expected non-JSON answer to be rejected")
+	}
+}
+
+func TestCachedExplainChangesAIHonorsCacheAndLimit(t *testing.T) {
+	s := &AuraService{
+		compareLimiter: newWindowLimiter(1, time.Hour),
+		compareCache:   newLRUCache[string](10),
+	}
+	userID := uuid.New()
+	a := models.AuraReading{ID: uuid.New(), UpdatedAt: time.Now()}
+	b := models.AuraReading{ID: uuid.New(), UpdatedAt: time.Now()}
+	en := i18n.For("en")
+	s.compareCache.add(compareCacheKey(a, b, en.Lang()), "cached")
+	s.compareLimiter.hit(time.Now(), "user:"+userID.String())
+
+	if got := s.cachedExplainChangesAI(userID, a, b, dto.AuraChanges{}, en); got == nil || *got != "cached" {
+		t.Errorf("cached explanation = %v, want it served past the limit", got)
+	}
+	// A miss past the limit must not reach the analyzer, which is nil here.
+	if got := s.cachedExplainChangesAI(userID, b, a, dto.AuraChanges{}, en); got != nil {
+		t.Errorf("explanation %q written past the limit", *got)
+	}
+}
+
+func TestCompareCacheKey(t *testing.T) {
+	a := models.AuraReading{ID: uuid.New(), UpdatedAt: time.Now()}
+	b := models.AuraReading{ID: uuid.New(), UpdatedAt: time.Now()}
+	key := compareCacheKey(a, b, "en")
+	edited := b
+	edited.UpdatedAt = b.UpdatedAt.Add(time.Second)
+	for name, other := range map[string]string{
+		"reversed": compareCacheKey(b, a, "en"),
+		"language": compareCacheKey(a, b, "tr"),
+		"edited":   compareCacheKey(a, edited, "en"),
+	} {
+		if other == key {
+			t.Errorf("%s pair shares the key %q", name, key)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const defaultReadingRetention = 30 * 24 * time.Hour
+
+// MaxBulkDeleteIDs caps the ID list of one bulk delete request.
+const MaxBulkDeleteIDs = 100
+
+var (
+	ErrReadingNotFound  = errors.New("reading not found")
+	ErrInvalidReadingID = errors.New("invalid reading ID")
+	ErrBulkDeleteEmpty  = errors.New("ids or a from/to range is required")
+	ErrBulkDeleteTooBig = errors.New("too many ids in one bulk delete")
+	ErrInvalidDateRange = errors.New("from must be before to")
+)
+
+// bulkDeleteFilter is a validated bulk delete: readings matching any listed ID, plus
+// readings created in [from, to) when a range is given.
+type bulkDeleteFilter struct {
+	ids      []uuid.UUID
+	from, to time.Time
+}
+
+func (f bulkDeleteFilter) hasRange() bool {
+	return !f.from.IsZero()
+}
+
+func parseBulkDelete(req dto.BulkDeleteAuraRequest) (bulkDeleteFilter, error) {
+	var f bulkDeleteFilter
+	if len(req.IDs) > MaxBulkDeleteIDs {
+		return f, ErrBulkDeleteTooBig
+	}
+	seen := make(map[uuid.UUID]bool, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return f, ErrInvalidReadingID
+		}
+		if !seen[id] {
+			seen[id] = true
+			f.ids = append(f.ids, id)
+		}
+	}
+
+	if req.From != nil || req.To != nil {
+		if req.From == nil || req.To == nil || !req.From.Before(*req.To) {
+			return f, ErrInvalidDateRange
+		}
+		f.from, f.to = *req.From, *req.To
+	}
+
+	if len(f.ids) == 0 && !f.hasRange() {
+		return f, ErrBulkDeleteEmpty
+	}
+	return f, nil
+}
+
+// Delete moves a reading to the user's recently deleted list and revokes links sharing
+// it. Its photo and any matches built on it are kept until the purge job removes them
+// after the retention window.
+func (s *AuraService) Delete(userID, id uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND id = ?", userID, id).Delete(&models.AuraReading{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReadingNotFound
+		}
+		return revokeReadingShares(tx, []uuid.UUID{id})
+	})
+}
+
+// DeleteMany soft-deletes the user's readings by ID and/or creation date range and
+// returns how many were deleted. IDs the user does not own are ignored.
+func (s *AuraService) DeleteMany(userID uuid.UUID, req dto.BulkDeleteAuraRequest) (int64, error) {
+	f, err := parseBulkDelete(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []uuid.UUID
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&models.AuraReading{}).Where("user_id = ?", userID)
+		switch {
+		case len(f.ids) > 0 && f.hasRange():
+			q = q.Where(tx.Where("id IN ?", f.ids).Or("created_at >= ? AND created_at < ?", f.from, f.to))
+		case len(f.ids) > 0:
+			q = q.Where("id IN ?", f.ids)
+		default:
+			q = q.Where("created_at >= ? AND created_at < ?", f.from, f.to)
+		}
+		if err := q.Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.Where("id IN ?", ids).Delete(&models.AuraReading{}).Error; err != nil {
+			return err
+		}
+		return revokeReadingShares(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// ListDeleted returns readings the user deleted within the retention window, most
+// recently deleted first.
+func (s *AuraService) ListDeleted(userID uuid.UUID) ([]models.AuraReading, error) {
+	var readings []models.AuraReading
+	err := s.db.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL AND deleted_at >= ?", userID, time.Now().Add(-s.retention)).
+		Order("deleted_at DESC").
+		Find(&readings).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range readings {
+		s.media.Decorate(&readings[i])
+	}
+	return readings, nil
+}
+
+// Restore brings a deleted reading back if it has not been purged yet.
+func (s *AuraService) Restore(userID, id uuid.UUID) (*models.AuraReading, error) {
+	result := s.db.Unscoped().Model(&models.AuraReading{}).
+		Where("user_id = ? AND id = ? AND deleted_at IS NOT NULL AND deleted_at >= ?", userID, id, time.Now().Add(-s.retention)).
+		Update("deleted_at", gorm.Expr("NULL"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrReadingNotFound
+	}
+	return s.GetByID(userID, id)
+}
+
+// PurgeAt is when a reading deleted at deletedAt will be removed for good.
+func (s *AuraService) PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(s.retention)
+}
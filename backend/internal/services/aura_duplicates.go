@@ -0,0 +1,94 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const defaultDuplicateWindow = 24 * time.Hour
+
+// duplicatePolicy is the validated duplicate photo configuration.
+type duplicatePolicy struct {
+	mode      string
+	window    time.Duration
+	threshold int
+}
+
+func newDuplicatePolicy(cfg *config.Config) duplicatePolicy {
+	p := duplicatePolicy{
+		mode:      cfg.AuraDuplicatePolicy,
+		window:    cfg.AuraDuplicateWindow,
+		threshold: cfg.AuraDuplicateThreshold,
+	}
+	switch p.mode {
+	case DuplicatePolicyReuse, DuplicatePolicyFlag, DuplicatePolicyOff:
+	default:
+		log.Printf("Unknown AURA_DUPLICATE_POLICY %q, using %q", p.mode, DuplicatePolicyReuse)
+		p.mode = DuplicatePolicyReuse
+	}
+	if p.window <= 0 {
+		p.window = defaultDuplicateWindow
+	}
+	if p.threshold < 0 || p.threshold > 32 {
+		p.threshold = defaultDuplicateThreshold
+	}
+	return p
+}
+
+// findDuplicate returns the user's newest reading from the duplicate window whose photo
+// hash is within the threshold of hash. Deleted readings never match, so deleting a
+// reading lets the user scan the same photo again.
+func (s *AuraService) findDuplicate(userID uuid.UUID, hash uint64, exclude uuid.UUID) (*models.AuraReading, error) {
+	var candidates []models.AuraReading
+	if err := s.db.Select("id", "image_hash").
+		Where("user_id = ? AND id <> ? AND image_hash IS NOT NULL AND created_at >= ?",
+			userID, exclude, time.Now().Add(-s.duplicates.window)).
+		Order("created_at DESC").
+		Limit(maxDuplicateCandidates).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range candidates {
+		if hashDistance(uint64(*c.ImageHash), hash) <= s.duplicates.threshold {
+			return s.GetByID(userID, c.ID)
+		}
+	}
+	return nil, nil
+}
+
+// reuseDuplicate returns the earlier reading for a re-uploaded photo under the reuse
+// policy, marked as reused. A failed lookup just lets the scan go ahead.
+func (s *AuraService) reuseDuplicate(userID uuid.UUID, img *auraImage) *models.AuraReading {
+	if img == nil || s.duplicates.mode != DuplicatePolicyReuse {
+		return nil
+	}
+	reading, err := s.findDuplicate(userID, img.hash, uuid.Nil)
+	if err != nil {
+		log.Printf("Duplicate photo lookup failed for user %s: %v", userID, err)
+		return nil
+	}
+	if reading != nil {
+		reading.Reused = true
+	}
+	return reading
+}
+
+// flagDuplicate returns the earlier reading a new one duplicates under the flag policy.
+func (s *AuraService) flagDuplicate(userID, readingID uuid.UUID, img *auraImage) *uuid.UUID {
+	if s.duplicates.mode != DuplicatePolicyFlag {
+		return nil
+	}
+	reading, err := s.findDuplicate(userID, img.hash, readingID)
+	if err != nil {
+		log.Printf("Duplicate photo lookup failed for user %s: %v", userID, err)
+		return nil
+	}
+	if reading == nil {
+		return nil
+	}
+	return &reading.ID
+}
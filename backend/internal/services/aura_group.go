@@ -0,0 +1,417 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrGroupImageRequired is returned for a group scan sent as a URL; people can only be
+	// found in a photo we have decoded.
+	ErrGroupImageRequired = errors.New("group scans need image_data or an upload")
+	ErrNoGroupFound       = errors.New("fewer than two people were found in the photo")
+)
+
+// MaxGroupScanPeople caps the readings one group photo can produce, largest faces first.
+const MaxGroupScanPeople = 6
+
+const (
+	// groupMaskSide is the long side of the grid the skin mask is built on.
+	groupMaskSide    = 96
+	groupCropQuality = 90
+)
+
+// personRegion is one person found in a group photo: the skin blob of their face (and
+// often neck) and the wider box around it that their aura is read from.
+type personRegion struct {
+	face image.Rectangle
+	body image.Rectangle
+}
+
+// detectPeople finds the people in a photo with a skin-tone segmentation: skin pixels on
+// a coarse grid are smoothed, grouped into connected blobs, and blobs stacked in the same
+// column (face, neck, chest) are merged. Blobs that are too small or the wrong shape for a
+// face, such as hands, are dropped. Regions are returned left to right.
+func detectPeople(img image.Image) []personRegion {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+
+	step := max(1, max(w, h)/groupMaskSide)
+	gw, gh := (w+step-1)/step, (h+step-1)/step
+	mask := make([]bool, gw*gh)
+	for gy := 0; gy < gh; gy++ {
+		for gx := 0; gx < gw; gx++ {
+			x := min(b.Min.X+gx*step+step/2, b.Max.X-1)
+			y := min(b.Min.Y+gy*step+step/2, b.Max.Y-1)
+			r16, g16, b16, _ := img.At(x, y).RGBA()
+			r, g, bl := float64(r16>>8), float64(g16>>8), float64(b16>>8)
+			if _, _, val := rgbToHSV(r, g, bl); val >= 0.2 && isSkinTone(r, g, bl) {
+				mask[gy*gw+gx] = true
+			}
+		}
+	}
+
+	blobs := mergeBlobColumns(skinBlobs(smoothMask(mask, gw, gh), gw, gh))
+
+	minArea := max(6, gw*gh/500)
+	largest := 0
+	faces := blobs[:0]
+	for _, blob := range blobs {
+		bw, bh := blob.box.Dx(), blob.box.Dy()
+		if blob.area < minArea || bh*5 < bw*2 || bh > bw*4 || blob.area*4 < bw*bh {
+			continue
+		}
+		faces = append(faces, blob)
+		largest = max(largest, blob.area)
+	}
+
+	sort.Slice(faces, func(i, j int) bool { return faces[i].area > faces[j].area })
+	kept := faces[:0]
+	for _, face := range faces {
+		if face.area*5 >= largest && len(kept) < MaxGroupScanPeople {
+			kept = append(kept, face)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].box.Min.X < kept[j].box.Min.X })
+
+	regions := make([]personRegion, 0, len(kept))
+	for i, face := range kept {
+		// Each body box is centred on its face and stops halfway to the neighbouring faces,
+		// so people standing close together are not read from each other's clothes.
+		center := (face.box.Min.X + face.box.Max.X) / 2
+		left, right := 0, gw
+		if i > 0 {
+			left = (center + (kept[i-1].box.Min.X+kept[i-1].box.Max.X)/2) / 2
+		}
+		if i < len(kept)-1 {
+			right = (center + (kept[i+1].box.Min.X+kept[i+1].box.Max.X)/2) / 2
+		}
+		fw, fh := face.box.Dx(), face.box.Dy()
+		body := image.Rect(
+			max(left, center-fw*3/2), face.box.Min.Y-fh/2,
+			min(right, center+fw*3/2), face.box.Max.Y+fh*3,
+		)
+		regions = append(regions, personRegion{
+			face: gridToPixels(face.box, step, b),
+			body: gridToPixels(body, step, b),
+		})
+	}
+	return regions
+}
+
+func gridToPixels(r image.Rectangle, step int, bounds image.Rectangle) image.Rectangle {
+	return image.Rect(r.Min.X*step, r.Min.Y*step, r.Max.X*step, r.Max.Y*step).Add(bounds.Min).Intersect(bounds)
+}
+
+// smoothMask applies a 3x3 majority filter, closing the gaps eyes and shadows leave in a
+// face and removing speckles.
+func smoothMask(mask []bool, w, h int) []bool {
+	out := make([]bool, len(mask))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx >= 0 && nx < w && ny >= 0 && ny < h && mask[ny*w+nx] {
+						n++
+					}
+				}
+			}
+			out[y*w+x] = n >= 5
+		}
+	}
+	return out
+}
+
+type skinBlob struct {
+	box  image.Rectangle
+	area int
+}
+
+// skinBlobs labels the 4-connected components of the mask.
+func skinBlobs(mask []bool, w, h int) []skinBlob {
+	seen := make([]bool, len(mask))
+	var blobs []skinBlob
+	var stack []int
+	for start, skin := range mask {
+		if !skin || seen[start] {
+			continue
+		}
+		seen[start] = true
+		stack = append(stack[:0], start)
+		blob := skinBlob{box: image.Rect(start%w, start/w, start%w+1, start/w+1)}
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w, i/w
+			blob.area++
+			blob.box = blob.box.Union(image.Rect(x, y, x+1, y+1))
+			for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[0] >= w || n[1] < 0 || n[1] >= h {
+					continue
+				}
+				if j := n[1]*w + n[0]; mask[j] && !seen[j] {
+					seen[j] = true
+					stack = append(stack, j)
+				}
+			}
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs
+}
+
+// mergeBlobColumns joins blobs that overlap horizontally by at least half the narrower
+// one: people in a group photo stand side by side, so such blobs belong to one person.
+func mergeBlobColumns(blobs []skinBlob) []skinBlob {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(blobs) && !merged; i++ {
+			for j := i + 1; j < len(blobs); j++ {
+				a, b := blobs[i].box, blobs[j].box
+				overlap := min(a.Max.X, b.Max.X) - max(a.Min.X, b.Min.X)
+				if overlap*2 < min(a.Dx(), b.Dx()) {
+					continue
+				}
+				blobs[i] = skinBlob{box: a.Union(b), area: blobs[i].area + blobs[j].area}
+				blobs = append(blobs[:j], blobs[j+1:]...)
+				merged = true
+				break
+			}
+		}
+	}
+	return blobs
+}
+
+// cropAuraImage cuts one person out of a group photo as a scan image of its own, so it
+// goes through the same analysis, storage and thumbnails as a single scan.
+func cropAuraImage(src *auraImage, r image.Rectangle) (*auraImage, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), src.img, r.Min, draw.Src)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: groupCropQuality}); err != nil {
+		return nil, err
+	}
+	return &auraImage{
+		raw:      buf.Bytes(),
+		format:   "jpeg",
+		img:      dst,
+		features: analyzeImagePixels(dst),
+		hash:     dHash(dst),
+	}, nil
+}
+
+// CreateGroup scans a group photo from a JSON request. Group scans need the photo itself.
+func (s *AuraService) CreateGroup(userID uuid.UUID, req dto.CreateAuraRequest, locale *i18n.Localizer) (*dto.GroupScanResponse, error) {
+	imageURL, img, err := s.prepareScan(req)
+	if err != nil {
+		return nil, err
+	}
+	if img == nil {
+		return nil, ErrGroupImageRequired
+	}
+	return s.groupScanWithQuota(userID, imageURL, img, locale)
+}
+
+// CreateGroupFromImage scans a group photo from a multipart upload.
+func (s *AuraService) CreateGroupFromImage(userID uuid.UUID, raw []byte, locale *i18n.Localizer) (*dto.GroupScanResponse, error) {
+	img, err := s.loadScanImage(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupScanWithQuota(userID, "base64_upload", img, locale)
+}
+
+// groupScanWithQuota reserves a single scan for the whole photo. People are found before
+// reserving, so a photo without a group never touches the quota.
+func (s *AuraService) groupScanWithQuota(userID uuid.UUID, imageURL string, img *auraImage, locale *i18n.Localizer) (*dto.GroupScanResponse, error) {
+	regions := detectPeople(img.img)
+	if len(regions) < 2 {
+		return nil, ErrNoGroupFound
+	}
+
+	reservation, err := s.quota.Reserve(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.createGroupReadings(userID, imageURL, img, regions, locale)
+	if err != nil {
+		s.quota.refund(&reservation.ID)
+		return nil, err
+	}
+	s.quota.commit(&reservation.ID, resp.Members[0].Reading.ID)
+	return resp, nil
+}
+
+// createGroupReadings reads every person's aura in parallel and saves the group scan. If
+// any reading fails, the ones already saved are removed so the scan is all or nothing.
+func (s *AuraService) createGroupReadings(userID uuid.UUID, imageURL string, img *auraImage, regions []personRegion, locale *i18n.Localizer) (*dto.GroupScanResponse, error) {
+	scan := &models.GroupScan{
+		ID:       uuid.New(),
+		UserID:   userID,
+		ImageURL: imageURL,
+		People:   len(regions),
+		Language: locale.Lang(),
+	}
+
+	readings := make([]*models.AuraReading, len(regions))
+	errs := make([]error, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			crop, err := cropAuraImage(img, region.body)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			readings[i], errs[i] = s.createReading(userID, imageURL, crop, scanOptions{locale: locale, groupScanID: &scan.ID})
+		}()
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	members := make([]dto.GroupScanMember, 0, len(regions))
+	saved := make([]models.AuraReading, 0, len(regions))
+	for i, reading := range readings {
+		if reading == nil {
+			continue
+		}
+		saved = append(saved, *reading)
+		members = append(members, dto.GroupScanMember{
+			Index:   i,
+			Region:  groupScanRegion(regions[i].body, img.img.Bounds()),
+			Reading: *reading,
+		})
+	}
+
+	var compatibility dto.GroupCompatibility
+	if err == nil {
+		compatibility = groupCompatibility(s.colors.Palette(), saved, locale)
+		scan.AverageScore = compatibility.AverageScore
+		scan.Summary = compatibility.Summary
+		err = s.db.Create(scan).Error
+	}
+	if err != nil {
+		s.discardGroupReadings(saved)
+		return nil, err
+	}
+
+	return &dto.GroupScanResponse{
+		ID:            scan.ID,
+		People:        scan.People,
+		Members:       members,
+		Compatibility: compatibility,
+		CreatedAt:     scan.CreatedAt,
+	}, nil
+}
+
+func (s *AuraService) discardGroupReadings(readings []models.AuraReading) {
+	if len(readings) == 0 {
+		return
+	}
+	ids := make([]uuid.UUID, len(readings))
+	for i, r := range readings {
+		ids[i] = r.ID
+	}
+	if err := s.db.Unscoped().Where("id IN ?", ids).Delete(&models.AuraReading{}).Error; err != nil {
+		log.Printf("Failed to discard group scan readings: %v", err)
+		return
+	}
+	s.media.DeleteReadingImages(readings...)
+}
+
+func groupScanRegion(r, bounds image.Rectangle) dto.GroupScanRegion {
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	return dto.GroupScanRegion{
+		X:      float64(r.Min.X-bounds.Min.X) / w,
+		Y:      float64(r.Min.Y-bounds.Min.Y) / h,
+		Width:  float64(r.Dx()) / w,
+		Height: float64(r.Dy()) / h,
+	}
+}
+
+// groupPairBase is the starting score for each color pairing before energy and mood gaps
+// are taken off.
+var groupPairBase = map[string]int{
+	MatchTypeSame:          88,
+	MatchTypeComplementary: 80,
+	MatchTypeNeutral:       64,
+	MatchTypeChallenging:   46,
+}
+
+// groupPairScore scores two group members, including their secondary accents. Unlike a
+// friend match it is deterministic, so the same photo always gets the same summary.
+func groupPairScore(palette *ColorPalette, a, b models.AuraReading) (int, string) {
+	matchType := compatibilityType(palette, a.AuraColor, b.AuraColor)
+	score := groupPairBase[matchType] + blendShift(palette, a, b) - absInt(a.EnergyLevel-b.EnergyLevel)/5 - 2*absInt(a.MoodScore-b.MoodScore)
+	return clamp(score, 0, 100), matchType
+}
+
+// groupCompatibility scores every pair of members and summarizes the group in the
+// localizer's language.
+func groupCompatibility(palette *ColorPalette, readings []models.AuraReading, locale *i18n.Localizer) dto.GroupCompatibility {
+	result := dto.GroupCompatibility{Pairs: []dto.GroupPairScore{}}
+	if len(readings) == 0 {
+		return result
+	}
+
+	total := 0
+	for i := range readings {
+		for j := i + 1; j < len(readings); j++ {
+			score, matchType := groupPairScore(palette, readings[i], readings[j])
+			pair := dto.GroupPairScore{A: i, B: j, Score: score, MatchType: matchType}
+			result.Pairs = append(result.Pairs, pair)
+			if result.BestPair == nil || score > result.BestPair.Score {
+				best := pair
+				result.BestPair = &best
+			}
+			total += score
+		}
+	}
+	if len(result.Pairs) > 0 {
+		result.AverageScore = (total + len(result.Pairs)/2) / len(result.Pairs)
+	}
+
+	counts := make(map[string]int, len(readings))
+	for _, r := range readings {
+		counts[r.AuraColor]++
+		if counts[r.AuraColor] > counts[result.DominantColor] {
+			result.DominantColor = r.AuraColor
+		}
+	}
+
+	level := "dynamic"
+	switch {
+	case result.AverageScore >= 75:
+		level = "harmonious"
+	case result.AverageScore >= 55:
+		level = "balanced"
+	}
+	result.Summary = locale.T("group.summary."+level, len(readings), palette.Name(locale, result.DominantColor))
+	return result
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+)
+
+// groupPhoto draws a face over a shirt for each shirt color, side by side on a grey wall.
+func groupPhoto(shirts ...color.RGBA) *image.RGBA {
+	img := solidImage(color.RGBA{R: 128, G: 128, B: 128, A: 255}, 200*len(shirts), 300)
+	skin := &image.Uniform{C: color.RGBA{R: 224, G: 172, B: 140, A: 255}}
+	for i, shirt := range shirts {
+		x := 200*i + 70
+		draw.Draw(img, image.Rect(x, 40, x+60, 120), skin, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x-40, 140, x+100, 300), &image.Uniform{C: shirt}, image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func TestDetectPeopleInGroupPhoto(t *testing.T) {
+	red := color.RGBA{R: 200, G: 30, B: 30, A: 255}
+	green := color.RGBA{R: 30, G: 160, B: 60, A: 255}
+	blue := color.RGBA{R: 30, G: 110, B: 220, A: 255}
+	photo := groupPhoto(red, green, blue)
+
+	regions := detectPeople(photo)
+	if len(regions) != 3 {
+		t.Fatalf("found %d people, want 3", len(regions))
+	}
+
+	src := &auraImage{img: photo}
+	for i, want := range []string{"red", "green", "blue"} {
+		if center := (regions[i].face.Min.X + regions[i].face.Max.X) / 2; center < 200*i+80 || center > 200*i+120 {
+			t.Errorf("person %d face at %v", i, regions[i].face)
+		}
+		crop, err := cropAuraImage(src, regions[i].body)
+		if err != nil {
+			t.Fatalf("crop %d: %v", i, err)
+		}
+		if got := imageAuraResult(crop.features).AuraColor; got != want {
+			t.Errorf("person %d aura = %s, want %s", i, got, want)
+		}
+	}
+
+	if got := detectPeople(groupPhoto(red)); len(got) != 1 {
+		t.Errorf("found %d people in a solo photo", len(got))
+	}
+	if got := detectPeople(solidImage(blue, 200, 200)); len(got) != 0 {
+		t.Errorf("found %d people in an empty photo", len(got))
+	}
+}
+
+func TestGroupCompatibility(t *testing.T) {
+	palette := builtinPalette()
+	readings := []models.AuraReading{
+		{AuraColor: "blue", EnergyLevel: 60, MoodScore: 7},
+		{AuraColor: "orange", EnergyLevel: 65, MoodScore: 7},
+		{AuraColor: "blue", EnergyLevel: 40, MoodScore: 4},
+	}
+
+	got := groupCompatibility(palette, readings, i18n.For("en"))
+	if len(got.Pairs) != 3 {
+		t.Fatalf("pairs = %d, want 3", len(got.Pairs))
+	}
+	// blue/orange are complements with close energy and mood, the best pair.
+	if got.BestPair == nil || got.BestPair.A != 0 || got.BestPair.B != 1 || got.BestPair.MatchType != MatchTypeComplementary {
+		t.Errorf("best pair = %+v", got.BestPair)
+	}
+	if got.DominantColor != "blue" {
+		t.Errorf("dominant color = %s", got.DominantColor)
+	}
+	if again := groupCompatibility(palette, readings, i18n.For("en")); again.AverageScore != got.AverageScore {
+		t.Error("group score is not deterministic")
+	}
+	if got.Summary == "" || strings.Contains(got.Summary, "%!") {
+		t.Errorf("summary = %q", got.Summary)
+	}
+}
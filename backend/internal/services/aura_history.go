@@ -0,0 +1,220 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultReadingPageSize = 20
+	MaxReadingPageSize     = 100
+	historyDateLayout      = "2006-01-02"
+)
+
+var (
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrInvalidReadingFilter = errors.New("invalid reading filter")
+)
+
+// readingCursor is the (created_at, id) of the last reading on a page. Pages are ordered
+// newest first with id as the tiebreak, so the next page is everything strictly before it.
+type readingCursor struct {
+	createdAt time.Time
+	id        uuid.UUID
+}
+
+func (c readingCursor) encode() string {
+	raw := c.createdAt.UTC().Format(time.RFC3339Nano) + "|" + c.id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeReadingCursor(s string) (readingCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return readingCursor{}, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return readingCursor{}, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return readingCursor{}, ErrInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return readingCursor{}, ErrInvalidCursor
+	}
+	return readingCursor{createdAt: createdAt, id: parsedID}, nil
+}
+
+// readingListQuery is a validated history request.
+type readingListQuery struct {
+	cursor         *readingCursor
+	pageSize       int
+	color          string
+	secondaryColor string
+	from, to       *time.Time
+	minEnergy      int
+	maxEnergy      int
+	minMood        int
+	maxMood        int
+}
+
+// parseReadingListQuery validates history query parameters. Page sizes outside 1-100 are
+// clamped. Dates are RFC 3339 timestamps or YYYY-MM-DD days in loc; from is inclusive
+// and to exclusive, except that a plain to date includes that whole day.
+func parseReadingListQuery(req dto.AuraListQuery, loc *time.Location) (readingListQuery, error) {
+	q := readingListQuery{
+		pageSize:       req.PageSize,
+		color:          strings.ToLower(strings.TrimSpace(req.Color)),
+		secondaryColor: strings.ToLower(strings.TrimSpace(req.SecondaryColor)),
+	}
+	switch {
+	case q.pageSize <= 0:
+		q.pageSize = DefaultReadingPageSize
+	case q.pageSize > MaxReadingPageSize:
+		q.pageSize = MaxReadingPageSize
+	}
+
+	if req.Cursor != "" {
+		c, err := decodeReadingCursor(req.Cursor)
+		if err != nil {
+			return q, err
+		}
+		q.cursor = &c
+	}
+
+	var err error
+	if q.from, err = parseHistoryTime(req.From, loc, false); err != nil {
+		return q, err
+	}
+	if q.to, err = parseHistoryTime(req.To, loc, true); err != nil {
+		return q, err
+	}
+	if q.from != nil && q.to != nil && !q.from.Before(*q.to) {
+		return q, ErrInvalidReadingFilter
+	}
+
+	ranges := []struct {
+		raw      string
+		dst      *int
+		min, max int
+	}{
+		{req.MinEnergy, &q.minEnergy, 1, 100},
+		{req.MaxEnergy, &q.maxEnergy, 1, 100},
+		{req.MinMood, &q.minMood, 1, 10},
+		{req.MaxMood, &q.maxMood, 1, 10},
+	}
+	for _, r := range ranges {
+		if r.raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(r.raw)
+		if err != nil || v < r.min || v > r.max {
+			return q, ErrInvalidReadingFilter
+		}
+		*r.dst = v
+	}
+	if (q.maxEnergy > 0 && q.minEnergy > q.maxEnergy) || (q.maxMood > 0 && q.minMood > q.maxMood) {
+		return q, ErrInvalidReadingFilter
+	}
+	return q, nil
+}
+
+func parseHistoryTime(raw string, loc *time.Location, endOfDay bool) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	day, err := time.ParseInLocation(historyDateLayout, raw, loc)
+	if err != nil {
+		return nil, ErrInvalidReadingFilter
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
+
+func (q readingListQuery) apply(db *gorm.DB) *gorm.DB {
+	if q.color != "" {
+		db = db.Where("aura_color = ?", q.color)
+	}
+	if q.secondaryColor != "" {
+		db = db.Where("secondary_color = ?", q.secondaryColor)
+	}
+	if q.from != nil {
+		db = db.Where("created_at >= ?", *q.from)
+	}
+	if q.to != nil {
+		db = db.Where("created_at < ?", *q.to)
+	}
+	if q.minEnergy > 0 {
+		db = db.Where("energy_level >= ?", q.minEnergy)
+	}
+	if q.maxEnergy > 0 {
+		db = db.Where("energy_level <= ?", q.maxEnergy)
+	}
+	if q.minMood > 0 {
+		db = db.Where("mood_score >= ?", q.minMood)
+	}
+	if q.maxMood > 0 {
+		db = db.Where("mood_score <= ?", q.maxMood)
+	}
+	return db
+}
+
+// ReadingPage is one page of history. NextCursor is empty on the last page.
+type ReadingPage struct {
+	Readings   []models.AuraReading
+	PageSize   int
+	NextCursor string
+	TotalCount int64
+}
+
+// List returns the user's readings newest first using keyset pagination on
+// (created_at, id), so pages stay stable while new scans arrive. TotalCount counts every
+// reading matching the filters, not just the rest of the pages.
+func (s *AuraService) List(userID uuid.UUID, req dto.AuraListQuery) (*ReadingPage, error) {
+	q, err := parseReadingListQuery(req, s.quota.location(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ReadingPage{PageSize: q.pageSize}
+	if err := q.apply(s.db.Model(&models.AuraReading{}).Where("user_id = ?", userID)).
+		Count(&page.TotalCount).Error; err != nil {
+		return nil, err
+	}
+
+	list := q.apply(s.db.Where("user_id = ?", userID))
+	if q.cursor != nil {
+		list = list.Where("(created_at, id) < (?, ?)", q.cursor.createdAt, q.cursor.id)
+	}
+	// One extra row tells us whether another page exists.
+	if err := list.Order("created_at DESC, id DESC").Limit(q.pageSize + 1).Find(&page.Readings).Error; err != nil {
+		return nil, err
+	}
+
+	if len(page.Readings) > q.pageSize {
+		page.Readings = page.Readings[:q.pageSize]
+		last := page.Readings[len(page.Readings)-1]
+		page.NextCursor = readingCursor{createdAt: last.CreatedAt, id: last.ID}.encode()
+	}
+	for i := range page.Readings {
+		s.media.Decorate(&page.Readings[i])
+	}
+	return page, nil
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"strings"
+)
+
+// ErrInvalidImage is returned when uploaded image bytes are not a decodable JPEG or PNG.
+var ErrInvalidImage = errors.New("image could not be decoded; only JPEG and PNG are supported")
+
+// Upper bound on decoded pixels so a tiny compressed file cannot expand into gigabytes.
+const auraImageMaxPixels = 40 * 1000 * 1000
+
+// Sampling grid used for pixel statistics; large photos are strided down to roughly this size.
+const auraImageSampleSide = 160
+
+// auraImage is a decoded scan photo together with the statistics derived from its pixels.
+type auraImage struct {
+	raw      []byte
+	format   string
+	img      image.Image
+	features imageFeatures
+	// hash is the photo's difference hash, see dHash.
+	hash uint64
+}
+
+// imageFeatures summarizes the colour and tone of a photo. All ratios are 0..1,
+// brightness is 0..1 and contrast is the luminance standard deviation on a 0..255 scale.
+type imageFeatures struct {
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	DominantHue    float64 `json:"dominant_hue"`
+	SecondaryHue   float64 `json:"secondary_hue"`
+	HueStrength    float64 `json:"hue_strength"`
+	Saturation     float64 `json:"saturation"`
+	Brightness     float64 `json:"brightness"`
+	Contrast       float64 `json:"contrast"`
+	Warmth         float64 `json:"warmth"`
+	ChromaticRatio float64 `json:"chromatic_ratio"`
+	NeutralRatio   float64 `json:"neutral_ratio"`
+	GoldRatio      float64 `json:"gold_ratio"`
+}
+
+// decodeImageData accepts raw base64 or a data URL ("data:image/png;base64,...").
+func decodeImageData(data string) ([]byte, error) {
+	data = strings.TrimSpace(data)
+	if strings.HasPrefix(data, "data:") {
+		comma := strings.Index(data, ",")
+		if comma < 0 {
+			return nil, ErrInvalidImage
+		}
+		data = data[comma+1:]
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		// Some clients strip padding.
+		raw, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+		if err != nil {
+			return nil, ErrInvalidImage
+		}
+	}
+	return raw, nil
+}
+
+func loadAuraImage(raw []byte) (*auraImage, error) {
+	if len(raw) == 0 {
+		return nil, ErrInvalidImage
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > auraImageMaxPixels {
+		return nil, ErrInvalidImage
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	return &auraImage{
+		raw:      raw,
+		format:   format,
+		img:      img,
+		features: analyzeImagePixels(img),
+		hash:     dHash(img),
+	}, nil
+}
+
+// mimeType returns the content type of the original upload.
+func (a *auraImage) mimeType() string {
+	if a.format == "png" {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+const hueBins = 24
+
+// analyzeImagePixels walks a strided grid over the image and builds a saturation-weighted
+// hue histogram plus tonal statistics. Skin-like pixels are down-weighted so the aura is
+// driven by the light, clothing and background around the person rather than their face.
+func analyzeImagePixels(img image.Image) imageFeatures {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	features := imageFeatures{Width: w, Height: h}
+	if w == 0 || h == 0 {
+		return features
+	}
+
+	step := int(math.Max(1, math.Floor(math.Max(float64(w), float64(h))/auraImageSampleSide)))
+
+	var hist [hueBins]float64
+	var n, lumSum, lumSqSum, satSum, chromatic, neutral, warm, gold, totalWeight float64
+
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			r16, g16, b16, _ := img.At(x, y).RGBA()
+			r, g, bl := float64(r16>>8), float64(g16>>8), float64(b16>>8)
+
+			lum := 0.2126*r + 0.7152*g + 0.0722*bl
+			hue, sat, val := rgbToHSV(r, g, bl)
+
+			n++
+			lumSum += lum
+			lumSqSum += lum * lum
+			satSum += sat
+
+			if sat < 0.15 || val < 0.15 {
+				neutral++
+				continue
+			}
+
+			weight := sat * sat * val
+			if isSkinTone(r, g, bl) {
+				weight *= 0.35
+			}
+
+			chromatic++
+			totalWeight += weight
+			hist[int(hue/(360.0/hueBins))%hueBins] += weight
+
+			if hue < 70 || hue >= 300 {
+				warm += weight
+			}
+			if hue >= 35 && hue < 55 && sat >= 0.3 && sat <= 0.8 && val >= 0.35 && val <= 0.8 {
+				gold += weight
+			}
+		}
+	}
+
+	if n == 0 {
+		return features
+	}
+
+	mean := lumSum / n
+	variance := lumSqSum/n - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+
+	features.Brightness = mean / 255
+	features.Contrast = math.Sqrt(variance)
+	features.Saturation = satSum / n
+	features.ChromaticRatio = chromatic / n
+	features.NeutralRatio = neutral / n
+
+	if totalWeight > 0 {
+		features.Warmth = warm / totalWeight
+		features.GoldRatio = gold / totalWeight
+
+		primary, primaryWeight := peakHueBin(hist, -1)
+		features.DominantHue = refineHue(hist, primary)
+		features.HueStrength = primaryWeight / totalWeight
+
+		if secondary, secondaryWeight := peakHueBin(hist, primary); secondary >= 0 && secondaryWeight >= primaryWeight*0.4 {
+			features.SecondaryHue = refineHue(hist, secondary)
+		} else {
+			features.SecondaryHue = -1
+		}
+	} else {
+		features.DominantHue = -1
+		features.SecondaryHue = -1
+	}
+
+	return features
+}
+
+// peakHueBin returns the heaviest bin (summed with its neighbours), skipping bins within two
+// steps of exclude so a secondary peak is a genuinely different hue.
+func peakHueBin(hist [hueBins]float64, exclude int) (int, float64) {
+	best, bestWeight := -1, 0.0
+	for i := 0; i < hueBins; i++ {
+		if exclude >= 0 && hueBinDistance(i, exclude) <= 2 {
+			continue
+		}
+		weight := hist[i] + 0.5*(hist[(i+hueBins-1)%hueBins]+hist[(i+1)%hueBins])
+		if weight > bestWeight {
+			best, bestWeight = i, weight
+		}
+	}
+	return best, bestWeight
+}
+
+func hueBinDistance(a, b int) int {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	if d > hueBins/2 {
+		d = hueBins - d
+	}
+	return d
+}
+
+// refineHue computes a weighted circular mean over a bin and its neighbours.
+func refineHue(hist [hueBins]float64, bin int) float64 {
+	binWidth := 360.0 / hueBins
+	var sx, sy float64
+	for _, i := range []int{(bin + hueBins - 1) % hueBins, bin, (bin + 1) % hueBins} {
+		angle := (float64(i) + 0.5) * binWidth * math.Pi / 180
+		sx += hist[i] * math.Cos(angle)
+		sy += hist[i] * math.Sin(angle)
+	}
+	hue := math.Atan2(sy, sx) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	}
+	return hue
+}
+
+func rgbToHSV(r, g, b float64) (float64, float64, float64) {
+	r, g, b = r/255, g/255, b/255
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+
+	var hue float64
+	switch {
+	case delta == 0:
+		hue = 0
+	case maxC == r:
+		hue = 60 * math.Mod((g-b)/delta, 6)
+	case maxC == g:
+		hue = 60 * ((b-r)/delta + 2)
+	default:
+		hue = 60 * ((r-g)/delta + 4)
+	}
+	if hue < 0 {
+		hue += 360
+	}
+
+	sat := 0.0
+	if maxC > 0 {
+		sat = delta / maxC
+	}
+	return hue, sat, maxC
+}
+
+// isSkinTone is the classic YCbCr skin range test.
+func isSkinTone(r, g, b float64) bool {
+	cb := 128 - 0.168736*r - 0.331264*g + 0.5*b
+	cr := 128 + 0.5*r - 0.418688*g - 0.081312*b
+	return cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173
+}
+
+// hueToAuraColor maps a hue (plus tone) onto the aura palette.
+func hueToAuraColor(hue, sat, brightness float64) string {
+	switch {
+	case hue < 15 || hue >= 340:
+		if sat < 0.45 && brightness > 0.65 {
+			return "pink"
+		}
+		return "red"
+	case hue < 35:
+		return "orange"
+	case hue < 55:
+		if sat <= 0.75 && brightness >= 0.3 && brightness <= 0.75 {
+			return "gold"
+		}
+		return "yellow"
+	case hue < 70:
+		return "yellow"
+	case hue < 170:
+		return "green"
+	case hue < 225:
+		return "blue"
+	case hue < 260:
+		return "indigo"
+	case hue < 295:
+		return "violet"
+	default:
+		return "pink"
+	}
+}
+
+// imageAuraResult turns pixel statistics into the baseline reading that AI providers refine.
+func imageAuraResult(f imageFeatures) auraAnalysisResult {
+	var color string
+	if f.DominantHue < 0 || f.ChromaticRatio < 0.08 {
+		switch {
+		case f.Brightness > 0.6:
+			color = "white"
+		case f.Brightness < 0.3:
+			color = "indigo"
+		default:
+			color = "violet"
+		}
+	} else {
+		color = hueToAuraColor(f.DominantHue, f.Saturation, f.Brightness)
+	}
+
+	contrastNorm := math.Min(f.Contrast/70, 1)
+	energy := 25 + 40*f.Saturation + 25*contrastNorm + 10*f.Brightness
+	mood := 2 + 5*f.Brightness + 2*f.Warmth + f.Saturation
+
+	var secondary *string
+	if candidate := imageSecondaryColor(f); candidate != "" && candidate != color {
+		secondary = &candidate
+	}
+
+	return auraAnalysisResult{
+		AuraColor:      color,
+		SecondaryColor: secondary,
+		EnergyLevel:    clamp(int(math.Round(energy)), 1, 100),
+		MoodScore:      clamp(int(math.Round(mood)), 1, 10),
+	}
+}
+
+// imageSecondaryColor picks a tonal accent from secondaryColors, or "" when the photo has
+// no clear secondary character.
+func imageSecondaryColor(f imageFeatures) string {
+	switch {
+	case f.Brightness < 0.22:
+		return "black"
+	case f.GoldRatio > 0.2:
+		return "gold"
+	case f.NeutralRatio > 0.45 && f.Brightness > 0.75:
+		return "white"
+	case f.NeutralRatio > 0.45 && f.Contrast > 55:
+		return "silver"
+	case f.NeutralRatio > 0.6:
+		return "grey"
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func solidImage(c color.RGBA, w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageAuraResultFollowsDominantHue(t *testing.T) {
+	cases := []struct {
+		name  string
+		color color.RGBA
+		want  string
+	}{
+		{"red", color.RGBA{220, 20, 30, 255}, "red"},
+		{"green", color.RGBA{30, 200, 60, 255}, "green"},
+		{"blue", color.RGBA{20, 90, 230, 255}, "blue"},
+		{"violet", color.RGBA{150, 40, 220, 255}, "violet"},
+		{"white", color.RGBA{245, 245, 245, 255}, "white"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := loadAuraImage(encodePNG(t, solidImage(tc.color, 64, 64)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := imageAuraResult(img.features)
+			if result.AuraColor != tc.want {
+				t.Fatalf("expected %s, got %s (features %+v)", tc.want, result.AuraColor, img.features)
+			}
+			if result.EnergyLevel < 1 || result.EnergyLevel > 100 {
+				t.Fatalf("energy out of range: %d", result.EnergyLevel)
+			}
+			if result.MoodScore < 1 || result.MoodScore > 10 {
+				t.Fatalf("mood out of range: %d", result.MoodScore)
+			}
+		})
+	}
+}
+
+func TestImageAuraResultDiffersPerImage(t *testing.T) {
+	dark, err := loadAuraImage(encodePNG(t, solidImage(color.RGBA{30, 20, 60, 255}, 32, 32)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bright, err := loadAuraImage(encodePNG(t, solidImage(color.RGBA{250, 200, 40, 255}, 32, 32)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	darkResult := imageAuraResult(dark.features)
+	brightResult := imageAuraResult(bright.features)
+
+	if darkResult.AuraColor == brightResult.AuraColor {
+		t.Fatalf("expected different colors, both %s", darkResult.AuraColor)
+	}
+	if darkResult.MoodScore >= brightResult.MoodScore {
+		t.Fatalf("expected brighter image to score higher mood: %d vs %d", darkResult.MoodScore, brightResult.MoodScore)
+	}
+	if darkResult.SecondaryColor == nil || *darkResult.SecondaryColor != "black" {
+		t.Fatalf("expected black secondary for dark image, got %#v", darkResult.SecondaryColor)
+	}
+}
+
+func TestDecodeImageDataAcceptsDataURL(t *testing.T) {
+	raw := encodePNG(t, solidImage(color.RGBA{10, 10, 10, 255}, 4, 4))
+	encoded := "data:image/png;base64," + base64.StdEncoding.EncodeToString(raw)
+
+	decoded, err := decodeImageData(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(decoded, raw) {
+		t.Fatalf("decoded bytes differ from original")
+	}
+
+	if _, err := loadAuraImage([]byte("not an image")); err != ErrInvalidImage {
+		t.Fatalf("expected ErrInvalidImage, got %v", err)
+	}
+}
+
+func TestImageQualityGateMatchesMobileChecks(t *testing.T) {
+	dark, err := loadAuraImage(encodePNG(t, solidImage(color.RGBA{15, 12, 20, 255}, 200, 200)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := evaluateImageQuality(dark)
+	if result.OK {
+		t.Fatalf("expected dark, flat, small photo to be rejected: %+v", result)
+	}
+	want := []QualityIssueCode{QualityLowResolution, QualityLowLight, QualityLowContrast, QualityBlurry}
+	if len(result.Issues) != len(want) {
+		t.Fatalf("expected issues %v, got %v", want, result.Issues)
+	}
+	for i := range want {
+		if result.Issues[i] != want[i] {
+			t.Fatalf("expected issues %v, got %v", want, result.Issues)
+		}
+	}
+	if result.Score != 100-20-14-9-18 {
+		t.Fatalf("unexpected score %d", result.Score)
+	}
+	if result.Message != "Photo resolution is too low. Move closer and try again." {
+		t.Fatalf("unexpected message %q", result.Message)
+	}
+
+	sharp := image.NewRGBA(image.Rect(0, 0, 640, 640))
+	for y := 0; y < 640; y++ {
+		for x := 0; x < 640; x++ {
+			c := color.RGBA{60, 70, 80, 255}
+			if (x/5+y/5)%2 == 0 {
+				c = color.RGBA{200, 190, 180, 255}
+			}
+			sharp.SetRGBA(x, y, c)
+		}
+	}
+	good, err := loadAuraImage(encodePNG(t, sharp))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := evaluateImageQuality(good); !result.OK || len(result.Issues) != 0 {
+		t.Fatalf("expected sharp, well-lit photo to pass: %+v", result)
+	}
+}
+
+func TestDHashNearDuplicates(t *testing.T) {
+	base := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			v := uint8((x*x + y*3) % 256)
+			base.Set(x, y, color.RGBA{R: v, G: uint8(y), B: 255 - v, A: 255})
+		}
+	}
+
+	// The same photo a little brighter and at a different size.
+	brighter := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			c := base.RGBAAt(x/2, y/2)
+			brighter.Set(x, y, color.RGBA{R: sat8(int(c.R) + 12), G: sat8(int(c.G) + 12), B: sat8(int(c.B) + 12), A: 255})
+		}
+	}
+
+	flipped := image.NewRGBA(base.Bounds())
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			flipped.Set(319-x, y, base.At(x, y))
+		}
+	}
+
+	if d := hashDistance(dHash(base), dHash(brighter)); d > defaultDuplicateThreshold {
+		t.Errorf("near-duplicate distance %d, want <= %d", d, defaultDuplicateThreshold)
+	}
+	if d := hashDistance(dHash(base), dHash(flipped)); d <= defaultDuplicateThreshold {
+		t.Errorf("different photo distance %d, want > %d", d, defaultDuplicateThreshold)
+	}
+}
+
+func sat8(v int) uint8 {
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,54 +15,32 @@ import (
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type AuraMatchService struct {
-	db  *gorm.DB
-	cfg *config.Config
-}
-
-func NewAuraMatchService(db *gorm.DB, cfg *config.Config) *AuraMatchService {
-	return &AuraMatchService{db: db, cfg: cfg}
-}
-
-// Complementary color pairs for high compatibility
-var complementaryColors = map[string]string{
-	"red":    "green",
-	"green":  "red",
-	"blue":   "orange",
-	"orange": "blue",
-	"yellow": "violet",
-	"violet": "yellow",
-	"indigo": "gold",
-	"gold":   "indigo",
-	"pink":   "white",
-	"white":  "pink",
-}
-
-// Synergy messages based on color combinations
-var synergyMessages = map[string]string{
-	"same":          "You share a deep soul connection! Your energies resonate on the same frequency.",
-	"complementary": "Your energies perfectly balance each other. What one lacks, the other provides.",
-	"neutral":       "Your auras have a harmonious blend, creating a stable and grounded connection.",
-	"challenging":   "Your energies create exciting tension - you push each other to grow.",
-}
+var (
+	ErrInvalidFriendID     = errors.New("invalid friend ID")
+	ErrSelfMatch           = errors.New("cannot match with yourself")
+	ErrNoAuraReading       = errors.New("you need an aura reading first")
+	ErrFriendNoAuraReading = errors.New("friend doesn't have an aura reading yet")
+	ErrMatchNotFriends     = errors.New("you can only match with accepted friends")
+)
 
-var tensionMessages = map[string]string{
-	"same":          "Too much similarity can lead to stagnation. Seek new experiences together.",
-	"complementary": "Your differences may sometimes cause misunderstandings. Communicate openly.",
-	"neutral":       "Neither of you may feel deeply challenged. Actively inspire each other.",
-	"challenging":   "Conflicting energies require patience and understanding to navigate.",
+type AuraMatchService struct {
+	db      *gorm.DB
+	cfg     *config.Config
+	colors  *ColorCatalogService
+	usage   *AIUsageService
+	prompts *PromptService
+	friends *FriendService
 }
 
-var adviceMessages = map[string]string{
-	"same":          "Celebrate your similarities while exploring new territories together.",
-	"complementary": "Embrace your differences as gifts. Learn from each other's strengths.",
-	"neutral":       "Build intentional rituals to deepen your connection over time.",
-	"challenging":   "Practice patience and active listening. Your growth potential is immense.",
+// NewAuraMatchService only matches accepted friends, checked through friends.
+func NewAuraMatchService(db *gorm.DB, cfg *config.Config, colors *ColorCatalogService, usage *AIUsageService, prompts *PromptService, friends *FriendService) *AuraMatchService {
+	return &AuraMatchService{db: db, cfg: cfg, colors: colors, usage: usage, prompts: prompts, friends: friends}
 }
 
 // compatibilityAIResult represents the JSON structure returned by OpenAI for match analysis
@@ -72,63 +51,43 @@ type compatibilityAIResult struct {
 	Advice             string `json:"advice"`
 }
 
-const matchSystemPrompt = `You are an aura compatibility analyst. You understand color theory, energy dynamics, and personality psychology as they relate to aura colors.
-
-The AuraSnap aura color system includes these colors and their meanings:
-- Red: Passionate, energetic, action-oriented. Strengths: courage, leadership. Challenges: impulsiveness.
-- Orange: Creative, social, adventurous. Strengths: creativity, optimism. Challenges: scattered focus.
-- Yellow: Optimistic, intellectual, cheerful. Strengths: analytical thinking, positivity. Challenges: overthinking.
-- Green: Balanced, growth-oriented, nurturing. Strengths: compassion, reliability. Challenges: jealousy.
-- Blue: Calm, intuitive, trustworthy. Strengths: communication, intuition. Challenges: fear of expression.
-- Indigo: Intuitive, wise, spiritual. Strengths: vision, wisdom. Challenges: isolation.
-- Violet: Visionary, artistic, magical. Strengths: imagination, humanitarianism. Challenges: unrealistic expectations.
-- White: Pure, balanced, spiritually connected. Strengths: healing, high vibration. Challenges: vulnerability.
-- Gold: Confident, abundant, empowered. Strengths: confidence, generosity. Challenges: ego.
-- Pink: Loving, gentle, compassionate. Strengths: empathy, nurturing. Challenges: lack of boundaries.
-
-Analyze the compatibility between two people based on their aura data. Consider:
-1. Color theory: complementary colors (red-green, blue-orange, yellow-violet, indigo-gold, pink-white) have natural harmony.
-2. Energy levels: similar energy levels indicate natural rhythm compatibility; large gaps may cause friction.
-3. Mood alignment: similar mood scores suggest emotional resonance.
-4. Personality traits: look for complementary strengths and overlapping challenges.
-
-Return ONLY valid JSON with these exact fields:
-- compatibility_score: integer 0-100 (0=incompatible, 50=neutral, 80+=highly compatible, 95+=soulmate level)
-- synergy: 2-3 sentences describing the positive dynamics and strengths of this pairing
-- tension: 1-2 sentences about potential friction points or growth areas
-- advice: 1-2 sentences of practical relationship guidance for this specific pairing
-
-Be specific and personal — reference the actual colors, traits, and energy levels provided. Do not give generic responses.`
-
-func (s *AuraMatchService) calculateCompatibilityAI(userAura, friendAura models.AuraReading) (*compatibilityAIResult, error) {
-	userPrompt := fmt.Sprintf(`Analyze compatibility between these two auras:
-
-Person A:
-- Aura Color: %s
-- Energy Level: %d/100
-- Mood Score: %d/10
-- Personality: %s
-- Strengths: %s
-- Challenges: %s
-
-Person B:
-- Aura Color: %s
-- Energy Level: %d/100
-- Mood Score: %d/10
-- Personality: %s
-- Strengths: %s
-- Challenges: %s`,
-		userAura.AuraColor, userAura.EnergyLevel, userAura.MoodScore,
-		userAura.Personality, strings.Join(userAura.Strengths, ", "), strings.Join(userAura.Challenges, ", "),
-		friendAura.AuraColor, friendAura.EnergyLevel, friendAura.MoodScore,
-		friendAura.Personality, strings.Join(friendAura.Strengths, ", "), strings.Join(friendAura.Challenges, ", "),
-	)
+func newMatchPromptPerson(aura models.AuraReading) matchPromptPerson {
+	person := matchPromptPerson{
+		AuraColor:   aura.AuraColor,
+		EnergyLevel: aura.EnergyLevel,
+		MoodScore:   aura.MoodScore,
+		Personality: aura.Personality,
+		Strengths:   strings.Join(aura.Strengths, ", "),
+		Challenges:  strings.Join(aura.Challenges, ", "),
+	}
+	if aura.SecondaryColor != nil {
+		person.SecondaryColor = *aura.SecondaryColor
+	}
+	return person
+}
+
+func (s *AuraMatchService) calculateCompatibilityAI(prompt *promptVariant, palette *ColorPalette, userAura, friendAura models.AuraReading, locale *i18n.Localizer) (*compatibilityAIResult, error) {
+	data := matchPromptData{
+		ColorMeanings:   palette.colorMeanings(),
+		ComplementPairs: palette.complementPairs(),
+		Language:        locale.LanguageName(),
+		A:               newMatchPromptPerson(userAura),
+		B:               newMatchPromptPerson(friendAura),
+	}
+	systemPrompt, err := prompt.render("system", data)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := prompt.render("user", data)
+	if err != nil {
+		return nil, err
+	}
 
 	// Reuse the OpenAI request/response types defined in aura_service.go (same package)
 	reqBody := openAIRequest{
-		Model: "gpt-4o-mini",
+		Model: s.cfg.OpenAIModel,
 		Messages: []openAIMessage{
-			{Role: "system", Content: matchSystemPrompt},
+			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userPrompt},
 		},
 		MaxTokens:   300,
@@ -143,11 +102,57 @@ Person B:
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	ctx := withAICall(context.Background(), AIFeatureMatch, &userAura.UserID)
+	start := time.Now()
+	content, usage, err := s.requestCompatibility(bodyBytes)
+	var result *compatibilityAIResult
+	if err == nil {
+		result, err = parseCompatibilityResult(content)
+	}
+	s.usage.record(ctx, "openai", s.cfg.OpenAIModel, usage, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseCompatibilityResult validates the AI's answer, clamping the score to 0-100.
+func parseCompatibilityResult(content string) (*compatibilityAIResult, error) {
+	var result compatibilityAIResult
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse compatibility JSON: %w", err)
+	}
+
+	// Validate compatibility_score is 0-100
+	if result.CompatibilityScore < 0 {
+		result.CompatibilityScore = 0
+	}
+	if result.CompatibilityScore > 100 {
+		result.CompatibilityScore = 100
+	}
+
+	// Validate non-empty text fields
+	if strings.TrimSpace(result.Synergy) == "" {
+		return nil, fmt.Errorf("AI returned empty synergy")
+	}
+	if strings.TrimSpace(result.Tension) == "" {
+		return nil, fmt.Errorf("AI returned empty tension")
+	}
+	if strings.TrimSpace(result.Advice) == "" {
+		return nil, fmt.Errorf("AI returned empty advice")
+	}
+
+	return &result, nil
+}
+
+// requestCompatibility sends a chat completion request to OpenAI and returns the message
+// content with the token usage it reported.
+func (s *AuraMatchService) requestCompatibility(bodyBytes []byte) (string, aiTokenUsage, error) {
 	client := &http.Client{Timeout: 30 * time.Second}
 
 	httpReq, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(bodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", aiTokenUsage{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -155,152 +160,153 @@ Person B:
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return "", aiTokenUsage{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return "", aiTokenUsage{}, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(respBody))
+		return "", aiTokenUsage{}, fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	var openAIResp openAIResponse
 	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+		return "", aiTokenUsage{}, fmt.Errorf("failed to parse OpenAI response: %w", err)
 	}
 
 	if openAIResp.Error != nil {
-		return nil, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
+		return "", openAIResp.Usage, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
 	}
 
 	if len(openAIResp.Choices) == 0 {
-		return nil, fmt.Errorf("OpenAI returned no choices")
+		return "", openAIResp.Usage, fmt.Errorf("OpenAI returned no choices")
 	}
 
-	content := openAIResp.Choices[0].Message.Content
-
-	var result compatibilityAIResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse compatibility JSON: %w", err)
-	}
+	return openAIResp.Choices[0].Message.Content, openAIResp.Usage, nil
+}
 
-	// Validate compatibility_score is 0-100
-	if result.CompatibilityScore < 0 {
-		result.CompatibilityScore = 0
-	}
-	if result.CompatibilityScore > 100 {
-		result.CompatibilityScore = 100
-	}
+// Color pairings, also the suffix of the match.synergy/tension/advice text keys.
+const (
+	MatchTypeSame          = "same"
+	MatchTypeComplementary = "complementary"
+	MatchTypeChallenging   = "challenging"
+	MatchTypeNeutral       = "neutral"
+)
 
-	// Validate non-empty text fields
-	if strings.TrimSpace(result.Synergy) == "" {
-		return nil, fmt.Errorf("AI returned empty synergy")
+// compatibilityType classifies how two aura colors pair up in the palette.
+func compatibilityType(palette *ColorPalette, a, b string) string {
+	switch {
+	case a == b:
+		return MatchTypeSame
+	case palette.Complement(a) == b:
+		return MatchTypeComplementary
+	case palette.Challenging(a, b):
+		return MatchTypeChallenging
+	default:
+		return MatchTypeNeutral
 	}
-	if strings.TrimSpace(result.Tension) == "" {
-		return nil, fmt.Errorf("AI returned empty tension")
-	}
-	if strings.TrimSpace(result.Advice) == "" {
-		return nil, fmt.Errorf("AI returned empty advice")
-	}
-
-	return &result, nil
 }
 
-func (s *AuraMatchService) calculateCompatibilityFallback(userColor, friendColor string) (int, string, string, string) {
+// calculateCompatibilityFallback scores a pairing from its primary colors, then lets the
+// secondary accents move the score by up to blendMaxShift points.
+func (s *AuraMatchService) calculateCompatibilityFallback(palette *ColorPalette, userAura, friendAura models.AuraReading, locale *i18n.Localizer) (int, string, string, string) {
 	var score int
-	var matchType string
+	userColor, friendColor := userAura.AuraColor, friendAura.AuraColor
+	matchType := compatibilityType(palette, userColor, friendColor)
 
-	// Same color = 85-100%
-	if userColor == friendColor {
+	switch matchType {
+	case MatchTypeSame:
+		// Same color = 85-100%
 		score = 85 + rand.Intn(16)
-		matchType = "same"
-	} else if complementaryColors[userColor] == friendColor {
+	case MatchTypeComplementary:
 		// Complementary colors = 70-90%
 		score = 70 + rand.Intn(21)
-		matchType = "complementary"
-	} else {
-		// Check if colors are "challenging"
-		challengingPairs := map[string][]string{
-			"red":    {"orange", "gold"},
-			"blue":   {"indigo", "violet"},
-			"green":  {"pink", "white"},
-			"yellow": {"orange", "gold"},
-		}
-
-		found := false
-		for color, challengers := range challengingPairs {
-			if userColor == color {
-				for _, c := range challengers {
-					if friendColor == c {
-						score = 30 + rand.Intn(31)
-						matchType = "challenging"
-						found = true
-						break
-					}
-				}
-			}
-			if found {
-				break
-			}
-		}
-
-		if !found {
-			// Neutral = 50-75%
-			score = 50 + rand.Intn(26)
-			matchType = "neutral"
-		}
+	case MatchTypeChallenging:
+		// Challenging pairs = 30-60%
+		score = 30 + rand.Intn(31)
+	default:
+		// Neutral = 50-75%
+		score = 50 + rand.Intn(26)
 	}
 
-	synergy := fmt.Sprintf("%s Your %s aura meets their %s energy. %s", synergyMessages[matchType], userColor, friendColor, getSynergyDetail(userColor, friendColor))
-	tension := tensionMessages[matchType]
-	advice := adviceMessages[matchType]
+	synergy := locale.T("match.synergy_summary",
+		locale.T("match.synergy."+matchType),
+		palette.Name(locale, userColor), palette.Name(locale, friendColor),
+		palette.MatchDetail(locale, userColor), palette.MatchDetail(locale, friendColor),
+	)
+	tension := locale.T("match.tension." + matchType)
+	advice := locale.T("match.advice." + matchType)
+
+	shift := blendShift(palette, userAura, friendAura)
+	score = clamp(score+shift, 0, 100)
+	switch {
+	case shift >= blendNoteShift:
+		synergy += " " + locale.T("match.blend.harmony")
+	case shift <= -blendNoteShift:
+		tension += " " + locale.T("match.blend.friction")
+	}
 
 	return score, synergy, tension, advice
 }
 
-func (s *AuraMatchService) Create(userID uuid.UUID, req dto.CreateMatchRequest) (*dto.AuraMatchResponse, error) {
+// Create scores the user against a friend's latest reading, writing the match text in
+// the localizer's language. The friend must have accepted a friend request and neither
+// may have blocked the other; otherwise it is ErrMatchNotFriends, so a stranger's
+// reading is never read.
+func (s *AuraMatchService) Create(userID uuid.UUID, req dto.CreateMatchRequest, locale *i18n.Localizer) (*dto.AuraMatchResponse, error) {
 	friendID, err := uuid.Parse(req.FriendID)
 	if err != nil {
-		return nil, errors.New("invalid friend ID")
+		return nil, ErrInvalidFriendID
 	}
 
 	if userID == friendID {
-		return nil, errors.New("cannot match with yourself")
+		return nil, ErrSelfMatch
+	}
+
+	friends, err := s.friends.AreFriends(userID, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if !friends {
+		return nil, ErrMatchNotFriends
 	}
 
 	// Get user's latest aura
 	var userAura models.AuraReading
-	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").First(&userAura).Error; err != nil {
-		return nil, errors.New("you need an aura reading first")
+	if err := s.db.Where("user_id = ? AND group_scan_id IS NULL", userID).Order("created_at DESC").First(&userAura).Error; err != nil {
+		return nil, ErrNoAuraReading
 	}
 
 	// Get friend's latest aura
 	var friendAura models.AuraReading
-	if err := s.db.Where("user_id = ?", friendID).Order("created_at DESC").First(&friendAura).Error; err != nil {
-		return nil, errors.New("friend doesn't have an aura reading yet")
+	if err := s.db.Where("user_id = ? AND group_scan_id IS NULL", friendID).Order("created_at DESC").First(&friendAura).Error; err != nil {
+		return nil, ErrFriendNoAuraReading
 	}
 
+	palette := s.colors.Palette()
 	var score int
-	var synergy, tension, advice string
+	var synergy, tension, advice, promptVersion string
 
 	// Try AI-powered analysis if API key is configured
 	if s.cfg.OpenAIAPIKey != "" {
-		aiResult, err := s.calculateCompatibilityAI(userAura, friendAura)
+		prompt := s.prompts.Select(PromptMatch, userID)
+		aiResult, err := s.calculateCompatibilityAI(prompt, palette, userAura, friendAura, locale)
 		if err != nil {
 			log.Printf("OpenAI match API error, falling back to mock: %v", err)
-			score, synergy, tension, advice = s.calculateCompatibilityFallback(userAura.AuraColor, friendAura.AuraColor)
+			score, synergy, tension, advice = s.calculateCompatibilityFallback(palette, userAura, friendAura, locale)
 		} else {
 			score = aiResult.CompatibilityScore
 			synergy = aiResult.Synergy
 			tension = aiResult.Tension
 			advice = aiResult.Advice
+			promptVersion = prompt.ID()
 		}
 	} else {
-		score, synergy, tension, advice = s.calculateCompatibilityFallback(userAura.AuraColor, friendAura.AuraColor)
+		score, synergy, tension, advice = s.calculateCompatibilityFallback(palette, userAura, friendAura, locale)
 	}
 
 	match := &models.AuraMatch{
@@ -312,6 +318,8 @@ func (s *AuraMatchService) Create(userID uuid.UUID, req dto.CreateMatchRequest)
 		Synergy:            synergy,
 		Tension:            tension,
 		Advice:             advice,
+		Language:           locale.Lang(),
+		PromptVersion:      promptVersion,
 	}
 
 	if err := s.db.Create(match).Error; err != nil {
@@ -334,25 +342,13 @@ func (s *AuraMatchService) Create(userID uuid.UUID, req dto.CreateMatchRequest)
 	}, nil
 }
 
-func getSynergyDetail(color1, color2 string) string {
-	details := map[string]string{
-		"red":    "Passion ignites.",
-		"orange": "Creativity sparks.",
-		"yellow": "Ideas flow.",
-		"green":  "Growth flourishes.",
-		"blue":   "Trust deepens.",
-		"indigo": "Intuition guides.",
-		"violet": "Magic happens.",
-		"white":  "Purity shines.",
-		"gold":   "Abundance attracts.",
-		"pink":   "Love blooms.",
-	}
-	return fmt.Sprintf("%s %s", details[color1], details[color2])
-}
-
+// List returns the user's matches, newest first. Matches with anyone on either side of a
+// block are left out.
 func (s *AuraMatchService) List(userID uuid.UUID) ([]dto.AuraMatchResponse, error) {
 	var matches []models.AuraMatch
-	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&matches).Error; err != nil {
+	if err := s.db.Where("user_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM blocks WHERE (blocks.blocker_id = aura_matches.user_id AND blocks.blocked_id = aura_matches.friend_id) OR (blocks.blocker_id = aura_matches.friend_id AND blocks.blocked_id = aura_matches.user_id))").
+		Order("created_at DESC").Find(&matches).Error; err != nil {
 		return nil, err
 	}
 
@@ -382,7 +378,15 @@ func (s *AuraMatchService) List(userID uuid.UUID) ([]dto.AuraMatchResponse, erro
 	return responses, nil
 }
 
+// GetByFriend returns the user's latest match with friendID. A block in either direction
+// hides it as gorm.ErrRecordNotFound.
 func (s *AuraMatchService) GetByFriend(userID, friendID uuid.UUID) (*dto.AuraMatchResponse, error) {
+	if blocked, err := blockedBetween(s.db, userID, friendID); err != nil {
+		return nil, err
+	} else if blocked {
+		return nil, gorm.ErrRecordNotFound
+	}
+
 	var match models.AuraMatch
 	if err := s.db.Where("user_id = ? AND friend_id = ?", userID, friendID).
 		Order("created_at DESC").First(&match).Error; err != nil {
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Narrative sources recorded on each reading.
+const (
+	NarrativeSourceAI     = "ai"
+	NarrativeSourceTraits = "traits"
+)
+
+const (
+	narrativeHistorySize       = 5
+	defaultNarrativeTimeout    = 15 * time.Second
+	narrativeMaxPersonality    = 600
+	narrativeMaxAdvice         = 400
+	narrativeMaxListItem       = 80
+	narrativeMinListItems      = 2
+	narrativeMaxListItems      = 5
+	narrativeHistoryDateLayout = "2006-01-02"
+)
+
+// auraNarrative is the personalised text of a reading.
+type auraNarrative struct {
+	Personality string   `json:"personality"`
+	Strengths   []string `json:"strengths"`
+	Challenges  []string `json:"challenges"`
+	DailyAdvice string   `json:"daily_advice"`
+}
+
+// traitsNarrative is the color catalog's static text for a color blended with its optional
+// secondary accent, used when AI narratives are disabled or the provider's answer is
+// unusable.
+func traitsNarrative(palette *ColorPalette, locale *i18n.Localizer, color string, secondary *string) auraNarrative {
+	t := palette.BlendedTraits(locale, color, secondary)
+	return auraNarrative{
+		Personality: t.Personality,
+		Strengths:   t.Strengths,
+		Challenges:  t.Challenges,
+		DailyAdvice: t.DailyAdvice,
+	}
+}
+
+type narrativeHistoryEntry struct {
+	Date           string  `json:"date"`
+	AuraColor      string  `json:"aura_color"`
+	SecondaryColor *string `json:"secondary_color,omitempty"`
+	EnergyLevel    int     `json:"energy_level"`
+	MoodScore      int     `json:"mood_score"`
+}
+
+// writeNarrative asks the AI providers for a reading narrative grounded in the colour
+// pair, scores and, with withHistory, the user's recent readings. The generated text is
+// saved on the reading itself, so each reading is written once and never regenerated, in
+// the language of the request that created it. It returns the narrative, its source and,
+// for AI narratives, the prompt version that wrote it.
+func (s *AuraService) writeNarrative(userID uuid.UUID, analysis auraAnalysisResult, palette *ColorPalette, locale *i18n.Localizer, withHistory bool) (auraNarrative, string, string) {
+	fallback := traitsNarrative(palette, locale, analysis.AuraColor, analysis.SecondaryColor)
+	if !s.narratives {
+		return fallback, NarrativeSourceTraits, ""
+	}
+
+	var recent []models.AuraReading
+	if withHistory {
+		if err := s.db.Select("aura_color", "secondary_color", "energy_level", "mood_score", "created_at").
+			Where("user_id = ? AND group_scan_id IS NULL", userID).
+			Order("created_at DESC").
+			Limit(narrativeHistorySize).
+			Find(&recent).Error; err != nil {
+			log.Printf("Failed to load reading history for narrative: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(withAICall(context.Background(), AIFeatureNarrative, &userID), s.narrativeTimeout)
+	defer cancel()
+
+	prompt := s.prompts.Select(PromptNarrative, userID)
+	messages, err := auraNarrativeMessages(prompt, analysis, fallback, recent, locale.LanguageName())
+	if err != nil {
+		log.Printf("Narrative prompt failed, using color traits: %v", err)
+		return fallback, NarrativeSourceTraits, ""
+	}
+	var narrative auraNarrative
+	if _, _, err := s.analyzer.complete(ctx, messages, func(content string) error {
+		var err error
+		narrative, err = parseAuraNarrative(content)
+		return err
+	}); err != nil {
+		log.Printf("Narrative generation failed, using color traits: %v", err)
+		return fallback, NarrativeSourceTraits, ""
+	}
+	return narrative, NarrativeSourceAI, prompt.ID()
+}
+
+// auraNarrativeMessages renders the narrative prompt; a nil prompt uses the embedded default.
+func auraNarrativeMessages(prompt *promptVariant, analysis auraAnalysisResult, base auraNarrative, recent []models.AuraReading, language string) ([]auraChatMessage, error) {
+	history := make([]narrativeHistoryEntry, 0, len(recent))
+	for _, r := range recent {
+		history = append(history, narrativeHistoryEntry{
+			Date:           r.CreatedAt.Format(narrativeHistoryDateLayout),
+			AuraColor:      r.AuraColor,
+			SecondaryColor: r.SecondaryColor,
+			EnergyLevel:    r.EnergyLevel,
+			MoodScore:      r.MoodScore,
+		})
+	}
+
+	reading, _ := json.Marshal(analysis)
+	traits, _ := json.Marshal(base)
+	past, _ := json.Marshal(history)
+
+	prompt = resolvePrompt(prompt, PromptNarrative)
+	data := narrativePromptData{
+		Reading:        string(reading),
+		ColorMeaning:   string(traits),
+		RecentReadings: string(past),
+		Language:       language,
+	}
+	system, err := prompt.render("system", data)
+	if err != nil {
+		return nil, err
+	}
+	user, err := prompt.render("user", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []auraChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	}, nil
+}
+
+// parseAuraNarrative accepts a provider answer only when every field is present and
+// within the sizes the app can display.
+func parseAuraNarrative(content string) (auraNarrative, error) {
+	var n auraNarrative
+	raw := strings.TrimSpace(content)
+	if err := json.Unmarshal([]byte(raw), &n); err != nil {
+		start := strings.Index(raw, "{")
+		end := strings.LastIndex(raw, "}")
+		if start < 0 || end <= start {
+			return auraNarrative{}, errors.New("narrative is not JSON")
+		}
+		if err := json.Unmarshal([]byte(raw[start:end+1]), &n); err != nil {
+			return auraNarrative{}, fmt.Errorf("narrative is not JSON: %w", err)
+		}
+	}
+
+	n.Personality = strings.TrimSpace(n.Personality)
+	n.DailyAdvice = strings.TrimSpace(n.DailyAdvice)
+	if n.Personality == "" || utf8.RuneCountInString(n.Personality) > narrativeMaxPersonality {
+		return auraNarrative{}, errors.New("narrative personality missing or too long")
+	}
+	if n.DailyAdvice == "" || utf8.RuneCountInString(n.DailyAdvice) > narrativeMaxAdvice {
+		return auraNarrative{}, errors.New("narrative daily_advice missing or too long")
+	}
+
+	var err error
+	if n.Strengths, err = cleanNarrativeList(n.Strengths); err != nil {
+		return auraNarrative{}, fmt.Errorf("narrative strengths: %w", err)
+	}
+	if n.Challenges, err = cleanNarrativeList(n.Challenges); err != nil {
+		return auraNarrative{}, fmt.Errorf("narrative challenges: %w", err)
+	}
+	return n, nil
+}
+
+func cleanNarrativeList(items []string) ([]string, error) {
+	cleaned := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if utf8.RuneCountInString(item) > narrativeMaxListItem {
+			return nil, errors.New("item too long")
+		}
+		cleaned = append(cleaned, item)
+	}
+	if len(cleaned) < narrativeMinListItems {
+		return nil, errors.New("too few items")
+	}
+	if len(cleaned) > narrativeMaxListItems {
+		cleaned = cleaned[:narrativeMaxListItems]
+	}
+	return cleaned, nil
+}
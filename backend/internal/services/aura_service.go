@@ -1,38 +1,47 @@
 package services
 
 import (
-	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
-	"net/http"
+	"log"
+	"math/rand"
 	"strings"
 	"time"
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrImageRequired is returned for a JSON scan request with neither image_data nor image_url.
+var ErrImageRequired = errors.New("image_url or image_data is required")
+
 type AuraService struct {
 	db       *gorm.DB
 	analyzer *auraAIAnalyzer
-}
-
-type auraAIProvider struct {
-	name   string
-	apiURL string
-	apiKey string
-	model  string
-}
-
-type auraAIAnalyzer struct {
-	providers []auraAIProvider
-	client    *http.Client
+	media    *MediaService
+	quota    *QuotaService
+	colors   *ColorCatalogService
+	// qualityGate rejects unusable photos before they reach the analyzer.
+	qualityGate bool
+	// narratives enables AI-written reading text; the catalog's color traits are the fallback.
+	narratives       bool
+	narrativeTimeout time.Duration
+	// retention is how long deleted readings stay restorable.
+	retention time.Duration
+	// duplicates decides what happens to re-uploads of a recent photo.
+	duplicates duplicatePolicy
+	cache      *AnalysisCache
+	prompts    *PromptService
+	// compareLimiter counts AI comparison explanations per user; compareCache keeps
+	// them per pair of readings and language.
+	compareLimiter *windowLimiter
+	compareCache   *lruCache[string]
 }
 
 type auraAnalysisResult struct {
@@ -42,178 +51,271 @@ type auraAnalysisResult struct {
 	MoodScore      int     `json:"mood_score"`
 }
 
-type auraChatCompletionRequest struct {
-	Model          string            `json:"model"`
-	Messages       []auraChatMessage `json:"messages"`
-	Temperature    float64           `json:"temperature,omitempty"`
-	ResponseFormat map[string]string `json:"response_format,omitempty"`
+func NewAuraService(db *gorm.DB, cfg *config.Config, media *MediaService, quota *QuotaService, colors *ColorCatalogService, cache *AnalysisCache, usage *AIUsageService, prompts *PromptService) *AuraService {
+	narrativeTimeout := cfg.AuraNarrativeTimeout
+	if narrativeTimeout <= 0 {
+		narrativeTimeout = defaultNarrativeTimeout
+	}
+	retention := cfg.ReadingRetention
+	if retention <= 0 {
+		retention = defaultReadingRetention
+	}
+	compareLimit := cfg.CompareAIHourlyLimit
+	if compareLimit <= 0 {
+		compareLimit = defaultCompareAIHourlyLimit
+	}
+	return &AuraService{
+		db:          db,
+		analyzer:    newAuraAIAnalyzer(cfg, usage),
+		media:       media,
+		quota:       quota,
+		colors:      colors,
+		qualityGate: cfg.AuraQualityGate,
+
+		narratives:       cfg.AuraNarratives,
+		narrativeTimeout: narrativeTimeout,
+		retention:        retention,
+		duplicates:       newDuplicatePolicy(cfg),
+		cache:            cache,
+		prompts:          prompts,
+		compareLimiter:   newWindowLimiter(compareLimit, time.Hour),
+		compareCache:     newLRUCache[string](compareAICacheSize),
+	}
 }
 
-type auraChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+// Create scans the photo in a JSON request. The reading is written in the localizer's
+// language.
+func (s *AuraService) Create(userID uuid.UUID, req dto.CreateAuraRequest, locale *i18n.Localizer) (*models.AuraReading, error) {
+	imageURL, img, err := s.prepareScan(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanWithQuota(userID, imageURL, img, locale)
 }
 
-type auraChatCompletionResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
+// CreateFromImage analyzes raw JPEG/PNG bytes from a multipart upload.
+func (s *AuraService) CreateFromImage(userID uuid.UUID, raw []byte, locale *i18n.Localizer) (*models.AuraReading, error) {
+	img, err := s.loadScanImage(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanWithQuota(userID, "base64_upload", img, locale)
 }
 
-func NewAuraService(db *gorm.DB, cfg *config.Config) *AuraService {
-	return &AuraService{
-		db:       db,
-		analyzer: newAuraAIAnalyzer(cfg),
+// prepareScan validates a JSON scan request and decodes its inline photo, if any.
+func (s *AuraService) prepareScan(req dto.CreateAuraRequest) (string, *auraImage, error) {
+	imageURL := strings.TrimSpace(req.ImageURL)
+
+	if strings.TrimSpace(req.ImageData) != "" {
+		raw, err := decodeImageData(req.ImageData)
+		if err != nil {
+			return "", nil, err
+		}
+		img, err := s.loadScanImage(raw)
+		if err != nil {
+			return "", nil, err
+		}
+		if imageURL == "" {
+			// Keep a marker when image data is sent inline.
+			imageURL = "base64_upload"
+		}
+		return imageURL, img, nil
 	}
-}
 
-func newAuraAIAnalyzer(cfg *config.Config) *auraAIAnalyzer {
-	timeout := cfg.AuraAITimeout
-	if timeout <= 0 {
-		timeout = 20 * time.Second
+	if imageURL == "" {
+		return "", nil, ErrImageRequired
 	}
+	return imageURL, nil, nil
+}
 
-	providers := make([]auraAIProvider, 0, 2)
+// scanWithQuota reserves a scan from the user's daily allowance, creates the reading,
+// and refunds the reservation if that fails. Photos are validated before reserving so
+// rejected uploads never touch the quota, and a re-upload of a recent photo may return
+// its earlier reading without reserving at all.
+func (s *AuraService) scanWithQuota(userID uuid.UUID, imageURL string, img *auraImage, locale *i18n.Localizer) (*models.AuraReading, error) {
+	if reading := s.reuseDuplicate(userID, img); reading != nil {
+		return reading, nil
+	}
 
-	if strings.TrimSpace(cfg.GLMAPIKey) != "" {
-		providers = append(providers, auraAIProvider{
-			name:   "glm",
-			apiURL: strings.TrimSpace(cfg.GLMAPIURL),
-			apiKey: strings.TrimSpace(cfg.GLMAPIKey),
-			model:  strings.TrimSpace(cfg.GLMModel),
-		})
+	reservation, err := s.quota.Reserve(userID)
+	if err != nil {
+		return nil, err
 	}
-	if strings.TrimSpace(cfg.DeepSeekAPIKey) != "" {
-		providers = append(providers, auraAIProvider{
-			name:   "deepseek",
-			apiURL: strings.TrimSpace(cfg.DeepSeekAPIURL),
-			apiKey: strings.TrimSpace(cfg.DeepSeekAPIKey),
-			model:  strings.TrimSpace(cfg.DeepSeekModel),
-		})
+
+	reading, err := s.createReading(userID, imageURL, img, scanOptions{locale: locale})
+	if err != nil {
+		s.quota.refund(&reservation.ID)
+		return nil, err
 	}
+	s.quota.commit(&reservation.ID, reading.ID)
+	return reading, nil
+}
 
-	return &auraAIAnalyzer{
-		providers: providers,
-		client:    &http.Client{Timeout: timeout},
+// loadScanImage decodes an uploaded photo and, when the gate is enabled, returns an
+// *ImageQualityError for photos the mobile gate would also have rejected. Nothing is
+// stored for a rejected photo, so it never counts against the daily limit.
+func (s *AuraService) loadScanImage(raw []byte) (*auraImage, error) {
+	img, err := loadAuraImage(raw)
+	if err != nil {
+		return nil, err
+	}
+	if s.qualityGate {
+		if result := evaluateImageQuality(img); !result.OK {
+			return nil, &ImageQualityError{Result: result}
+		}
 	}
+	return img, nil
 }
 
-var colorTraits = map[string]struct {
-	personality string
-	strengths   []string
-	challenges  []string
-	dailyAdvice string
-}{
-	"red": {
-		personality: "Passionate, energetic, and action-oriented.",
-		strengths:   []string{"Courage", "Leadership", "Determination"},
-		challenges:  []string{"Impulsiveness", "Patience", "Anger Management"},
-		dailyAdvice: "Channel your energy into a physical activity today. Avoid hasty decisions.",
-	},
-	"orange": {
-		personality: "Creative, social, and adventurous.",
-		strengths:   []string{"Creativity", "Optimism", "Social Skills"},
-		challenges:  []string{"Scattered Focus", "Restlessness", "Overcommitment"},
-		dailyAdvice: "Start a new creative project. Connect with an old friend.",
-	},
-	"yellow": {
-		personality: "Optimistic, intellectual, and cheerful.",
-		strengths:   []string{"Analytical Thinking", "Positivity", "Communication"},
-		challenges:  []string{"Critical Nature", "Overthinking", "Perfectionism"},
-		dailyAdvice: "Share your ideas with others. Take time to relax your mind.",
-	},
-	"green": {
-		personality: "Balanced, growth-oriented, and nurturing.",
-		strengths:   []string{"Compassion", "Reliability", "Growth Mindset"},
-		challenges:  []string{"Jealousy", "Possessiveness", "Insecurity"},
-		dailyAdvice: "Spend time in nature. Nurture a relationship or a plant.",
-	},
-	"blue": {
-		personality: "Calm, intuitive, and trustworthy.",
-		strengths:   []string{"Communication", "Intuition", "Loyalty"},
-		challenges:  []string{"Fear of Expression", "Melancholy", "Stubbornness"},
-		dailyAdvice: "Speak your truth today. Trust your gut feelings.",
-	},
-	"indigo": {
-		personality: "Intuitive, wise, and deeply spiritual.",
-		strengths:   []string{"Vision", "Wisdom", "Integrity"},
-		challenges:  []string{"Isolation", "Judgment", "Rigidity"},
-		dailyAdvice: "Meditate or reflect on your long-term goals. Practice forgiveness.",
-	},
-	"violet": {
-		personality: "Visionary, artistic, and magical.",
-		strengths:   []string{"Imagination", "Humanitarianism", "Leadership"},
-		challenges:  []string{"Unrealistic Expectations", "Arrogance", "Detachment"},
-		dailyAdvice: "Engage in art or music. Visualize your ideal future.",
-	},
-	"white": {
-		personality: "Pure, balanced, and spiritually connected.",
-		strengths:   []string{"Purity", "Healing", "High Vibration"},
-		challenges:  []string{"Vulnerability", "Naivety", "Disconnection from Reality"},
-		dailyAdvice: "Focus on cleansing your space, physical or mental. Protect your energy.",
-	},
-	"gold": {
-		personality: "Confident, abundant, and empowered.",
-		strengths:   []string{"Confidence", "Generosity", "Willpower"},
-		challenges:  []string{"Ego", "Greed", "Overbearing nature"},
-		dailyAdvice: "Share your abundance with others. Practice humility.",
-	},
-	"pink": {
-		personality: "Loving, gentle, and compassionate.",
-		strengths:   []string{"Love", "Empathy", "Nurturing"},
-		challenges:  []string{"Neediness", "Martyrdom", "Lack of Boundaries"},
-		dailyAdvice: "Practice self-love. Set healthy boundaries with kindness.",
-	},
+// scanOptions lets background jobs drive createReading. A fixed readingID makes a retried
+// job find the reading it already created instead of producing a duplicate.
+type scanOptions struct {
+	readingID uuid.UUID
+	locale    *i18n.Localizer
+	// progress is told each stage as it starts. An error stops the scan before anything
+	// else is stored, e.g. when the job it runs for was reclaimed or deleted.
+	progress func(stage string, percent int) error
+	// groupScanID marks a reading cut from a group photo; see createGroupReadings.
+	groupScanID *uuid.UUID
 }
 
-var auraColors = []string{"red", "orange", "yellow", "green", "blue", "indigo", "violet", "white", "gold", "pink"}
-var secondaryColors = []string{"silver", "gold", "white", "black", "grey"}
+func (o scanOptions) report(stage string, percent int) error {
+	if o.progress != nil {
+		return o.progress(stage, percent)
+	}
+	return nil
+}
 
-func (s *AuraService) Create(userID uuid.UUID, req dto.CreateAuraRequest) (*models.AuraReading, error) {
-	imageURL := strings.TrimSpace(req.ImageURL)
-	if imageURL == "" && strings.TrimSpace(req.ImageData) != "" {
-		// Keep a deterministic marker when image data is sent inline.
-		imageURL = "base64_upload"
+func (s *AuraService) createReading(userID uuid.UUID, imageURL string, img *auraImage, opts scanOptions) (*models.AuraReading, error) {
+	readingID := opts.readingID
+	if readingID == uuid.Nil {
+		readingID = uuid.New()
 	}
-	if imageURL == "" {
-		return nil, errors.New("image_url or image_data is required")
+
+	// The palette is read once so the whole reading uses one catalog version.
+	palette := s.colors.Palette()
+
+	// Pixel statistics give the baseline when we have the photo; URL-only scans fall back
+	// to a stable hash so the same link always yields the same reading.
+	var analysis auraAnalysisResult
+	if img != nil {
+		analysis = imageAuraResult(img.features)
+	} else {
+		analysis = deterministicAuraResult(palette, userID, imageURL)
 	}
 
-	analysis := deterministicAuraResult(userID, imageURL)
-	if aiAnalysis, err := s.analyzer.analyze(imageURL, analysis); err == nil {
+	if err := opts.report(ScanStageAnalyzing, 30); err != nil {
+		return nil, err
+	}
+	prompt := s.prompts.Select(PromptAnalysis, userID)
+	input := auraAnalysisInput{imageURL: imageURL, image: img, palette: palette, prompt: prompt}
+	// Only decoded photos are cached: a URL says nothing about the bytes behind it.
+	cacheKey := ""
+	if img != nil && s.cache.enabled() {
+		cacheKey = analysisCacheKey(img, palette, prompt.ID(), s.analyzer.signature)
+	}
+	// Prompt versions are stamped only when an AI answer was used, so comparing variants
+	// leaves out readings that fell back to pixel statistics or color traits.
+	promptVersions := map[string]string{}
+	if cached, ok := s.cache.get(cacheKey); ok {
+		analysis = cached
+		promptVersions[PromptAnalysis] = prompt.ID()
+	} else if aiAnalysis, err := s.analyzer.analyze(withAICall(context.Background(), AIFeatureAnalysis, &userID), input, analysis); err == nil {
 		analysis = aiAnalysis
+		promptVersions[PromptAnalysis] = prompt.ID()
+		s.cache.set(cacheKey, analysis)
+	}
+
+	if palette.normalizePrimary(analysis.AuraColor) == "" {
+		analysis.AuraColor = palette.FallbackColor()
+	}
+	if analysis.SecondaryColor != nil && (!palette.IsSecondary(*analysis.SecondaryColor) || *analysis.SecondaryColor == analysis.AuraColor) {
+		analysis.SecondaryColor = nil
+	}
+	// Rare streak colors are not something a photo shows, so they are rolled for the user
+	// after analysis rather than asked of the providers.
+	if opts.groupScanID == nil {
+		if rare := palette.rollRareColor(s.unlockedColors(userID, palette), rand.Float64()); rare != "" {
+			analysis.AuraColor = rare
+			if analysis.SecondaryColor != nil && *analysis.SecondaryColor == rare {
+				analysis.SecondaryColor = nil
+			}
+		}
 	}
 
-	traits, ok := colorTraits[analysis.AuraColor]
-	if !ok {
-		traits = colorTraits["violet"]
-		analysis.AuraColor = "violet"
+	if err := opts.report(ScanStageWriting, 50); err != nil {
+		return nil, err
+	}
+	narrative, narrativeSource, narrativePrompt := s.writeNarrative(userID, analysis, palette, opts.locale, opts.groupScanID == nil)
+	if narrativePrompt != "" {
+		promptVersions[PromptNarrative] = narrativePrompt
 	}
 
 	reading := &models.AuraReading{
-		UserID:         userID,
-		ImageURL:       imageURL,
-		AuraColor:      analysis.AuraColor,
-		SecondaryColor: analysis.SecondaryColor,
-		EnergyLevel:    clamp(analysis.EnergyLevel, 1, 100),
-		MoodScore:      clamp(analysis.MoodScore, 1, 10),
-		Personality:    traits.personality,
-		Strengths:      traits.strengths,
-		Challenges:     traits.challenges,
-		DailyAdvice:    traits.dailyAdvice,
-		AnalyzedAt:     time.Now(),
+		ID:              readingID,
+		UserID:          userID,
+		ImageURL:        imageURL,
+		AuraColor:       analysis.AuraColor,
+		SecondaryColor:  analysis.SecondaryColor,
+		EnergyLevel:     clamp(analysis.EnergyLevel, 1, 100),
+		MoodScore:       clamp(analysis.MoodScore, 1, 10),
+		Personality:     narrative.Personality,
+		Strengths:       narrative.Strengths,
+		Challenges:      narrative.Challenges,
+		DailyAdvice:     narrative.DailyAdvice,
+		NarrativeSource: narrativeSource,
+		Language:        opts.locale.Lang(),
+		AnalyzedAt:      time.Now(),
+		PromptVersions:  promptVersions,
+		GroupScanID:     opts.groupScanID,
+	}
+	// People in a group photo are not the user, so their crops never count as re-uploads.
+	if img != nil && opts.groupScanID == nil {
+		hash := int64(img.hash)
+		reading.ImageHash = &hash
+		reading.DuplicateOf = s.flagDuplicate(userID, readingID, img)
+	}
+
+	if img != nil {
+		if err := opts.report(ScanStageStoring, 70); err != nil {
+			return nil, err
+		}
+		// A storage outage should not cost the user their scan; the reading is kept without a photo.
+		imageKey, thumbKey, err := s.media.StoreReadingImage(userID, reading.ID, img)
+		if err != nil {
+			log.Printf("Failed to store scan image for reading %s: %v", reading.ID, err)
+		} else {
+			reading.ImageKey = imageKey
+			reading.ThumbnailKey = thumbKey
+		}
 	}
 
+	if err := opts.report(ScanStageSaving, 90); err != nil {
+		s.media.DeleteReadingImages(*reading)
+		return nil, err
+	}
 	if err := s.db.Create(reading).Error; err != nil {
+		s.media.DeleteReadingImages(*reading)
 		return nil, err
 	}
 
+	s.media.Decorate(reading)
 	return reading, nil
 }
 
-const auraDailyFreeLimit = 2
+// unlockedColors lists the rare colors the user has unlocked with streaks. A lookup failure
+// only costs the user a chance at a rare color, so it is logged rather than returned.
+func (s *AuraService) unlockedColors(userID uuid.UUID, palette *ColorPalette) []string {
+	if len(palette.RareColors()) == 0 {
+		return nil
+	}
+	var streak models.AuraStreak
+	if err := s.db.Select("unlocked_colors").Where("user_id = ?", userID).Limit(1).Find(&streak).Error; err != nil {
+		log.Printf("Failed to load unlocked colors for user %s: %v", userID, err)
+		return nil
+	}
+	return streak.UnlockedColors
+}
 
 func (s *AuraService) IsSubscribed(userID uuid.UUID) bool {
 	var sub models.Subscription
@@ -224,41 +326,24 @@ func (s *AuraService) IsSubscribed(userID uuid.UUID) bool {
 	return err == nil
 }
 
-func (s *AuraService) CanScan(userID uuid.UUID, isSubscribed bool) (bool, int, error) {
-	if isSubscribed {
-		return true, -1, nil
-	}
-
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	var scansToday int64
-	if err := s.db.Model(&models.AuraReading{}).
-		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, startOfDay, endOfDay).
-		Count(&scansToday).Error; err != nil {
-		return false, 0, err
-	}
-
-	remaining := auraDailyFreeLimit - int(scansToday)
-	if remaining < 0 {
-		remaining = 0
-	}
-
-	return scansToday < auraDailyFreeLimit, remaining, nil
+// ScanQuota reports the user's daily scan allowance in their own timezone.
+func (s *AuraService) ScanQuota(userID uuid.UUID) (*ScanQuota, error) {
+	return s.quota.Status(userID)
 }
 
-func deterministicAuraResult(userID uuid.UUID, imageURL string) auraAnalysisResult {
+func deterministicAuraResult(palette *ColorPalette, userID uuid.UUID, imageURL string) auraAnalysisResult {
 	seedInput := strings.ToLower(strings.TrimSpace(imageURL)) + ":" + userID.String()
 	hash := sha256.Sum256([]byte(seedInput))
 
-	color := auraColors[int(hash[0])%len(auraColors)]
+	primaries := palette.PrimaryColors()
+	secondaries := palette.SecondaryColors()
+	color := primaries[int(hash[0])%len(primaries)]
 	energy := 45 + int(hash[1])%51 // 45..95
 	mood := 5 + int(hash[2])%6     // 5..10
 
 	var secondary *string
-	if int(hash[3])%4 == 0 { // 25% chance
-		candidate := secondaryColors[int(hash[4])%len(secondaryColors)]
+	if int(hash[3])%4 == 0 && len(secondaries) > 0 { // 25% chance
+		candidate := secondaries[int(hash[4])%len(secondaries)]
 		if candidate != color {
 			secondary = &candidate
 		}
@@ -272,94 +357,12 @@ func deterministicAuraResult(userID uuid.UUID, imageURL string) auraAnalysisResu
 	}
 }
 
-func (a *auraAIAnalyzer) analyze(imageURL string, base auraAnalysisResult) (auraAnalysisResult, error) {
-	if a == nil || len(a.providers) == 0 {
-		return base, errors.New("aura ai analyzer disabled")
-	}
-
-	var lastErr error
-	for _, provider := range a.providers {
-		result, err := a.analyzeWithProvider(provider, imageURL, base)
-		if err == nil {
-			return result, nil
-		}
-		lastErr = fmt.Errorf("%s provider failed: %w", provider.name, err)
-	}
-
-	if lastErr != nil {
-		return base, lastErr
-	}
-	return base, errors.New("no aura ai provider available")
-}
-
-func (a *auraAIAnalyzer) analyzeWithProvider(provider auraAIProvider, imageURL string, base auraAnalysisResult) (auraAnalysisResult, error) {
-	prompt := fmt.Sprintf(
-		"Analyze this aura image URL and return only JSON. image_url=%q allowed_colors=%v fallback=%+v. Output keys: aura_color (string), secondary_color (string or null), energy_level (1-100), mood_score (1-10). Keep results realistic.",
-		imageURL,
-		auraColors,
-		base,
-	)
-
-	reqBody := auraChatCompletionRequest{
-		Model: provider.model,
-		Messages: []auraChatMessage{
-			{Role: "system", Content: "You are an aura analysis engine. Return valid JSON only."},
-			{Role: "user", Content: prompt},
-		},
-		Temperature:    0.2,
-		ResponseFormat: map[string]string{"type": "json_object"},
-	}
-
-	payload, err := json.Marshal(reqBody)
-	if err != nil {
-		return base, err
-	}
-
-	req, err := http.NewRequest(http.MethodPost, provider.apiURL, bytes.NewReader(payload))
-	if err != nil {
-		return base, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+provider.apiKey)
-
-	resp, err := a.client.Do(req)
-	if err != nil {
-		return base, err
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return base, err
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return base, fmt.Errorf("aura ai request failed: status=%d", resp.StatusCode)
-	}
-
-	var completion auraChatCompletionResponse
-	if err := json.Unmarshal(respBody, &completion); err != nil {
-		return base, err
-	}
-	if len(completion.Choices) == 0 {
-		return base, errors.New("aura ai returned no choices")
-	}
-
-	content := strings.TrimSpace(completion.Choices[0].Message.Content)
-	parsed, err := parseAuraAIContent(content)
-	if err != nil {
-		return base, err
-	}
-
-	return mergeAuraAnalysis(base, parsed), nil
-}
-
-func parseAuraAIContent(content string) (auraAnalysisResult, error) {
+func parseAuraAIContent(content string, palette *ColorPalette) (auraAnalysisResult, error) {
 	if strings.TrimSpace(content) == "" {
 		return auraAnalysisResult{}, errors.New("empty aura ai content")
 	}
 
-	parsed, ok := parseAuraJSON(content)
+	parsed, ok := parseAuraJSON(content, palette)
 	if ok {
 		return parsed, nil
 	}
@@ -367,7 +370,7 @@ func parseAuraAIContent(content string) (auraAnalysisResult, error) {
 	start := strings.Index(content, "{")
 	end := strings.LastIndex(content, "}")
 	if start >= 0 && end > start {
-		parsed, ok = parseAuraJSON(content[start : end+1])
+		parsed, ok = parseAuraJSON(content[start:end+1], palette)
 		if ok {
 			return parsed, nil
 		}
@@ -376,13 +379,13 @@ func parseAuraAIContent(content string) (auraAnalysisResult, error) {
 	return auraAnalysisResult{}, errors.New("could not parse aura ai response")
 }
 
-func parseAuraJSON(raw string) (auraAnalysisResult, bool) {
+func parseAuraJSON(raw string, palette *ColorPalette) (auraAnalysisResult, bool) {
 	var parsed auraAnalysisResult
 	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
 		return auraAnalysisResult{}, false
 	}
 
-	parsed.AuraColor = normalizeAuraColor(parsed.AuraColor)
+	parsed.AuraColor = palette.normalizePrimary(parsed.AuraColor)
 	if parsed.AuraColor == "" {
 		return auraAnalysisResult{}, false
 	}
@@ -401,11 +404,11 @@ func parseAuraJSON(raw string) (auraAnalysisResult, bool) {
 	return parsed, true
 }
 
-func mergeAuraAnalysis(base, incoming auraAnalysisResult) auraAnalysisResult {
+func mergeAuraAnalysis(palette *ColorPalette, base, incoming auraAnalysisResult) auraAnalysisResult {
 	result := base
 
 	if incoming.AuraColor != "" {
-		result.AuraColor = normalizeAuraColor(incoming.AuraColor)
+		result.AuraColor = palette.normalizePrimary(incoming.AuraColor)
 	}
 	if incoming.SecondaryColor != nil {
 		result.SecondaryColor = incoming.SecondaryColor
@@ -418,22 +421,12 @@ func mergeAuraAnalysis(base, incoming auraAnalysisResult) auraAnalysisResult {
 	}
 
 	if result.AuraColor == "" {
-		result.AuraColor = "violet"
+		result.AuraColor = palette.FallbackColor()
 	}
 
 	return result
 }
 
-func normalizeAuraColor(color string) string {
-	normalized := strings.ToLower(strings.TrimSpace(color))
-	for _, c := range auraColors {
-		if normalized == c {
-			return normalized
-		}
-	}
-	return ""
-}
-
 func clamp(v, minV, maxV int) int {
 	if v < minV {
 		return minV
@@ -450,35 +443,13 @@ func (s *AuraService) GetByID(userID, id uuid.UUID) (*models.AuraReading, error)
 	if err != nil {
 		return nil, err
 	}
+	s.media.Decorate(&reading)
 	return &reading, nil
 }
 
-func (s *AuraService) List(userID uuid.UUID, page, pageSize int) ([]models.AuraReading, int64, error) {
-	var readings []models.AuraReading
-	var total int64
-
-	offset := (page - 1) * pageSize
-
-	if err := s.db.Model(&models.AuraReading{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	err := s.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(pageSize).
-		Offset(offset).
-		Find(&readings).Error
-
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return readings, total, nil
-}
-
 func (s *AuraService) GetLatest(userID uuid.UUID) (*models.AuraReading, error) {
 	var reading models.AuraReading
-	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").First(&reading).Error
+	err := s.db.Where("user_id = ? AND group_scan_id IS NULL", userID).Order("created_at DESC").First(&reading).Error
 	if err != nil {
 		return nil, err
 	}
@@ -491,7 +462,7 @@ func (s *AuraService) GetToday(userID uuid.UUID) (*models.AuraReading, error) {
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	err := s.db.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, startOfDay, endOfDay).
+	err := s.db.Where("user_id = ? AND group_scan_id IS NULL AND created_at >= ? AND created_at < ?", userID, startOfDay, endOfDay).
 		Order("created_at DESC").
 		First(&reading).Error
 
@@ -501,46 +472,51 @@ func (s *AuraService) GetToday(userID uuid.UUID) (*models.AuraReading, error) {
 	return &reading, nil
 }
 
-func (s *AuraService) Delete(userID, id uuid.UUID) error {
-	result := s.db.Where("user_id = ? AND id = ?", userID, id).Delete(&models.AuraReading{})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return errors.New("record not found")
-	}
-	return nil
+// ProviderHealth reports per-provider circuit breaker state for admins.
+func (s *AuraService) ProviderHealth() []dto.AIProviderHealth {
+	return s.analyzer.healthReport()
 }
 
+// AnalysisCacheStats reports analysis cache hits and misses for admins.
+func (s *AuraService) AnalysisCacheStats() (dto.AnalysisCacheStats, error) {
+	return s.cache.Stats()
+}
+
+// GetStats aggregates the user's readings in the database rather than loading them.
 func (s *AuraService) GetStats(userID uuid.UUID) (*dto.AuraStatsResponse, error) {
-	var readings []models.AuraReading
-	if err := s.db.Where("user_id = ?", userID).Find(&readings).Error; err != nil {
+	var totals struct {
+		Total     int64
+		AvgEnergy float64
+		AvgMood   float64
+	}
+	if err := s.db.Model(&models.AuraReading{}).
+		Select("COUNT(*) AS total, COALESCE(AVG(energy_level), 0) AS avg_energy, COALESCE(AVG(mood_score), 0) AS avg_mood").
+		Where("user_id = ? AND group_scan_id IS NULL", userID).
+		Scan(&totals).Error; err != nil {
 		return nil, err
 	}
 
-	if len(readings) == 0 {
-		return &dto.AuraStatsResponse{
-			ColorDistribution: make(map[string]int),
-			TotalReadings:     0,
-			AverageEnergy:     0,
-			AverageMood:       0,
-		}, nil
+	var colors []struct {
+		AuraColor string
+		Count     int
+	}
+	if err := s.db.Model(&models.AuraReading{}).
+		Select("aura_color, COUNT(*) AS count").
+		Where("user_id = ? AND group_scan_id IS NULL", userID).
+		Group("aura_color").
+		Scan(&colors).Error; err != nil {
+		return nil, err
 	}
 
-	colorDist := make(map[string]int)
-	totalEnergy := 0
-	totalMood := 0
-
-	for _, r := range readings {
-		colorDist[r.AuraColor]++
-		totalEnergy += r.EnergyLevel
-		totalMood += r.MoodScore
+	colorDist := make(map[string]int, len(colors))
+	for _, c := range colors {
+		colorDist[c.AuraColor] = c.Count
 	}
 
 	return &dto.AuraStatsResponse{
 		ColorDistribution: colorDist,
-		TotalReadings:     int64(len(readings)),
-		AverageEnergy:     float64(totalEnergy) / float64(len(readings)),
-		AverageMood:       float64(totalMood) / float64(len(readings)),
+		TotalReadings:     totals.Total,
+		AverageEnergy:     totals.AvgEnergy,
+		AverageMood:       totals.AvgMood,
 	}, nil
 }
@@ -1,9 +1,18 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
 	"github.com/google/uuid"
 )
 
@@ -11,13 +20,13 @@ func TestDeterministicAuraResultStable(t *testing.T) {
 	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
 	imageURL := "https://cdn.example.com/user/aura-photo-1.jpg"
 
-	first := deterministicAuraResult(userID, imageURL)
-	second := deterministicAuraResult(userID, imageURL)
+	first := deterministicAuraResult(builtinPalette(), userID, imageURL)
+	second := deterministicAuraResult(builtinPalette(), userID, imageURL)
 
 	if first != second {
 		t.Fatalf("deterministic result changed between runs: %#v vs %#v", first, second)
 	}
-	if normalizeAuraColor(first.AuraColor) == "" {
+	if !builtinPalette().IsPrimary(first.AuraColor) {
 		t.Fatalf("invalid aura color: %q", first.AuraColor)
 	}
 	if first.EnergyLevel < 1 || first.EnergyLevel > 100 {
@@ -30,7 +39,7 @@ func TestDeterministicAuraResultStable(t *testing.T) {
 
 func TestParseAuraAIContentJSON(t *testing.T) {
 	content := `{"aura_color":"Blue","secondary_color":"gold","energy_level":88,"mood_score":9}`
-	parsed, err := parseAuraAIContent(content)
+	parsed, err := parseAuraAIContent(content, builtinPalette())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -53,7 +62,7 @@ func TestMergeAuraAnalysisFallbackBehavior(t *testing.T) {
 	base := auraAnalysisResult{AuraColor: "red", EnergyLevel: 50, MoodScore: 7}
 	incoming := auraAnalysisResult{AuraColor: "", EnergyLevel: 120, MoodScore: 0}
 
-	merged := mergeAuraAnalysis(base, incoming)
+	merged := mergeAuraAnalysis(builtinPalette(), base, incoming)
 
 	if merged.AuraColor != "red" {
 		t.Fatalf("expected base color red, got %s", merged.AuraColor)
@@ -76,7 +85,7 @@ func TestAuraProviderPriorityGLMFirst(t *testing.T) {
 		DeepSeekModel:  "deepseek-chat",
 	}
 
-	analyzer := newAuraAIAnalyzer(cfg)
+	analyzer := newAuraAIAnalyzer(cfg, nil)
 	if len(analyzer.providers) != 2 {
 		t.Fatalf("expected 2 providers, got %d", len(analyzer.providers))
 	}
@@ -87,3 +96,401 @@ func TestAuraProviderPriorityGLMFirst(t *testing.T) {
 		t.Fatalf("expected deepseek second, got %s", analyzer.providers[1].name)
 	}
 }
+
+func TestVisionProviderFallsBackToTextOnImageRejection(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content json.RawMessage `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		models = append(models, req.Model)
+
+		if req.Model == "vision" {
+			if !strings.Contains(string(req.Messages[1].Content), "data:image/") {
+				t.Errorf("vision request missing data URL: %s", req.Messages[1].Content)
+			}
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"aura_color\":\"green\",\"energy_level\":70,\"mood_score\":8}"}}]}`))
+	}))
+	defer server.Close()
+
+	img, err := loadAuraImage(encodePNG(t, solidImage(color.RGBA{200, 30, 30, 255}, 128, 96)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	analyzer := newAuraAIAnalyzer(&config.Config{
+		AuraAIProviders: []config.AuraProviderConfig{{
+			Name: "test", URL: server.URL, Model: "text", VisionModel: "vision", Key: "k",
+			MaxImageBytes: 64 * 1024, MaxImageDim: 64,
+		}},
+	}, nil)
+
+	base := imageAuraResult(img.features)
+	result, err := analyzer.analyze(context.Background(), auraAnalysisInput{imageURL: "base64_upload", image: img}, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AuraColor != "green" {
+		t.Fatalf("expected text fallback result green, got %s", result.AuraColor)
+	}
+	if len(models) != 2 || models[0] != "vision" || models[1] != "text" {
+		t.Fatalf("unexpected request sequence: %v", models)
+	}
+}
+
+func TestDataURLDownscalesToProviderLimit(t *testing.T) {
+	img, err := loadAuraImage(encodePNG(t, solidImage(color.RGBA{40, 80, 200, 255}, 400, 200)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	url, err := img.dataURL(100, 8*1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(url, "data:image/jpeg;base64,") {
+		t.Fatalf("expected re-encoded jpeg, got %.40s", url)
+	}
+
+	raw, err := decodeImageData(url)
+	if err != nil {
+		t.Fatalf("decode data url: %v", err)
+	}
+	scaled, err := loadAuraImage(raw)
+	if err != nil {
+		t.Fatalf("load scaled image: %v", err)
+	}
+	if scaled.features.Width != 100 || scaled.features.Height != 50 {
+		t.Fatalf("expected 100x50, got %dx%d", scaled.features.Width, scaled.features.Height)
+	}
+}
+
+func TestAuraProviderRegistryOrdersByWeight(t *testing.T) {
+	cfg := &config.Config{
+		AuraAIProviders: []config.AuraProviderConfig{
+			{Name: "slow", URL: "http://slow", Model: "m", Key: "k", Weight: 1},
+			{Name: "keyless", URL: "http://keyless", Model: "m", Weight: 5},
+			{Name: "fast", URL: "http://fast", Model: "m", Key: "k", Weight: 3},
+		},
+	}
+
+	analyzer := newAuraAIAnalyzer(cfg, nil)
+	if len(analyzer.providers) != 2 {
+		t.Fatalf("expected keyless provider to be skipped, got %d providers", len(analyzer.providers))
+	}
+	if analyzer.providers[0].name != "fast" || analyzer.providers[1].name != "slow" {
+		t.Fatalf("unexpected order: %s, %s", analyzer.providers[0].name, analyzer.providers[1].name)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.failure()
+	if !breaker.allow() {
+		t.Fatalf("breaker should stay closed below threshold")
+	}
+	breaker.failure()
+	if breaker.allow() {
+		t.Fatalf("breaker should be open after threshold failures")
+	}
+
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatalf("breaker should admit a trial call after cooldown")
+	}
+	if breaker.allow() {
+		t.Fatalf("only one trial call allowed while half-open")
+	}
+
+	breaker.success()
+	if state, failures, _ := breaker.snapshot(); state != breakerClosed || failures != 0 {
+		t.Fatalf("expected closed breaker after success, got %s/%d", state, failures)
+	}
+}
+
+func TestAuraAnalyzerSkipsOpenProvider(t *testing.T) {
+	var primaryCalls int
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"aura_color\":\"blue\",\"energy_level\":60,\"mood_score\":6}"}}]}`))
+	}))
+	defer secondary.Close()
+
+	analyzer := newAuraAIAnalyzer(&config.Config{
+		AuraAIBreakerThreshold: 1,
+		AuraAIBreakerCooldown:  time.Hour,
+		AuraAIProviders: []config.AuraProviderConfig{
+			{Name: "primary", URL: primary.URL, Model: "m", Key: "k", Weight: 2},
+			{Name: "secondary", URL: secondary.URL, Model: "m", Key: "k", Weight: 1},
+		},
+	}, nil)
+
+	base := auraAnalysisResult{AuraColor: "red", EnergyLevel: 50, MoodScore: 5}
+	for i := 0; i < 3; i++ {
+		result, err := analyzer.analyze(context.Background(), auraAnalysisInput{imageURL: "https://cdn.example.com/a.jpg"}, base)
+		if err != nil || result.AuraColor != "blue" {
+			t.Fatalf("scan %d: expected blue from secondary, got %+v (%v)", i, result, err)
+		}
+	}
+	if primaryCalls != 1 {
+		t.Fatalf("expected open breaker to skip primary after first failure, got %d calls", primaryCalls)
+	}
+
+	health := analyzer.healthReport()
+	if health[0].Name != "primary" || health[0].State != breakerOpen || health[0].OpenUntil == nil {
+		t.Fatalf("unexpected primary health: %+v", health[0])
+	}
+}
+
+func TestAuraAnalyzerKeepsHalfOpenTrialForLaterScans(t *testing.T) {
+	primaryDown := false
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if primaryDown {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"aura_color\":\"blue\",\"energy_level\":60,\"mood_score\":6}"}}]}`))
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"aura_color\":\"green\",\"energy_level\":60,\"mood_score\":6}"}}]}`))
+	}))
+	defer secondary.Close()
+
+	analyzer := newAuraAIAnalyzer(&config.Config{
+		AuraAIBreakerThreshold: 1,
+		AuraAIBreakerCooldown:  time.Minute,
+		AuraAIProviders: []config.AuraProviderConfig{
+			{Name: "primary", URL: primary.URL, Model: "m", Key: "k", Weight: 2},
+			{Name: "secondary", URL: secondary.URL, Model: "m", Key: "k", Weight: 1},
+		},
+	}, nil)
+	now := time.Now()
+	breaker := analyzer.providers[1].breaker
+	breaker.now = func() time.Time { return now }
+	breaker.failure()
+	now = now.Add(time.Minute)
+
+	base := auraAnalysisResult{AuraColor: "red", EnergyLevel: 50, MoodScore: 5}
+	input := auraAnalysisInput{imageURL: "https://cdn.example.com/a.jpg"}
+	if result, err := analyzer.analyze(context.Background(), input, base); err != nil || result.AuraColor != "blue" {
+		t.Fatalf("expected blue from primary, got %+v (%v)", result, err)
+	}
+
+	primaryDown = true
+	result, err := analyzer.analyze(context.Background(), input, base)
+	if err != nil || result.AuraColor != "green" {
+		t.Fatalf("expected the half-open secondary to get its trial, got %+v (%v)", result, err)
+	}
+	if state, _, _ := breaker.snapshot(); state != breakerClosed {
+		t.Fatalf("expected secondary breaker to close after its trial, got %s", state)
+	}
+}
+
+func TestParseAuraNarrativeValidatesShape(t *testing.T) {
+	valid := "Here you go: {\"personality\":\"You are steady today.\",\"strengths\":[\" Focus \",\"Patience\",\"\"],\"challenges\":[\"Rigidity\",\"Overthinking\"],\"daily_advice\":\"Take a slow walk.\"}"
+	n, err := parseAuraNarrative(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(n.Strengths) != 2 || n.Strengths[0] != "Focus" {
+		t.Fatalf("expected cleaned strengths, got %#v", n.Strengths)
+	}
+
+	invalid := []string{
+		"not json",
+		`{"personality":"","strengths":["a","b"],"challenges":["c","d"],"daily_advice":"x"}`,
+		`{"personality":"p","strengths":["only one"],"challenges":["c","d"],"daily_advice":"x"}`,
+		`{"personality":"p","strengths":["a","b"],"challenges":["c","d"]}`,
+		`{"personality":"p","strengths":["a","b"],"challenges":["c","` + strings.Repeat("x", narrativeMaxListItem+1) + `"],"daily_advice":"x"}`,
+	}
+	for i, content := range invalid {
+		if _, err := parseAuraNarrative(content); err == nil {
+			t.Fatalf("case %d: expected rejection", i)
+		}
+	}
+}
+
+func TestAuraAnalyzerCompleteFallsBackToNextProvider(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	var temperature float64
+	var prompt string
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req auraChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		temperature = req.Temperature
+		if len(req.Messages) > 1 {
+			prompt, _ = req.Messages[1].Content.(string)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"personality\":\"p\"}"}}]}`))
+	}))
+	defer working.Close()
+
+	analyzer := newAuraAIAnalyzer(&config.Config{
+		AuraAIProviders: []config.AuraProviderConfig{
+			{Name: "first", URL: failing.URL, Model: "m", Key: "k", Weight: 2},
+			{Name: "second", URL: working.URL, Model: "m", Key: "k", Weight: 1},
+		},
+	}, nil)
+
+	messages, err := auraNarrativeMessages(nil,
+		auraAnalysisResult{AuraColor: "blue", EnergyLevel: 70, MoodScore: 7},
+		traitsNarrative(builtinPalette(), i18n.For("es"), "blue", nil),
+		nil,
+		i18n.For("es").LanguageName(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected prompt error: %v", err)
+	}
+	content, provider, err := analyzer.complete(context.Background(), messages, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider != "second" || !strings.Contains(content, "personality") {
+		t.Fatalf("expected answer from second provider, got %s: %s", provider, content)
+	}
+	if temperature <= 0.2 {
+		t.Fatalf("expected narrative temperature above analysis temperature, got %v", temperature)
+	}
+	if !strings.Contains(prompt, "in Spanish") || !strings.Contains(prompt, "Tranquilo, intuitivo") {
+		t.Fatalf("expected a Spanish narrative prompt, got %s", prompt)
+	}
+}
+
+func TestAuraAnalyzerCompleteCountsUnparseableAnswerAsFailure(t *testing.T) {
+	garbled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"not json"}}]}`))
+	}))
+	defer garbled.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"personality\":\"p\"}"}}]}`))
+	}))
+	defer working.Close()
+
+	analyzer := newAuraAIAnalyzer(&config.Config{
+		AuraAIProviders: []config.AuraProviderConfig{
+			{Name: "first", URL: garbled.URL, Model: "m", Key: "k", Weight: 2},
+			{Name: "second", URL: working.URL, Model: "m", Key: "k", Weight: 1},
+		},
+	}, nil)
+
+	var parsed map[string]string
+	_, provider, err := analyzer.complete(context.Background(), []auraChatMessage{{Role: "user", Content: "hi"}}, func(content string) error {
+		return json.Unmarshal([]byte(content), &parsed)
+	})
+	if err != nil || provider != "second" || parsed["personality"] != "p" {
+		t.Fatalf("expected parsed answer from second provider, got %s %v (%v)", provider, parsed, err)
+	}
+	if health := analyzer.healthReport(); health[0].TotalFailures != 1 || health[1].TotalFailures != 0 {
+		t.Fatalf("expected only the unparseable answer to count as a failure, got %+v", health)
+	}
+}
+
+func TestParseBulkDelete(t *testing.T) {
+	id := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	f, err := parseBulkDelete(dto.BulkDeleteAuraRequest{IDs: []string{id.String(), id.String()}})
+	if err != nil || len(f.ids) != 1 || f.hasRange() {
+		t.Fatalf("expected one de-duplicated id and no range, got %+v, %v", f, err)
+	}
+	if f, err = parseBulkDelete(dto.BulkDeleteAuraRequest{From: &from, To: &to}); err != nil || !f.hasRange() {
+		t.Fatalf("expected a range filter, got %+v, %v", f, err)
+	}
+
+	tooMany := make([]string, MaxBulkDeleteIDs+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.NewString()
+	}
+	cases := []struct {
+		name string
+		req  dto.BulkDeleteAuraRequest
+		want error
+	}{
+		{"empty", dto.BulkDeleteAuraRequest{}, ErrBulkDeleteEmpty},
+		{"bad id", dto.BulkDeleteAuraRequest{IDs: []string{"nope"}}, ErrInvalidReadingID},
+		{"too many", dto.BulkDeleteAuraRequest{IDs: tooMany}, ErrBulkDeleteTooBig},
+		{"open range", dto.BulkDeleteAuraRequest{From: &from}, ErrInvalidDateRange},
+		{"reversed range", dto.BulkDeleteAuraRequest{From: &to, To: &from}, ErrInvalidDateRange},
+	}
+	for _, tc := range cases {
+		if _, err := parseBulkDelete(tc.req); err != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestParseTrendQuery(t *testing.T) {
+	q, err := parseTrendQuery("", 0, 0)
+	if err != nil || q.interval != TrendDaily || q.periods != 30 || q.window != 7 {
+		t.Fatalf("unexpected defaults %+v, %v", q, err)
+	}
+	if q, err = parseTrendQuery(TrendMonthly, 6, 0); err != nil || q.periods != 6 || q.window != 3 {
+		t.Fatalf("unexpected monthly query %+v, %v", q, err)
+	}
+	if _, err := parseTrendQuery("hour", 0, 0); err != ErrInvalidTrendInterval {
+		t.Fatalf("expected ErrInvalidTrendInterval, got %v", err)
+	}
+	for _, bad := range [][2]int{{-1, 0}, {maxTrendPeriods + 1, 0}, {0, maxTrendWindow + 1}} {
+		if _, err := parseTrendQuery(TrendWeekly, bad[0], bad[1]); err != ErrInvalidTrendRange {
+			t.Errorf("periods=%d window=%d: expected ErrInvalidTrendRange, got %v", bad[0], bad[1], err)
+		}
+	}
+}
+
+func TestParseReadingListQuery(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+
+	q, err := parseReadingListQuery(dto.AuraListQuery{PageSize: 1000, Color: " Blue ", From: "2026-03-01", To: "2026-03-01"}, loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.pageSize != MaxReadingPageSize || q.color != "blue" {
+		t.Fatalf("expected a clamped page size and normalized color, got %+v", q)
+	}
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, loc); !q.to.Equal(want) || q.to.Sub(*q.from) != 24*time.Hour {
+		t.Fatalf("expected a plain to date to include the whole local day, got %v - %v", q.from, q.to)
+	}
+
+	cursor := readingCursor{createdAt: time.Date(2026, 3, 1, 10, 0, 0, 123456000, time.UTC), id: uuid.New()}
+	q, err = parseReadingListQuery(dto.AuraListQuery{Cursor: cursor.encode()}, loc)
+	if err != nil || q.cursor == nil || !q.cursor.createdAt.Equal(cursor.createdAt) || q.cursor.id != cursor.id {
+		t.Fatalf("cursor did not round-trip: %+v, %v", q.cursor, err)
+	}
+	if q.pageSize != DefaultReadingPageSize {
+		t.Fatalf("expected the default page size, got %d", q.pageSize)
+	}
+
+	for name, bad := range map[string]dto.AuraListQuery{
+		"cursor":       {Cursor: "not-a-cursor"},
+		"date":         {From: "March 1st"},
+		"energy range": {MinEnergy: "80", MaxEnergy: "20"},
+		"mood bounds":  {MaxMood: "11"},
+		"reversed":     {From: "2026-03-02", To: "2026-03-01T00:00:00Z"},
+	} {
+		if _, err := parseReadingListQuery(bad, loc); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/google/uuid"
+)
+
+// Trend bucket sizes accepted by GET /api/aura/trends.
+const (
+	TrendDaily   = "day"
+	TrendWeekly  = "week"
+	TrendMonthly = "month"
+)
+
+const (
+	maxTrendPeriods = 366
+	maxTrendWindow  = 30
+)
+
+var (
+	ErrInvalidTrendInterval = errors.New("interval must be day, week or month")
+	ErrInvalidTrendRange    = errors.New("periods or window out of range")
+)
+
+// trendDefaults are the bucket count and moving-average window used when a request
+// leaves them out.
+var trendDefaults = map[string]struct{ periods, window int }{
+	TrendDaily:   {periods: 30, window: 7},
+	TrendWeekly:  {periods: 12, window: 4},
+	TrendMonthly: {periods: 12, window: 3},
+}
+
+// trendQuery is a validated trends request.
+type trendQuery struct {
+	interval string
+	periods  int
+	window   int
+}
+
+func parseTrendQuery(interval string, periods, window int) (trendQuery, error) {
+	if interval == "" {
+		interval = TrendDaily
+	}
+	defaults, ok := trendDefaults[interval]
+	if !ok {
+		return trendQuery{}, ErrInvalidTrendInterval
+	}
+	if periods == 0 {
+		periods = defaults.periods
+	}
+	if window == 0 {
+		window = defaults.window
+	}
+	if periods < 1 || periods > maxTrendPeriods || window < 1 || window > maxTrendWindow {
+		return trendQuery{}, ErrInvalidTrendRange
+	}
+	return trendQuery{interval: interval, periods: periods, window: window}, nil
+}
+
+// auraTrendsSQL buckets readings by the user's local calendar. The bucket series is
+// generated densely so empty periods appear, and starts window-1 buckets early so the
+// first returned moving average already covers a full window. The frame size is
+// formatted in because window frame offsets cannot be bound parameters everywhere.
+const auraTrendsSQL = `
+WITH series AS (
+	SELECT generate_series(
+		date_trunc(@unit, now() AT TIME ZONE @tz) - (@lookback::int * ('1 ' || @unit::text)::interval),
+		date_trunc(@unit, now() AT TIME ZONE @tz),
+		('1 ' || @unit::text)::interval
+	) AS bucket
+), readings AS (
+	SELECT date_trunc(@unit, created_at AT TIME ZONE @tz) AS bucket, aura_color, energy_level, mood_score, created_at
+	FROM aura_readings
+	WHERE user_id = @user AND deleted_at IS NULL AND group_scan_id IS NULL
+		AND created_at >= ((SELECT MIN(bucket) FROM series) AT TIME ZONE @tz)
+), totals AS (
+	SELECT bucket, COUNT(*) AS readings, AVG(energy_level)::float8 AS avg_energy, AVG(mood_score)::float8 AS avg_mood
+	FROM readings
+	GROUP BY bucket
+), dominant AS (
+	SELECT DISTINCT ON (bucket) bucket, aura_color
+	FROM (
+		SELECT bucket, aura_color, COUNT(*) AS n, MAX(created_at) AS latest
+		FROM readings
+		GROUP BY bucket, aura_color
+	) colors
+	ORDER BY bucket, n DESC, latest DESC
+), trend AS (
+	SELECT s.bucket,
+		COALESCE(t.readings, 0) AS readings,
+		t.avg_energy,
+		t.avg_mood,
+		d.aura_color AS dominant_color,
+		AVG(t.avg_energy) OVER w AS energy_moving_avg,
+		AVG(t.avg_mood) OVER w AS mood_moving_avg,
+		t.avg_energy - LAG(t.avg_energy) OVER (ORDER BY s.bucket) AS energy_delta,
+		t.avg_mood - LAG(t.avg_mood) OVER (ORDER BY s.bucket) AS mood_delta
+	FROM series s
+	LEFT JOIN totals t ON t.bucket = s.bucket
+	LEFT JOIN dominant d ON d.bucket = s.bucket
+	WINDOW w AS (ORDER BY s.bucket ROWS BETWEEN %d PRECEDING AND CURRENT ROW)
+)
+SELECT * FROM trend ORDER BY bucket DESC LIMIT @periods`
+
+type auraTrendRow struct {
+	Bucket          time.Time
+	Readings        int
+	AvgEnergy       *float64
+	AvgMood         *float64
+	DominantColor   *string
+	EnergyMovingAvg *float64
+	MoodMovingAvg   *float64
+	EnergyDelta     *float64
+	MoodDelta       *float64
+}
+
+// GetTrends returns average energy, mood and dominant color per day, week or month in the
+// user's timezone, oldest first, with a trailing moving average over window buckets and
+// the change from the previous bucket. Empty buckets have a zero count and null values;
+// moving averages skip them.
+func (s *AuraService) GetTrends(userID uuid.UUID, interval string, periods, window int) (*dto.AuraTrendsResponse, error) {
+	q, err := parseTrendQuery(interval, periods, window)
+	if err != nil {
+		return nil, err
+	}
+	loc := s.quota.location(userID)
+
+	var rows []auraTrendRow
+	if err := s.db.Raw(fmt.Sprintf(auraTrendsSQL, q.window-1), map[string]interface{}{
+		"unit":     q.interval,
+		"tz":       loc.String(),
+		"lookback": q.periods + q.window - 2,
+		"user":     userID,
+		"periods":  q.periods,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	buckets := make([]dto.AuraTrendBucket, len(rows))
+	for i, r := range rows {
+		// Rows come newest first; the bucket is a local wall-clock time read back as UTC.
+		y, m, d := r.Bucket.Date()
+		buckets[len(rows)-1-i] = dto.AuraTrendBucket{
+			Start:           time.Date(y, m, d, 0, 0, 0, 0, loc),
+			Readings:        r.Readings,
+			AverageEnergy:   round2(r.AvgEnergy),
+			AverageMood:     round2(r.AvgMood),
+			DominantColor:   r.DominantColor,
+			EnergyMovingAvg: round2(r.EnergyMovingAvg),
+			MoodMovingAvg:   round2(r.MoodMovingAvg),
+			EnergyDelta:     round2(r.EnergyDelta),
+			MoodDelta:       round2(r.MoodDelta),
+		}
+	}
+
+	return &dto.AuraTrendsResponse{
+		Interval: q.interval,
+		Timezone: loc.String(),
+		Window:   q.window,
+		Buckets:  buckets,
+	}, nil
+}
+
+func round2(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	r := math.Round(*v*100) / 100
+	return &r
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"strings"
+)
+
+// auraAnalysisInput is everything a provider may look at for one scan.
+type auraAnalysisInput struct {
+	imageURL string
+	image    *auraImage
+	palette  *ColorPalette
+	// prompt is the analysis template assigned to the user; nil uses the embedded default.
+	prompt *promptVariant
+}
+
+// auraChatContentPart is one element of an OpenAI-compatible multimodal message.
+type auraChatContentPart struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	ImageURL *auraChatImageURL `json:"image_url,omitempty"`
+}
+
+type auraChatImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+var errNoImageForVision = errors.New("no image available for vision request")
+
+// hasRemoteImage reports whether the scan references an http(s) image the provider can fetch.
+func (in auraAnalysisInput) hasRemoteImage() bool {
+	u := strings.ToLower(strings.TrimSpace(in.imageURL))
+	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+}
+
+// visionImageURL returns the value for an image_url part: a data URL for inline photos
+// (downscaled to the provider's limits) or the original remote URL.
+func (in auraAnalysisInput) visionImageURL(maxDim, maxBytes int) (string, error) {
+	if in.image != nil {
+		return in.image.dataURL(maxDim, maxBytes)
+	}
+	if in.hasRemoteImage() {
+		return strings.TrimSpace(in.imageURL), nil
+	}
+	return "", errNoImageForVision
+}
+
+// dataURL encodes the photo as a base64 data URL that fits within maxDim pixels on the
+// long side and maxBytes of encoded payload. The original bytes are reused when they
+// already fit; otherwise the image is downscaled and re-encoded as JPEG.
+func (a *auraImage) dataURL(maxDim, maxBytes int) (string, error) {
+	b := a.img.Bounds()
+	longSide := b.Dx()
+	if b.Dy() > longSide {
+		longSide = b.Dy()
+	}
+
+	if (maxDim <= 0 || longSide <= maxDim) && (maxBytes <= 0 || len(a.raw) <= maxBytes) {
+		return "data:" + a.mimeType() + ";base64," + base64.StdEncoding.EncodeToString(a.raw), nil
+	}
+
+	target := longSide
+	if maxDim > 0 && target > maxDim {
+		target = maxDim
+	}
+
+	for attempt := 0; attempt < 4; attempt++ {
+		scaled := resizeImage(a.img, target)
+		for _, quality := range []int{85, 70, 55} {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality}); err != nil {
+				return "", err
+			}
+			if maxBytes <= 0 || buf.Len() <= maxBytes {
+				return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+			}
+		}
+		target = target * 2 / 3
+		if target < 64 {
+			break
+		}
+	}
+
+	return "", fmt.Errorf("image cannot fit provider limit of %d bytes", maxBytes)
+}
+
+// resizeImage box-filters img so its longer side is at most maxDim pixels.
+func resizeImage(img image.Image, maxDim int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxDim <= 0 || (w <= maxDim && h <= maxDim) {
+		return img
+	}
+
+	if w >= h {
+		return resizeImageTo(img, maxDim, h*maxDim/w)
+	}
+	return resizeImageTo(img, w*maxDim/h, maxDim)
+}
+
+// resizeImageTo box-filters img to exactly dw x dh pixels.
+func resizeImageTo(img image.Image, dw, dh int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0 := b.Min.Y + y*h/dh
+		sy1 := b.Min.Y + (y+1)*h/dh
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			sx0 := b.Min.X + x*w/dw
+			sx1 := b.Min.X + (x+1)*w/dw
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
+
+// auraVisionMessages builds the system + multimodal user messages for a vision provider.
+func auraVisionMessages(in auraAnalysisInput, imageURL string, base auraAnalysisResult) ([]auraChatMessage, error) {
+	prompt := resolvePrompt(in.prompt, PromptAnalysis)
+	data := analysisPromptData{Colors: in.palette.PrimaryColors(), Baseline: base}
+	system, err := prompt.render("system", data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := prompt.render("vision", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []auraChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: []auraChatContentPart{
+			{Type: "text", Text: text},
+			{Type: "image_url", ImageURL: &auraChatImageURL{URL: imageURL}},
+		}},
+	}, nil
+}
+
+// auraTextMessages is the prompt for text-only providers. When the photo was decoded the
+// pixel statistics are included so the model still reasons about the actual image.
+func auraTextMessages(in auraAnalysisInput, base auraAnalysisResult) ([]auraChatMessage, error) {
+	prompt := resolvePrompt(in.prompt, PromptAnalysis)
+	data := analysisPromptData{Colors: in.palette.PrimaryColors(), Baseline: base, ImageURL: in.imageURL}
+	if in.image != nil {
+		data.Photo = true
+		data.Stats = in.image.features
+	}
+	system, err := prompt.render("system", data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := prompt.render("text", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []auraChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: text},
+	}, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -25,20 +26,42 @@ var (
 	ErrInvalidToken       = errors.New("invalid or expired refresh token")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrGuestOnlyAction    = errors.New("guest account required")
+	ErrInvalidTimezone    = errors.New("invalid timezone")
+	ErrInvalidLanguage    = errors.New("unsupported language")
+	ErrWeakCredentials    = errors.New("email required and password must be at least 8 characters")
 )
 
+// languageCacheTTL bounds how long a language preference is cached for the locale
+// middleware, which looks it up on every authenticated request. languageCacheSize bounds
+// how many users' preferences are kept; the least recently active are dropped first.
+const (
+	languageCacheTTL  = 5 * time.Minute
+	languageCacheSize = 10000
+)
+
+type cachedLanguage struct {
+	lang    string
+	expires time.Time
+}
+
 type AuthService struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db      *gorm.DB
+	cfg     *config.Config
+	media   *MediaService
+	invites *InviteService
+
+	languages *lruCache[cachedLanguage] // keyed by user ID string
 }
 
-func NewAuthService(db *gorm.DB, cfg *config.Config) *AuthService {
-	return &AuthService{db: db, cfg: cfg}
+func NewAuthService(db *gorm.DB, cfg *config.Config, media *MediaService, invites *InviteService) *AuthService {
+	return &AuthService{db: db, cfg: cfg, media: media, invites: invites, languages: newLRUCache[cachedLanguage](languageCacheSize)}
 }
 
-func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
+// Register creates an email account. clientIP is charged for a bad invite code, as on
+// the redeem endpoint.
+func (s *AuthService) Register(req *dto.RegisterRequest, clientIP string) (*dto.AuthResponse, error) {
 	if len(req.Email) == 0 || len(req.Password) < 8 {
-		return nil, errors.New("email required and password must be at least 8 characters")
+		return nil, ErrWeakCredentials
 	}
 
 	var existing models.User
@@ -60,6 +83,7 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 	if err := s.db.Create(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	s.invites.AttributeSignup(user.ID, req.InviteCode, clientIP)
 
 	return s.generateTokenPair(&user)
 }
@@ -80,7 +104,7 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
 func (s *AuthService) ClaimGuest(userID uuid.UUID, req *dto.ClaimGuestRequest) (*dto.AuthResponse, error) {
 	email := strings.TrimSpace(strings.ToLower(req.Email))
 	if email == "" || len(req.Password) < 8 {
-		return nil, errors.New("email required and password must be at least 8 characters")
+		return nil, ErrWeakCredentials
 	}
 
 	var existing models.User
@@ -154,7 +178,8 @@ func (s *AuthService) Logout(req *dto.LogoutRequest) error {
 }
 
 // DeleteAccount implements Apple Guideline 5.1.1(v) - account deletion.
-// Scrubs all user data: tokens, subscriptions, reports, blocks, then soft-deletes user.
+// Scrubs all user data: tokens, subscriptions, reports, blocks, readings and their photos,
+// then soft-deletes user.
 func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
 	var user models.User
 	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
@@ -171,8 +196,22 @@ func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
 		}
 	}
 
+	// Collect stored photos up front; blobs are removed only once the transaction commits.
+	var readings []models.AuraReading
+	if err := s.db.Unscoped().Select("id", "image_key", "thumbnail_key").
+		Where("user_id = ?", userID).Find(&readings).Error; err != nil {
+		return fmt.Errorf("failed to load readings: %w", err)
+	}
+	// Photos of scan jobs that have not finished are only removed by their worker, which
+	// stops once the job row is gone.
+	var scanInputs []string
+	if err := s.db.Model(&models.ScanJob{}).Where("user_id = ? AND input_key <> ''", userID).
+		Pluck("input_key", &scanInputs).Error; err != nil {
+		return fmt.Errorf("failed to load scan jobs: %w", err)
+	}
+
 	// Scrub all associated data in a transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Revoke all refresh tokens
 		tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{})
 
@@ -185,14 +224,57 @@ func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
 		// Remove blocks
 		tx.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&models.Block{})
 
+		// Remove friendships and friend requests in both directions
+		tx.Where("requester_id = ? OR addressee_id = ?", userID, userID).Delete(&models.Friendship{})
+
+		// Remove the user's invites, who redeemed them, and the user's own redemptions
+		tx.Where("invite_id IN (?)", tx.Model(&models.FriendInvite{}).Select("id").Where("user_id = ?", userID)).Delete(&models.FriendInviteRedemption{})
+		tx.Where("user_id = ?", userID).Delete(&models.FriendInviteRedemption{})
+		tx.Where("user_id = ?", userID).Delete(&models.FriendInvite{})
+
+		// Remove share links and the record of links this user opened. Other users' links
+		// to matches with this user's readings stop working with them.
+		readingIDs := make([]uuid.UUID, len(readings))
+		for i, r := range readings {
+			readingIDs[i] = r.ID
+		}
+		if err := revokeReadingShares(tx, readingIDs); err != nil {
+			return err
+		}
+		tx.Where("share_id IN (?)", tx.Model(&models.ShareLink{}).Select("id").Where("user_id = ?", userID)).Delete(&models.ShareView{})
+		tx.Where("viewer_id = ?", userID).Delete(&models.ShareView{})
+		tx.Where("user_id = ?", userID).Delete(&models.ShareLink{})
+
+		// Keep AI spend history but detach it from the account
+		tx.Model(&models.AICall{}).Where("user_id = ?", userID).Update("user_id", nil)
+
+		// Remove scan jobs and the quota ledger
+		tx.Where("user_id = ?", userID).Delete(&models.ScanJob{})
+		tx.Where("user_id = ?", userID).Delete(&models.ScanReservation{})
+		tx.Where("user_id = ?", userID).Delete(&models.ScanQuotaDay{})
+		tx.Where("user_id = ?", userID).Delete(&models.GroupScan{})
+
+		// Hard-delete aura readings, including previously soft-deleted ones
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.AuraReading{}).Error; err != nil {
+			return err
+		}
+
 		// Soft-delete the user (GORM DeletedAt)
 		return tx.Delete(&user).Error
 	})
+	if err != nil {
+		return err
+	}
+
+	s.media.DeleteReadingImages(readings...)
+	s.media.DeleteScanInput(scanInputs...)
+	return nil
 }
 
 // AppleSignIn handles Sign in with Apple (Guideline 4.8).
-// Verifies Apple identity token and creates/finds a user.
-func (s *AuthService) AppleSignIn(req *dto.AppleSignInRequest) (*dto.AuthResponse, error) {
+// Verifies Apple identity token and creates/finds a user. clientIP is charged for a bad
+// invite code on a new account, as on the redeem endpoint.
+func (s *AuthService) AppleSignIn(req *dto.AppleSignInRequest, clientIP string) (*dto.AuthResponse, error) {
 	allowedAudiences := splitCSV(s.cfg.AppleClientIDs)
 	claims, err := verifyAppleIdentityToken(context.Background(), req.IdentityToken, allowedAudiences)
 	if err != nil {
@@ -236,6 +318,7 @@ func (s *AuthService) AppleSignIn(req *dto.AppleSignInRequest) (*dto.AuthRespons
 			if err := s.db.Create(&user).Error; err != nil {
 				return nil, fmt.Errorf("failed to create Apple user: %w", err)
 			}
+			s.invites.AttributeSignup(user.ID, req.InviteCode, clientIP)
 		} else {
 			return nil, fmt.Errorf("failed to lookup user by email: %w", err)
 		}
@@ -338,9 +421,65 @@ func (s *AuthService) GetProfile(userID uuid.UUID) (map[string]interface{}, erro
 		"email":              user.Email,
 		"subscriptionStatus": subStatus,
 		"currentStreak":      currentStreak,
+		"timezone":           user.Timezone,
+		"language":           user.Language,
 	}, nil
 }
 
+// UpdateProfile changes user preferences. The timezone decides when the daily scan
+// quota resets, so only valid IANA names are accepted. An empty language clears the
+// preference so Accept-Language decides again.
+func (s *AuthService) UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequest) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+
+	if req.Timezone != nil {
+		tz := strings.TrimSpace(*req.Timezone)
+		if tz == "" || strings.EqualFold(tz, "local") {
+			return nil, ErrInvalidTimezone
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+		updates["timezone"] = tz
+	}
+
+	if req.Language != nil {
+		lang := strings.ToLower(strings.TrimSpace(*req.Language))
+		if lang != "" && !i18n.IsSupported(lang) {
+			return nil, ErrInvalidLanguage
+		}
+		updates["language"] = lang
+	}
+
+	if len(updates) > 0 {
+		res := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil, ErrUserNotFound
+		}
+		s.languages.remove(userID.String())
+	}
+
+	return s.GetProfile(userID)
+}
+
+// PreferredLanguage returns the user's saved language, or "" when they have none. It is
+// the lookup behind the locale middleware, so results are cached briefly.
+func (s *AuthService) PreferredLanguage(userID string) string {
+	if entry, ok := s.languages.get(userID); ok && time.Now().Before(entry.expires) {
+		return entry.lang
+	}
+
+	var user models.User
+	if err := s.db.Select("language").Where("id = ?", userID).First(&user).Error; err != nil {
+		return ""
+	}
+	s.languages.add(userID, cachedLanguage{lang: user.Language, expires: time.Now().Add(languageCacheTTL)})
+	return user.Language
+}
+
 func hashToken(token string) string {
 	h := sha256.Sum256([]byte(token))
 	return fmt.Sprintf("%x", h)
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPreferredLanguageCacheIsBounded(t *testing.T) {
+	// No database: a lookup that misses the cache would panic.
+	s := NewAuthService(nil, nil, nil, nil)
+	s.languages.add("user", cachedLanguage{lang: "tr", expires: time.Now().Add(time.Minute)})
+	if got := s.PreferredLanguage("user"); got != "tr" {
+		t.Errorf("cached language = %q, want tr", got)
+	}
+
+	for i := 0; i <= languageCacheSize; i++ {
+		s.languages.add(strconv.Itoa(i), cachedLanguage{lang: "en", expires: time.Now().Add(time.Minute)})
+	}
+	if n := s.languages.len(); n != languageCacheSize {
+		t.Errorf("cache holds %d users, want at most %d", n, languageCacheSize)
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	cardGlyphWidth   = 5
+	cardGlyphHeight  = 7
+	cardGlyphAdvance = cardGlyphWidth + 1
+)
+
+//go:embed fonts/pixel5x7.txt
+var cardFontData string
+
+// cardFont maps a character to its rows, one bit per pixel with the leftmost pixel in
+// the highest of the low five bits.
+type cardFont map[rune][cardGlyphHeight]uint8
+
+var loadCardFont = sync.OnceValue(func() cardFont {
+	font, err := parseCardFont(cardFontData)
+	if err != nil {
+		panic(err)
+	}
+	return font
+})
+
+func parseCardFont(data string) (cardFont, error) {
+	font := make(cardFont)
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	for i := 0; i < len(lines); i += cardGlyphHeight + 1 {
+		header := lines[i]
+		var ch rune
+		switch {
+		case header == "space":
+			ch = ' '
+		case len([]rune(header)) == 1:
+			ch = []rune(header)[0]
+		default:
+			return nil, fmt.Errorf("card font: bad glyph header %q", header)
+		}
+		if i+cardGlyphHeight >= len(lines) {
+			return nil, fmt.Errorf("card font: glyph %q is truncated", header)
+		}
+
+		var glyph [cardGlyphHeight]uint8
+		for row := 0; row < cardGlyphHeight; row++ {
+			bits := lines[i+1+row]
+			if len(bits) != cardGlyphWidth {
+				return nil, fmt.Errorf("card font: glyph %q row %d is %q", header, row, bits)
+			}
+			for col, c := range bits {
+				if c == '#' {
+					glyph[row] |= 1 << (cardGlyphWidth - 1 - col)
+				}
+			}
+		}
+		font[ch] = glyph
+	}
+	return font, nil
+}
+
+// foldCardText folds text to what the pixel font can draw: accents are stripped, letters are
+// upper-cased and anything else without a glyph is dropped.
+func foldCardText(s string) string {
+	font := loadCardFont()
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'ı':
+			r = 'I'
+		case r == 'ß':
+			b.WriteString("SS")
+			continue
+		case unicode.IsSpace(r):
+			r = ' '
+		}
+		r = unicode.ToUpper(r)
+		if _, ok := font[r]; ok {
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// cardTextWidth is the width in pixels of folded text drawn at scale.
+func cardTextWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*cardGlyphAdvance - 1) * scale
+}
+
+// drawCardText draws folded text with its top-left corner at (x, y), each font pixel
+// becoming a scale×scale block.
+func drawCardText(dst *image.RGBA, x, y, scale int, c color.RGBA, text string) {
+	font := loadCardFont()
+	for _, r := range text {
+		glyph := font[r]
+		for row := 0; row < cardGlyphHeight; row++ {
+			for col := 0; col < cardGlyphWidth; col++ {
+				if glyph[row]&(1<<(cardGlyphWidth-1-col)) == 0 {
+					continue
+				}
+				fillRect(dst, x+col*scale, y+row*scale, scale, scale, c)
+			}
+		}
+		x += cardGlyphAdvance * scale
+	}
+}
+
+// drawCardTextCentered centers folded text horizontally within the image.
+func drawCardTextCentered(dst *image.RGBA, y, scale int, c color.RGBA, text string) {
+	x := (dst.Bounds().Dx() - cardTextWidth(text, scale)) / 2
+	drawCardText(dst, x, y, scale, c, text)
+}
+
+// wrapCardText splits folded text into at most maxLines lines of maxChars, ending with an
+// ellipsis when it does not fit.
+func wrapCardText(text string, maxChars, maxLines int) []string {
+	var lines []string
+	line := ""
+	words := strings.Fields(text)
+	for i, word := range words {
+		if len(word) > maxChars {
+			word = word[:maxChars]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= maxChars:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+		if len(lines) == maxLines {
+			last := lines[maxLines-1]
+			if len(last)+3 > maxChars {
+				last = strings.TrimRight(last[:maxChars-3], " ")
+			}
+			lines[maxLines-1] = last + "..."
+			return lines
+		}
+		if i == len(words)-1 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
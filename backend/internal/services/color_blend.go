@@ -0,0 +1,153 @@
+package services
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+)
+
+const (
+	// blendSecondaryWeight is the share of a reading's blend carried by its secondary color.
+	blendSecondaryWeight = 0.3
+	// blendMaxShift is how many compatibility points the secondary colors can add or take
+	// away from a pairing.
+	blendMaxShift = 10
+	// blendNoteShift is the smallest shift, either way, that a match's text mentions.
+	blendNoteShift = 3
+)
+
+// colorVector is a color in HSV space: hue in degrees, saturation and value 0..1.
+type colorVector struct {
+	hue, sat, val float64
+}
+
+// hexVector parses a "#rrggbb" catalog hex into HSV.
+func hexVector(hex string) (colorVector, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return colorVector{}, false
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return colorVector{}, false
+	}
+	h, s, v := rgbToHSV(float64(rgb>>16&0xff), float64(rgb>>8&0xff), float64(rgb&0xff))
+	return colorVector{hue: h, sat: s, val: v}, true
+}
+
+// auraBlend is a reading's colors as weighted HSV vectors: the primary color and, when the
+// reading has one, its secondary accent.
+type auraBlend []blendPart
+
+type blendPart struct {
+	key    string
+	weight float64
+	vec    colorVector
+}
+
+// blend builds the HSV blend of a primary color and an optional secondary color. Colors
+// missing from the palette are left out.
+func (p *ColorPalette) blend(primary string, secondary *string) auraBlend {
+	var parts auraBlend
+	add := func(key string, weight float64) {
+		if c, ok := p.Color(key); ok {
+			if vec, ok := hexVector(c.Hex); ok {
+				parts = append(parts, blendPart{key: key, weight: weight, vec: vec})
+			}
+		}
+	}
+	if secondary == nil || *secondary == primary {
+		add(primary, 1)
+		return parts
+	}
+	add(primary, 1-blendSecondaryWeight)
+	add(*secondary, blendSecondaryWeight)
+	return parts
+}
+
+// vector is the weighted mean of the blend. Hues are averaged as saturation-weighted
+// angles, so a grey or silver accent mutes the primary hue rather than rotating it, and
+// a black accent pulls the value down.
+func (b auraBlend) vector() colorVector {
+	var x, y, val, total float64
+	for _, part := range b {
+		rad := part.vec.hue * math.Pi / 180
+		x += part.weight * part.vec.sat * math.Cos(rad)
+		y += part.weight * part.vec.sat * math.Sin(rad)
+		val += part.weight * part.vec.val
+		total += part.weight
+	}
+	if total == 0 {
+		return colorVector{}
+	}
+	hue := math.Atan2(y, x) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	}
+	return colorVector{hue: hue, sat: math.Hypot(x, y) / total, val: val / total}
+}
+
+// blendHarmony rates two colors from -1 to 1. Hues that match or sit opposite each other
+// harmonize and hues a quarter turn apart clash, weighted by how saturated both are;
+// similar brightness adds to the harmony.
+func blendHarmony(a, b colorVector) float64 {
+	dh := (a.hue - b.hue) * math.Pi / 180
+	hue := math.Cos(2*dh) * math.Sqrt(a.sat*b.sat)
+	val := 1 - 2*math.Abs(a.val-b.val)
+	return 0.6*hue + 0.4*val
+}
+
+// blendShift is the compatibility points two readings' secondary colors add to the score
+// their primary colors alone would get. Readings without accents shift nothing.
+func blendShift(palette *ColorPalette, a, b models.AuraReading) int {
+	full := blendHarmony(palette.blend(a.AuraColor, a.SecondaryColor).vector(), palette.blend(b.AuraColor, b.SecondaryColor).vector())
+	base := blendHarmony(palette.blend(a.AuraColor, nil).vector(), palette.blend(b.AuraColor, nil).vector())
+	return int(math.Round(blendMaxShift * (full - base)))
+}
+
+// BlendedTraits is the reading text for a primary color with an optional secondary accent.
+// The accent adds a line to the personality and, in proportion to its blend weight, a few
+// of its own strengths and challenges after the primary color's.
+func (p *ColorPalette) BlendedTraits(locale *i18n.Localizer, primary string, secondary *string) models.ColorTraits {
+	t := p.Traits(locale, primary)
+	if secondary == nil || *secondary == primary {
+		return t
+	}
+	accent := p.Traits(locale, *secondary)
+	if !colorTraitsComplete(accent) {
+		return t
+	}
+	return models.ColorTraits{
+		Personality: locale.T("traits.blend", t.Personality, p.Name(locale, *secondary), accent.Personality),
+		Strengths:   blendTraitList(t.Strengths, accent.Strengths),
+		Challenges:  blendTraitList(t.Challenges, accent.Challenges),
+		DailyAdvice: t.DailyAdvice,
+	}
+}
+
+func blendTraitList(primary, secondary []string) []string {
+	out := append([]string(nil), primary...)
+	take := int(math.Ceil(blendSecondaryWeight * float64(len(secondary))))
+	for _, item := range secondary {
+		if take == 0 {
+			break
+		}
+		if !containsFold(out, item) {
+			out = append(out, item)
+			take--
+		}
+	}
+	return out
+}
+
+func containsFold(items []string, s string) bool {
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
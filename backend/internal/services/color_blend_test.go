@@ -0,0 +1,79 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+)
+
+func TestHexVector(t *testing.T) {
+	v, ok := hexVector("#FF0000")
+	if !ok || v.hue != 0 || v.sat != 1 || v.val != 1 {
+		t.Errorf("red = %+v, %v", v, ok)
+	}
+	if v, ok := hexVector("0000ff"); !ok || math.Abs(v.hue-240) > 0.01 {
+		t.Errorf("blue = %+v, %v", v, ok)
+	}
+	for _, bad := range []string{"", "#fff", "#gg0000"} {
+		if _, ok := hexVector(bad); ok {
+			t.Errorf("%q parsed", bad)
+		}
+	}
+}
+
+func TestBlendShift(t *testing.T) {
+	palette := builtinPalette()
+	blue := models.AuraReading{AuraColor: "blue"}
+	orange := models.AuraReading{AuraColor: "orange"}
+	if got := blendShift(palette, blue, orange); got != 0 {
+		t.Errorf("shift without accents = %d", got)
+	}
+
+	black := "black"
+	shaded := models.AuraReading{AuraColor: "orange", SecondaryColor: &black}
+	if got := blendShift(palette, blue, shaded); got >= 0 {
+		t.Errorf("black accent shift = %d, want negative", got)
+	}
+	if got := blendShift(palette, blue, shaded); got != blendShift(palette, shaded, blue) {
+		t.Error("shift is not symmetric")
+	}
+
+	colors := append(append([]string(nil), palette.PrimaryColors()...), palette.SecondaryColors()...)
+	for _, a := range palette.PrimaryColors() {
+		for _, b := range colors {
+			ra := models.AuraReading{AuraColor: a, SecondaryColor: &b}
+			if got := blendShift(palette, ra, orange); got < -blendMaxShift || got > blendMaxShift {
+				t.Errorf("%s/%s shift = %d", a, b, got)
+			}
+		}
+	}
+}
+
+func TestBlendedTraits(t *testing.T) {
+	palette := builtinPalette()
+	locale := i18n.For("en")
+	plain := palette.Traits(locale, "blue")
+	if got := palette.BlendedTraits(locale, "blue", nil); got.Personality != plain.Personality {
+		t.Errorf("unblended personality = %q", got.Personality)
+	}
+
+	silver := "silver"
+	got := palette.BlendedTraits(locale, "blue", &silver)
+	if !strings.HasPrefix(got.Personality, plain.Personality) || !strings.Contains(got.Personality, palette.Name(locale, "silver")) {
+		t.Errorf("blended personality = %q", got.Personality)
+	}
+	if len(got.Strengths) <= len(plain.Strengths) || len(got.Challenges) <= len(plain.Challenges) {
+		t.Errorf("blended traits = %v / %v", got.Strengths, got.Challenges)
+	}
+	if got.DailyAdvice != plain.DailyAdvice {
+		t.Errorf("daily advice = %q", got.DailyAdvice)
+	}
+	for _, lang := range []string{"tr", "es"} {
+		if p := palette.BlendedTraits(i18n.For(lang), "blue", &silver).Personality; strings.Contains(p, "%!") {
+			t.Errorf("%s personality = %q", lang, p)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Color catalog statuses.
+const (
+	ColorCatalogDraft   = "draft"
+	ColorCatalogActive  = "active"
+	ColorCatalogRetired = "retired"
+)
+
+const defaultColorCatalogRefresh = time.Minute
+
+var (
+	ErrColorCatalogNotFound  = errors.New("color catalog version not found")
+	ErrColorCatalogPublished = errors.New("only draft color catalogs can be changed")
+	ErrColorNotFound         = errors.New("color not found in catalog")
+)
+
+// ColorCatalogService owns the versioned aura palette. Admins edit drafts and publish
+// them; the active version is cached in memory for the aura, match and streak services
+// and reloaded periodically so every instance picks up a publish.
+type ColorCatalogService struct {
+	db      *gorm.DB
+	refresh time.Duration
+
+	mu      sync.RWMutex
+	palette *ColorPalette
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewColorCatalogService loads the active palette, seeding version 1 from the built-in
+// palette on first run. If the database cannot be read the built-in palette is used
+// until the next successful reload.
+func NewColorCatalogService(db *gorm.DB, cfg *config.Config) *ColorCatalogService {
+	s := &ColorCatalogService{db: db, refresh: cfg.ColorCatalogRefresh}
+	if s.refresh <= 0 {
+		s.refresh = defaultColorCatalogRefresh
+	}
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to load color catalog, using built-in palette: %v", err)
+	}
+	return s
+}
+
+// Palette returns the active palette. It never returns nil.
+func (s *ColorCatalogService) Palette() *ColorPalette {
+	if s == nil {
+		return builtinPalette()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.palette == nil {
+		return builtinPalette()
+	}
+	return s.palette
+}
+
+// Reload reads the active catalog version into the cache. An active version that no
+// longer validates is ignored and the previous palette kept.
+func (s *ColorCatalogService) Reload() error {
+	catalog, err := s.active()
+	if errors.Is(err, ErrColorCatalogNotFound) {
+		catalog, err = s.seed()
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateColorCatalog(catalog.Colors); err != nil {
+		return err
+	}
+
+	palette := catalogPalette(catalog)
+	s.mu.Lock()
+	s.palette = palette
+	s.mu.Unlock()
+	return nil
+}
+
+// seed stores the built-in palette as the first active version. Instances racing to seed
+// all end up reading the same row.
+func (s *ColorCatalogService) seed() (*models.ColorCatalog, error) {
+	now := time.Now()
+	catalog := models.ColorCatalog{
+		Version:     1,
+		Status:      ColorCatalogActive,
+		Note:        "Built-in palette",
+		Colors:      defaultColorDefinitions(),
+		PublishedAt: &now,
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&catalog).Error; err != nil {
+		return nil, err
+	}
+	return s.active()
+}
+
+// Start periodically reloads the active palette.
+func (s *ColorCatalogService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stop = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Reload(); err != nil {
+					log.Printf("Failed to reload color catalog: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends the reload loop.
+func (s *ColorCatalogService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	s.stop()
+	s.wg.Wait()
+}
+
+// --- Admin ---
+
+// Active returns the published catalog version currently in use.
+func (s *ColorCatalogService) Active() (*models.ColorCatalog, error) {
+	return s.active()
+}
+
+func (s *ColorCatalogService) active() (*models.ColorCatalog, error) {
+	var catalog models.ColorCatalog
+	err := s.db.Where("status = ?", ColorCatalogActive).Order("version DESC").First(&catalog).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrColorCatalogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// ListVersions returns every catalog version, newest first, without their colors.
+func (s *ColorCatalogService) ListVersions() ([]models.ColorCatalog, error) {
+	var catalogs []models.ColorCatalog
+	err := s.db.Omit("colors").Order("version DESC").Find(&catalogs).Error
+	return catalogs, err
+}
+
+// GetVersion returns one catalog version with its colors.
+func (s *ColorCatalogService) GetVersion(version int) (*models.ColorCatalog, error) {
+	var catalog models.ColorCatalog
+	err := s.db.Where("version = ?", version).First(&catalog).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrColorCatalogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// Problems lists what would stop a catalog from being published.
+func (s *ColorCatalogService) Problems(catalog *models.ColorCatalog) []string {
+	var catalogErr *ColorCatalogError
+	if err := validateColorCatalog(catalog.Colors); errors.As(err, &catalogErr) {
+		return catalogErr.Problems
+	}
+	return []string{}
+}
+
+// CreateDraft adds a new draft version numbered after the newest existing one.
+func (s *ColorCatalogService) CreateDraft(req dto.CreateColorCatalogRequest) (*models.ColorCatalog, error) {
+	colors := req.Colors
+	if colors == nil {
+		active, err := s.active()
+		if err != nil && !errors.Is(err, ErrColorCatalogNotFound) {
+			return nil, err
+		}
+		if active != nil {
+			colors = active.Colors
+		} else {
+			colors = defaultColorDefinitions()
+		}
+	}
+
+	catalog := models.ColorCatalog{
+		Status: ColorCatalogDraft,
+		Note:   req.Note,
+		Colors: cleanColorDefinitions(colors),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.ColorCatalog{}).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		catalog.Version = latest + 1
+		return tx.Create(&catalog).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// UpdateDraft replaces a draft's note and/or colors.
+func (s *ColorCatalogService) UpdateDraft(version int, req dto.UpdateColorCatalogRequest) (*models.ColorCatalog, error) {
+	return s.editDraft(version, func(catalog *models.ColorCatalog) error {
+		if req.Note != nil {
+			catalog.Note = *req.Note
+		}
+		if req.Colors != nil {
+			catalog.Colors = cleanColorDefinitions(*req.Colors)
+		}
+		return nil
+	})
+}
+
+// UpsertColor adds a color to a draft or replaces the one with the same key.
+func (s *ColorCatalogService) UpsertColor(version int, key string, color models.ColorDefinition) (*models.ColorCatalog, error) {
+	color.Key = key
+	color = cleanColorDefinition(color)
+	return s.editDraft(version, func(catalog *models.ColorCatalog) error {
+		for i := range catalog.Colors {
+			if catalog.Colors[i].Key == color.Key {
+				catalog.Colors[i] = color
+				return nil
+			}
+		}
+		catalog.Colors = append(catalog.Colors, color)
+		return nil
+	})
+}
+
+// DeleteColor removes a color from a draft. Pairings that referenced it are left for the
+// admin to fix; validation reports them.
+func (s *ColorCatalogService) DeleteColor(version int, key string) (*models.ColorCatalog, error) {
+	return s.editDraft(version, func(catalog *models.ColorCatalog) error {
+		for i := range catalog.Colors {
+			if catalog.Colors[i].Key == key {
+				catalog.Colors = append(catalog.Colors[:i], catalog.Colors[i+1:]...)
+				return nil
+			}
+		}
+		return ErrColorNotFound
+	})
+}
+
+// DeleteDraft removes an unpublished version.
+func (s *ColorCatalogService) DeleteDraft(version int) error {
+	catalog, err := s.GetVersion(version)
+	if err != nil {
+		return err
+	}
+	if catalog.Status != ColorCatalogDraft {
+		return ErrColorCatalogPublished
+	}
+	return s.db.Delete(catalog).Error
+}
+
+func (s *ColorCatalogService) editDraft(version int, edit func(*models.ColorCatalog) error) (*models.ColorCatalog, error) {
+	var catalog models.ColorCatalog
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("version = ?", version).First(&catalog).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrColorCatalogNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if catalog.Status != ColorCatalogDraft {
+			return ErrColorCatalogPublished
+		}
+		if err := edit(&catalog); err != nil {
+			return err
+		}
+		return tx.Save(&catalog).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// Publish validates a draft, or a retired version being rolled back to, and makes it the
+// active palette. The previously active version is retired. A version that fails
+// validation returns a *ColorCatalogError.
+func (s *ColorCatalogService) Publish(version int) (*models.ColorCatalog, error) {
+	var catalog models.ColorCatalog
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("version = ?", version).First(&catalog).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrColorCatalogNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if catalog.Status == ColorCatalogActive {
+			return nil
+		}
+		if err := validateColorCatalog(catalog.Colors); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.ColorCatalog{}).
+			Where("status = ? AND version <> ?", ColorCatalogActive, version).
+			Update("status", ColorCatalogRetired).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		catalog.Status = ColorCatalogActive
+		catalog.PublishedAt = &now
+		return tx.Model(&catalog).Updates(map[string]interface{}{
+			"status":       catalog.Status,
+			"published_at": catalog.PublishedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to reload color catalog after publishing v%d: %v", version, err)
+	}
+	return &catalog, nil
+}
+
+func cleanColorDefinitions(colors []models.ColorDefinition) []models.ColorDefinition {
+	cleaned := make([]models.ColorDefinition, len(colors))
+	for i, c := range colors {
+		cleaned[i] = cleanColorDefinition(c)
+	}
+	return cleaned
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+)
+
+func TestDefaultColorCatalogIsValid(t *testing.T) {
+	if err := validateColorCatalog(defaultColorDefinitions()); err != nil {
+		t.Fatalf("built-in palette does not validate: %v", err)
+	}
+
+	p := builtinPalette()
+	if len(p.PrimaryColors()) != 10 || len(p.SecondaryColors()) != 5 {
+		t.Fatalf("unexpected palette sizes: %v / %v", p.PrimaryColors(), p.SecondaryColors())
+	}
+	if p.Complement("blue") != "orange" || !p.Challenging("gold", "red") {
+		t.Fatal("built-in pairings changed")
+	}
+	if color, days := p.NextUnlock(4); color != "gold" || days != 3 {
+		t.Fatalf("NextUnlock(4) = %q, %d", color, days)
+	}
+	if got := p.UnlockAt(21); got != "rainbow" {
+		t.Fatalf("UnlockAt(21) = %q", got)
+	}
+	if got := p.Name(i18n.For("tr"), "rainbow"); got != i18n.For("tr").T("colors.rainbow") {
+		t.Fatalf("unexpected localized name %q", got)
+	}
+	if got := p.RareColors(); !reflect.DeepEqual(got, []string{"rainbow", "cosmic", "celestial"}) {
+		t.Fatalf("RareColors() = %v", got)
+	}
+}
+
+func TestRollRareColor(t *testing.T) {
+	p := builtinPalette()
+	cases := []struct {
+		unlocked []string
+		roll     float64
+		want     string
+	}{
+		{nil, 0, ""},
+		{[]string{"silver", "gold"}, 0, ""},
+		{[]string{"cosmic"}, 0.01, "cosmic"},
+		{[]string{"cosmic"}, 0.07, ""},
+		{[]string{"rainbow", "cosmic"}, 0.15, "cosmic"},
+		{[]string{"rainbow", "cosmic", "celestial"}, 0.2, "celestial"},
+		{[]string{"rainbow", "cosmic", "celestial"}, 0.5, ""},
+	}
+	for _, tc := range cases {
+		if got := p.rollRareColor(tc.unlocked, tc.roll); got != tc.want {
+			t.Errorf("rollRareColor(%v, %v) = %q, want %q", tc.unlocked, tc.roll, got, tc.want)
+		}
+	}
+
+	personal := p.PersonalPalette([]string{"silver", "celestial"})
+	if len(personal) != len(p.PrimaryColors())+1 || personal[len(personal)-1] != "celestial" {
+		t.Errorf("PersonalPalette = %v", personal)
+	}
+	if traits := p.Traits(i18n.For("es"), "cosmic"); !colorTraitsComplete(traits) {
+		t.Errorf("cosmic traits = %+v", traits)
+	}
+}
+
+func TestValidateColorCatalogReportsProblems(t *testing.T) {
+	colors := defaultColorDefinitions()
+	for i := range colors {
+		switch colors[i].Key {
+		case "red":
+			colors[i].Complement = "blue" // blue's complement is orange
+		case "pink":
+			colors[i].Traits = nil
+		case "grey":
+			colors[i].Hex = "grey"
+		case "gold":
+			colors[i].ScanChance = 0.1
+		case "cosmic":
+			colors[i].ScanChance = 0.45
+			colors[i].CardStyle = "sparkle"
+		}
+	}
+	colors = append(colors, models.ColorDefinition{Key: "Teal", Hex: "#008080", Primary: true})
+
+	err := validateColorCatalog(colors)
+	var catalogErr *ColorCatalogError
+	if !errors.As(err, &catalogErr) {
+		t.Fatalf("expected a ColorCatalogError, got %v", err)
+	}
+
+	problems := strings.Join(catalogErr.Problems, "\n")
+	for _, want := range []string{
+		`red: complement "blue" must name red`,
+		"pink: en traits need",
+		"grey: hex",
+		`color key "Teal"`,
+		"gold: primary colors cannot have a scan_chance",
+		"rare color scan chances add up to",
+		`cosmic: unknown card_style "sparkle"`,
+	} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("expected a problem containing %q, got:\n%s", want, problems)
+		}
+	}
+}
+
+func TestPaletteFallsBackForUnknownColors(t *testing.T) {
+	p := newColorPalette(2, []models.ColorDefinition{
+		{Key: "sky", Primary: true, Names: map[string]string{"en": "Sky"}},
+		{Key: "sea", Primary: true, Names: map[string]string{"en": "Sea"}},
+	})
+	if got := p.FallbackColor(); got != "sky" {
+		t.Fatalf("expected the first primary as fallback without violet, got %q", got)
+	}
+	if got := p.Name(i18n.For("es"), "sea"); got != "Sea" {
+		t.Fatalf("expected the default-language name, got %q", got)
+	}
+
+	var missing *ColorCatalogService
+	if missing.Palette() != builtinPalette() {
+		t.Fatal("a nil service must serve the built-in palette")
+	}
+}
+
+func TestPaletteRollsRareColorsOfCatalogsPublishedBeforeThem(t *testing.T) {
+	// Catalogs published before rare colors had the rewards with no scan chance, card
+	// style, match detail or traits.
+	colors := defaultColorDefinitions()
+	for i := range colors {
+		if isRareColor(colors[i]) {
+			colors[i].ScanChance = 0
+			colors[i].CardStyle = ""
+			colors[i].MatchDetail = nil
+			colors[i].Traits = nil
+		}
+	}
+	p := catalogPalette(&models.ColorCatalog{Version: 4, Colors: colors})
+
+	if got := p.RareColors(); !reflect.DeepEqual(got, builtinPalette().RareColors()) {
+		t.Fatalf("rare colors = %v, want the built-in ones", got)
+	}
+	if got := p.rollRareColor([]string{"rainbow"}, 0); got != "rainbow" {
+		t.Errorf("unlocked rainbow not rolled, got %q", got)
+	}
+	if got := p.CardStyle("cosmic"); got != CardStyleNebula {
+		t.Errorf("cosmic card style = %q", got)
+	}
+	en := i18n.For("en")
+	if p.MatchDetail(en, "celestial") == "" || p.Traits(en, "celestial").Personality == "" {
+		t.Error("celestial has no match detail or traits")
+	}
+	if colors[len(colors)-1].ScanChance != 0 {
+		t.Error("the stored catalog was modified")
+	}
+
+	// A reward given a card style and no scan chance is deliberately kept out of scans.
+	colors[len(colors)-1].CardStyle = CardStyleHalo
+	if p := catalogPalette(&models.ColorCatalog{Version: 5, Colors: colors}); p.IsRare(colors[len(colors)-1].Key) {
+		t.Error("a reward without a scan chance was made rare")
+	}
+}
@@ -0,0 +1,602 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+)
+
+// defaultAuraColor is the reading color used when an analysis lands outside the palette.
+const defaultAuraColor = "violet"
+
+// Share card looks a catalog color can ask for; the empty style is the plain orb.
+const (
+	CardStylePrism  = "prism"
+	CardStyleNebula = "nebula"
+	CardStyleHalo   = "halo"
+)
+
+var cardStyles = map[string]bool{CardStylePrism: true, CardStyleNebula: true, CardStyleHalo: true}
+
+// ColorPalette is a read-only, indexed view of one color catalog version. A nil palette
+// behaves as the built-in default palette.
+type ColorPalette struct {
+	Version     int
+	colors      []models.ColorDefinition
+	byKey       map[string]*models.ColorDefinition
+	primaries   []string
+	secondaries []string
+	rares       []string
+	unlocks     []colorUnlock
+}
+
+type colorUnlock struct {
+	days  int
+	color string
+}
+
+func newColorPalette(version int, colors []models.ColorDefinition) *ColorPalette {
+	p := &ColorPalette{
+		Version: version,
+		colors:  colors,
+		byKey:   make(map[string]*models.ColorDefinition, len(colors)),
+	}
+	for i := range colors {
+		c := &colors[i]
+		p.byKey[c.Key] = c
+		if c.Primary {
+			p.primaries = append(p.primaries, c.Key)
+		}
+		if c.Secondary {
+			p.secondaries = append(p.secondaries, c.Key)
+		}
+		if c.UnlockStreak > 0 {
+			p.unlocks = append(p.unlocks, colorUnlock{days: c.UnlockStreak, color: c.Key})
+		}
+		if isRareColor(*c) {
+			p.rares = append(p.rares, c.Key)
+		}
+	}
+	sort.Slice(p.unlocks, func(i, j int) bool { return p.unlocks[i].days < p.unlocks[j].days })
+	return p
+}
+
+// catalogPalette indexes a published catalog version. Catalogs published before rare
+// colors could turn up in scans borrow the built-in rare settings; see
+// withBuiltinRareColors.
+func catalogPalette(catalog *models.ColorCatalog) *ColorPalette {
+	return newColorPalette(catalog.Version, withBuiltinRareColors(catalog.Colors))
+}
+
+// withBuiltinRareColors returns colors with the built-in scan chance, card style and
+// match detail filled in for streak rewards that have neither a scan chance nor a card
+// style, which is how rewards look in catalogs published before rare colors. An admin
+// keeps a reward out of scans by giving it a card style and no scan chance.
+func withBuiltinRareColors(colors []models.ColorDefinition) []models.ColorDefinition {
+	var merged []models.ColorDefinition
+	for i, c := range colors {
+		if c.Primary || c.UnlockStreak <= 0 || c.ScanChance > 0 || c.CardStyle != "" {
+			continue
+		}
+		builtin, ok := builtinPalette().byKey[c.Key]
+		if !ok || !isRareColor(*builtin) {
+			continue
+		}
+		if merged == nil {
+			merged = append([]models.ColorDefinition(nil), colors...)
+		}
+		merged[i].ScanChance = builtin.ScanChance
+		merged[i].CardStyle = builtin.CardStyle
+		if len(c.MatchDetail) == 0 {
+			merged[i].MatchDetail = builtin.MatchDetail
+		}
+	}
+	if merged == nil {
+		return colors
+	}
+	return merged
+}
+
+var builtinPalette = sync.OnceValue(func() *ColorPalette {
+	return newColorPalette(0, defaultColorDefinitions())
+})
+
+func (p *ColorPalette) orDefault() *ColorPalette {
+	if p == nil {
+		return builtinPalette()
+	}
+	return p
+}
+
+// PrimaryColors lists the colors a reading can have, in catalog order.
+func (p *ColorPalette) PrimaryColors() []string {
+	return p.orDefault().primaries
+}
+
+// SecondaryColors lists the accent colors a reading can have.
+func (p *ColorPalette) SecondaryColors() []string {
+	return p.orDefault().secondaries
+}
+
+// RareColors lists the streak rewards that can be a reading's main color for users who
+// unlocked them, in catalog order.
+func (p *ColorPalette) RareColors() []string {
+	return p.orDefault().rares
+}
+
+// Color returns the definition for key.
+func (p *ColorPalette) Color(key string) (*models.ColorDefinition, bool) {
+	c, ok := p.orDefault().byKey[key]
+	return c, ok
+}
+
+// IsPrimary reports whether key can be a reading's main color.
+func (p *ColorPalette) IsPrimary(key string) bool {
+	c, ok := p.Color(key)
+	return ok && c.Primary
+}
+
+// IsSecondary reports whether key can be a reading's accent color.
+func (p *ColorPalette) IsSecondary(key string) bool {
+	c, ok := p.Color(key)
+	return ok && c.Secondary
+}
+
+// IsRare reports whether key is a streak reward that can be an owner's reading color.
+func (p *ColorPalette) IsRare(key string) bool {
+	c, ok := p.Color(key)
+	return ok && isRareColor(*c)
+}
+
+func isRareColor(c models.ColorDefinition) bool {
+	return !c.Primary && c.UnlockStreak > 0 && c.ScanChance > 0
+}
+
+// PersonalPalette lists the colors a user's readings can come out in: every primary color
+// plus the rare colors among unlocked.
+func (p *ColorPalette) PersonalPalette(unlocked []string) []string {
+	colors := append([]string(nil), p.PrimaryColors()...)
+	for _, key := range p.RareColors() {
+		if contains(unlocked, key) {
+			colors = append(colors, key)
+		}
+	}
+	return colors
+}
+
+// rollRareColor picks the rare color a scan comes out in, if any. Each unlocked rare color
+// owns a slice of [0, 1) as wide as its scan chance, so rarer colors turn up less often;
+// roll lands in one of them or in the remainder, which keeps the scanned color.
+func (p *ColorPalette) rollRareColor(unlocked []string, roll float64) string {
+	var upTo float64
+	for _, key := range p.RareColors() {
+		if !contains(unlocked, key) {
+			continue
+		}
+		c, _ := p.Color(key)
+		upTo += c.ScanChance
+		if roll < upTo {
+			return key
+		}
+	}
+	return ""
+}
+
+// CardStyle is the share card look for key, "" for the plain orb.
+func (p *ColorPalette) CardStyle(key string) string {
+	if c, ok := p.Color(key); ok {
+		return c.CardStyle
+	}
+	return ""
+}
+
+// normalizePrimary lower-cases color and returns it if it is a primary color, "" otherwise.
+func (p *ColorPalette) normalizePrimary(color string) string {
+	normalized := strings.ToLower(strings.TrimSpace(color))
+	if p.IsPrimary(normalized) {
+		return normalized
+	}
+	return ""
+}
+
+// FallbackColor is the reading color used when an analysis returns none from the palette.
+func (p *ColorPalette) FallbackColor() string {
+	p = p.orDefault()
+	if p.IsPrimary(defaultAuraColor) || len(p.primaries) == 0 {
+		return defaultAuraColor
+	}
+	return p.primaries[0]
+}
+
+// Name is the color's display name in the localizer's language.
+func (p *ColorPalette) Name(locale *i18n.Localizer, key string) string {
+	c, ok := p.Color(key)
+	if !ok {
+		return key
+	}
+	return localizedText(c.Names, locale, key)
+}
+
+// MatchDetail is the color's short line used in fallback match text.
+func (p *ColorPalette) MatchDetail(locale *i18n.Localizer, key string) string {
+	c, ok := p.Color(key)
+	if !ok {
+		return ""
+	}
+	return localizedText(c.MatchDetail, locale, "")
+}
+
+// Traits returns the color's reading text in the localizer's language, falling back to
+// the default language when that translation is missing. Catalogs published before
+// secondary colors had traits borrow the built-in text for those colors.
+func (p *ColorPalette) Traits(locale *i18n.Localizer, key string) models.ColorTraits {
+	c, ok := p.Color(key)
+	if !ok {
+		return models.ColorTraits{}
+	}
+	if t, ok := c.Traits[locale.Lang()]; ok && colorTraitsComplete(t) {
+		return t
+	}
+	if t := c.Traits[i18n.Default]; colorTraitsComplete(t) || p.orDefault() == builtinPalette() {
+		return t
+	}
+	return builtinPalette().Traits(locale, key)
+}
+
+// Complement returns the primary color that pairs best with key.
+func (p *ColorPalette) Complement(key string) string {
+	if c, ok := p.Color(key); ok {
+		return c.Complement
+	}
+	return ""
+}
+
+// Challenging reports whether either color lists the other as a challenging pairing.
+func (p *ColorPalette) Challenging(a, b string) bool {
+	if c, ok := p.Color(a); ok && contains(c.Challenging, b) {
+		return true
+	}
+	if c, ok := p.Color(b); ok && contains(c.Challenging, a) {
+		return true
+	}
+	return false
+}
+
+// UnlockAt returns the color unlocked by reaching a streak of days, if any.
+func (p *ColorPalette) UnlockAt(days int) string {
+	for _, u := range p.orDefault().unlocks {
+		if u.days == days {
+			return u.color
+		}
+	}
+	return ""
+}
+
+// NextUnlock returns the next streak reward after current days and how far away it is.
+func (p *ColorPalette) NextUnlock(current int) (string, int) {
+	for _, u := range p.orDefault().unlocks {
+		if current < u.days {
+			return u.color, u.days - current
+		}
+	}
+	return "", 0
+}
+
+// colorMeanings is the palette summary given to AI providers for match analysis. Colors
+// that can only be secondary are listed as accents, and rare colors as streak rewards.
+func (p *ColorPalette) colorMeanings() string {
+	p = p.orDefault()
+	var b strings.Builder
+	describe := func(key, suffix string) {
+		c := p.byKey[key]
+		t := p.Traits(nil, key)
+		fmt.Fprintf(&b, "- %s%s: %s Strengths: %s. Challenges: %s.\n",
+			localizedText(c.Names, nil, key), suffix, t.Personality,
+			strings.ToLower(strings.Join(t.Strengths, ", ")), strings.ToLower(strings.Join(t.Challenges, ", ")))
+	}
+	for _, key := range p.primaries {
+		describe(key, "")
+	}
+	for _, key := range p.secondaries {
+		if !p.byKey[key].Primary && colorTraitsComplete(p.Traits(nil, key)) {
+			describe(key, " (secondary accent only)")
+		}
+	}
+	for _, key := range p.rares {
+		describe(key, " (rare streak reward)")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// complementPairs lists each complementary pair once, e.g. "red-green".
+func (p *ColorPalette) complementPairs() string {
+	p = p.orDefault()
+	seen := make(map[string]bool)
+	var pairs []string
+	for _, key := range p.primaries {
+		other := p.byKey[key].Complement
+		if other == "" || seen[key] {
+			continue
+		}
+		seen[key], seen[other] = true, true
+		pairs = append(pairs, key+"-"+other)
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func localizedText(texts map[string]string, locale *i18n.Localizer, fallback string) string {
+	if t := texts[locale.Lang()]; t != "" {
+		return t
+	}
+	if t := texts[i18n.Default]; t != "" {
+		return t
+	}
+	return fallback
+}
+
+func colorTraitsComplete(t models.ColorTraits) bool {
+	return strings.TrimSpace(t.Personality) != "" &&
+		strings.TrimSpace(t.DailyAdvice) != "" &&
+		len(nonEmpty(t.Strengths)) >= colorTraitsMinItems &&
+		len(nonEmpty(t.Challenges)) >= colorTraitsMinItems
+}
+
+func nonEmpty(items []string) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+const (
+	colorCatalogMinPrimaries = 2
+	colorTraitsMinItems      = 2
+	// colorRareMaxChance caps the summed scan chance of all rare colors, so a user who
+	// unlocked every one still mostly sees their scanned color.
+	colorRareMaxChance = 0.5
+)
+
+var (
+	colorKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+	colorHexPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// ColorCatalogError lists every consistency problem found in a palette.
+type ColorCatalogError struct {
+	Problems []string
+}
+
+func (e *ColorCatalogError) Error() string {
+	return "invalid color catalog: " + strings.Join(e.Problems, "; ")
+}
+
+// cleanColorDefinition trims and lower-cases the identifiers of an admin-supplied color.
+func cleanColorDefinition(c models.ColorDefinition) models.ColorDefinition {
+	c.Key = strings.ToLower(strings.TrimSpace(c.Key))
+	c.Hex = strings.ToLower(strings.TrimSpace(c.Hex))
+	c.Complement = strings.ToLower(strings.TrimSpace(c.Complement))
+	c.CardStyle = strings.ToLower(strings.TrimSpace(c.CardStyle))
+	challenging := make([]string, 0, len(c.Challenging))
+	for _, other := range c.Challenging {
+		if other = strings.ToLower(strings.TrimSpace(other)); other != "" {
+			challenging = append(challenging, other)
+		}
+	}
+	c.Challenging = challenging
+	return c
+}
+
+// validateColorCatalog checks that a palette is usable by every service: each color is
+// well formed and named, every color a reading can show has complete traits, every
+// primary and rare color has a match line, rare colors are streak rewards with a modest
+// scan chance, and pairings only reference primary colors (complements in both
+// directions).
+func validateColorCatalog(colors []models.ColorDefinition) error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	byKey := make(map[string]models.ColorDefinition, len(colors))
+	unlockDays := make(map[int]string)
+	primaries := 0
+	rareChance := 0.0
+	for _, c := range colors {
+		if !colorKeyPattern.MatchString(c.Key) {
+			add("color key %q must be 2-32 lowercase letters, digits, '-' or '_'", c.Key)
+			continue
+		}
+		if _, dup := byKey[c.Key]; dup {
+			add("color %q is defined more than once", c.Key)
+			continue
+		}
+		byKey[c.Key] = c
+		if c.Primary {
+			primaries++
+		}
+		if isRareColor(c) {
+			rareChance += c.ScanChance
+		}
+		if c.UnlockStreak > 0 {
+			if other, dup := unlockDays[c.UnlockStreak]; dup {
+				add("colors %q and %q both unlock at a %d-day streak", other, c.Key, c.UnlockStreak)
+			}
+			unlockDays[c.UnlockStreak] = c.Key
+		}
+	}
+	if primaries < colorCatalogMinPrimaries {
+		add("the palette needs at least %d primary colors", colorCatalogMinPrimaries)
+	}
+	if rareChance > colorRareMaxChance {
+		add("rare color scan chances add up to %.2f; the most allowed is %.2f", rareChance, colorRareMaxChance)
+	}
+
+	for _, c := range colors {
+		if _, ok := byKey[c.Key]; !ok {
+			continue
+		}
+		if !colorHexPattern.MatchString(c.Hex) {
+			add("%s: hex must look like #a1b2c3", c.Key)
+		}
+		if strings.TrimSpace(c.Names[i18n.Default]) == "" {
+			add("%s: missing %s name", c.Key, i18n.Default)
+		}
+		if !c.Primary && !c.Secondary && c.UnlockStreak <= 0 {
+			add("%s: must be primary, secondary or unlocked by a streak", c.Key)
+		}
+		if c.UnlockStreak < 0 {
+			add("%s: unlock_streak cannot be negative", c.Key)
+		}
+		switch {
+		case c.ScanChance < 0 || c.ScanChance > colorRareMaxChance:
+			add("%s: scan_chance must be between 0 and %.2f", c.Key, colorRareMaxChance)
+		case c.ScanChance > 0 && c.Primary:
+			add("%s: primary colors cannot have a scan_chance", c.Key)
+		case c.ScanChance > 0 && c.UnlockStreak <= 0:
+			add("%s: only streak rewards can have a scan_chance", c.Key)
+		}
+		if c.CardStyle != "" && !cardStyles[c.CardStyle] {
+			add("%s: unknown card_style %q", c.Key, c.CardStyle)
+		}
+		for lang := range c.Names {
+			if !i18n.IsSupported(lang) {
+				add("%s: unsupported language %q", c.Key, lang)
+			}
+		}
+
+		rare := isRareColor(c)
+		if c.Primary || c.Secondary || rare {
+			if !colorTraitsComplete(c.Traits[i18n.Default]) {
+				add("%s: %s traits need a personality, daily advice and at least %d strengths and challenges",
+					c.Key, i18n.Default, colorTraitsMinItems)
+			}
+			for lang, t := range c.Traits {
+				if !i18n.IsSupported(lang) {
+					add("%s: unsupported language %q", c.Key, lang)
+				} else if !colorTraitsComplete(t) {
+					add("%s: %s traits are incomplete", c.Key, lang)
+				}
+			}
+		}
+
+		if (c.Primary || rare) && strings.TrimSpace(c.MatchDetail[i18n.Default]) == "" {
+			add("%s: missing %s match detail", c.Key, i18n.Default)
+		}
+		if !c.Primary {
+			if c.Complement != "" || len(c.Challenging) > 0 {
+				add("%s: only primary colors have match pairings", c.Key)
+			}
+			continue
+		}
+
+		switch other, ok := byKey[c.Complement]; {
+		case c.Complement == "":
+			add("%s: primary colors need a complement", c.Key)
+		case c.Complement == c.Key:
+			add("%s: cannot complement itself", c.Key)
+		case !ok || !other.Primary:
+			add("%s: complement %q is not a primary color", c.Key, c.Complement)
+		case other.Complement != c.Key:
+			add("%s: complement %q must name %s as its complement", c.Key, c.Complement, c.Key)
+		}
+		for _, challenger := range c.Challenging {
+			other, ok := byKey[challenger]
+			switch {
+			case challenger == c.Key:
+				add("%s: cannot challenge itself", c.Key)
+			case !ok || !other.Primary:
+				add("%s: challenging color %q is not a primary color", c.Key, challenger)
+			case challenger == c.Complement:
+				add("%s: %q cannot be both complement and challenging", c.Key, challenger)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ColorCatalogError{Problems: problems}
+	}
+	return nil
+}
+
+// defaultColorSpecs is the palette seeded into the first catalog version. Names and text
+// come from the embedded locale files.
+var defaultColorSpecs = []struct {
+	key         string
+	hex         string
+	primary     bool
+	secondary   bool
+	unlock      int
+	chance      float64
+	style       string
+	complement  string
+	challenging []string
+}{
+	{key: "red", hex: "#ef4444", primary: true, complement: "green", challenging: []string{"orange", "gold"}},
+	{key: "orange", hex: "#f97316", primary: true, complement: "blue"},
+	{key: "yellow", hex: "#eab308", primary: true, complement: "violet", challenging: []string{"orange", "gold"}},
+	{key: "green", hex: "#22c55e", primary: true, complement: "red", challenging: []string{"pink", "white"}},
+	{key: "blue", hex: "#3b82f6", primary: true, complement: "orange", challenging: []string{"indigo", "violet"}},
+	{key: "indigo", hex: "#6366f1", primary: true, complement: "gold"},
+	{key: "violet", hex: "#8b5cf6", primary: true, complement: "yellow"},
+	{key: "white", hex: "#f8fafc", primary: true, secondary: true, unlock: 14, complement: "pink"},
+	{key: "gold", hex: "#f59e0b", primary: true, secondary: true, unlock: 7, complement: "indigo"},
+	{key: "pink", hex: "#ec4899", primary: true, complement: "white"},
+	{key: "silver", hex: "#cbd5e1", secondary: true, unlock: 3},
+	{key: "black", hex: "#111827", secondary: true},
+	{key: "grey", hex: "#9ca3af", secondary: true},
+	{key: "rainbow", hex: "#f472b6", unlock: 21, chance: 0.12, style: CardStylePrism},
+	{key: "cosmic", hex: "#4c1d95", unlock: 30, chance: 0.07, style: CardStyleNebula},
+	{key: "celestial", hex: "#67e8f9", unlock: 50, chance: 0.04, style: CardStyleHalo},
+}
+
+func defaultColorDefinitions() []models.ColorDefinition {
+	langs := i18n.Supported()
+	colors := make([]models.ColorDefinition, 0, len(defaultColorSpecs))
+	for _, spec := range defaultColorSpecs {
+		c := models.ColorDefinition{
+			Key:          spec.key,
+			Hex:          spec.hex,
+			Primary:      spec.primary,
+			Secondary:    spec.secondary,
+			UnlockStreak: spec.unlock,
+			ScanChance:   spec.chance,
+			CardStyle:    spec.style,
+			Complement:   spec.complement,
+			Challenging:  append([]string(nil), spec.challenging...),
+			Names:        make(map[string]string, len(langs)),
+		}
+		rare := isRareColor(c)
+		if spec.primary || rare {
+			c.MatchDetail = make(map[string]string, len(langs))
+		}
+		if spec.primary || spec.secondary || rare {
+			c.Traits = make(map[string]models.ColorTraits, len(langs))
+		}
+		for _, lang := range langs {
+			l := i18n.For(lang)
+			c.Names[lang] = l.T("colors." + spec.key)
+			if spec.primary || rare {
+				c.MatchDetail[lang] = l.T("match.detail." + spec.key)
+			}
+			if !spec.primary && !spec.secondary && !rare {
+				continue
+			}
+			c.Traits[lang] = models.ColorTraits{
+				Personality: l.T("traits." + spec.key + ".personality"),
+				Strengths:   l.List("traits." + spec.key + ".strengths"),
+				Challenges:  l.List("traits." + spec.key + ".challenges"),
+				DailyAdvice: l.T("traits." + spec.key + ".daily_advice"),
+			}
+		}
+		colors = append(colors, c)
+	}
+	return colors
+}
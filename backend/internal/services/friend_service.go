@@ -0,0 +1,301 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Friendship statuses.
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+)
+
+// maxPendingFriendRequests caps how many unanswered requests a user can have out at once.
+const maxPendingFriendRequests = 50
+
+var (
+	ErrSelfFriendRequest     = errors.New("cannot send a friend request to yourself")
+	ErrFriendUserNotFound    = errors.New("user not found")
+	ErrFriendRequestExists   = errors.New("friend request already pending")
+	ErrAlreadyFriends        = errors.New("already friends")
+	ErrTooManyFriendRequests = errors.New("too many pending friend requests")
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrNotFriends            = errors.New("not friends")
+)
+
+// FriendService manages friend requests and the friendships they become. A block in
+// either direction hides the two users from each other: requests between them fail as if
+// the other user did not exist, and blocking ends any friendship or request they had.
+type FriendService struct {
+	db *gorm.DB
+}
+
+func NewFriendService(db *gorm.DB) *FriendService {
+	return &FriendService{db: db}
+}
+
+// SendRequest asks addresseeID to be friends. If they had already asked the user, their
+// request is accepted instead and the returned friendship is accepted.
+func (s *FriendService) SendRequest(userID, addresseeID uuid.UUID) (*models.Friendship, error) {
+	if userID == addresseeID {
+		return nil, ErrSelfFriendRequest
+	}
+
+	var friendship *models.Friendship
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockFriendPair(tx, userID, addresseeID); err != nil {
+			return err
+		}
+		var exists int64
+		if err := tx.Model(&models.User{}).Where("id = ?", addresseeID).Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists == 0 {
+			return ErrFriendUserNotFound
+		}
+		if blocked, err := blockedBetween(tx, userID, addresseeID); err != nil {
+			return err
+		} else if blocked {
+			return ErrFriendUserNotFound
+		}
+
+		existing, err := findFriendship(tx, userID, addresseeID)
+		if err != nil {
+			return err
+		}
+		if accept, err := friendRequestOutcome(existing, userID); err != nil {
+			return err
+		} else if accept {
+			friendship = existing
+			return acceptFriendship(tx, friendship)
+		}
+
+		var pending int64
+		if err := tx.Model(&models.Friendship{}).
+			Where("requester_id = ? AND status = ?", userID, FriendshipPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending >= maxPendingFriendRequests {
+			return ErrTooManyFriendRequests
+		}
+
+		friendship = &models.Friendship{
+			ID:          uuid.New(),
+			RequesterID: userID,
+			AddresseeID: addresseeID,
+			Status:      FriendshipPending,
+		}
+		return tx.Create(friendship).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return friendship, nil
+}
+
+// friendRequestOutcome decides what a request from userID does given the friendship
+// already between the pair: accept is true when it answers the other user's pending
+// request, and both are zero when a new request should be created.
+func friendRequestOutcome(existing *models.Friendship, userID uuid.UUID) (accept bool, err error) {
+	switch {
+	case existing == nil:
+		return false, nil
+	case existing.Status == FriendshipAccepted:
+		return false, ErrAlreadyFriends
+	case existing.RequesterID == userID:
+		return false, ErrFriendRequestExists
+	default:
+		return true, nil
+	}
+}
+
+// Accept accepts a pending request sent to the user.
+func (s *FriendService) Accept(userID, requestID uuid.UUID) (*models.Friendship, error) {
+	var friendship models.Friendship
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND addressee_id = ? AND status = ?", requestID, userID, FriendshipPending).
+			First(&friendship).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFriendRequestNotFound
+			}
+			return err
+		}
+		return acceptFriendship(tx, &friendship)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+// Decline turns down a pending request sent to the user. The requester is not told; the
+// request simply stops being pending.
+func (s *FriendService) Decline(userID, requestID uuid.UUID) error {
+	return s.deletePending(requestID, "addressee_id = ?", userID)
+}
+
+// Cancel withdraws a pending request the user sent.
+func (s *FriendService) Cancel(userID, requestID uuid.UUID) error {
+	return s.deletePending(requestID, "requester_id = ?", userID)
+}
+
+func (s *FriendService) deletePending(requestID uuid.UUID, owner string, userID uuid.UUID) error {
+	result := s.db.Where("id = ? AND status = ?", requestID, FriendshipPending).
+		Where(owner, userID).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// Remove ends a friendship. Matches already made stay in each user's history.
+func (s *FriendService) Remove(userID, friendID uuid.UUID) error {
+	result := friendshipBetween(s.db, userID, friendID).
+		Where("status = ?", FriendshipAccepted).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+// List returns the user's friends, most recent first.
+func (s *FriendService) List(userID uuid.UUID) ([]dto.FriendResponse, error) {
+	var friendships []models.Friendship
+	if err := s.db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, FriendshipAccepted).
+		Order("accepted_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+
+	friends := make([]dto.FriendResponse, 0, len(friendships))
+	for _, f := range friendships {
+		since := f.CreatedAt
+		if f.AcceptedAt != nil {
+			since = *f.AcceptedAt
+		}
+		friends = append(friends, dto.FriendResponse{UserID: otherFriend(f, userID), FriendsSince: since})
+	}
+	return friends, nil
+}
+
+// Requests returns the user's pending requests, received and sent, newest first.
+func (s *FriendService) Requests(userID uuid.UUID) (*dto.FriendRequestsResponse, error) {
+	var pending []models.Friendship
+	if err := s.db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, FriendshipPending).
+		Order("created_at DESC").
+		Find(&pending).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dto.FriendRequestsResponse{
+		Incoming: []dto.FriendRequestResponse{},
+		Outgoing: []dto.FriendRequestResponse{},
+	}
+	for _, f := range pending {
+		item := friendRequestResponse(f, userID)
+		if f.AddresseeID == userID {
+			response.Incoming = append(response.Incoming, item)
+		} else {
+			response.Outgoing = append(response.Outgoing, item)
+		}
+	}
+	return response, nil
+}
+
+// AreFriends reports whether the two users are accepted friends with no block between
+// them in either direction.
+func (s *FriendService) AreFriends(a, b uuid.UUID) (bool, error) {
+	friendship, err := findFriendship(s.db, a, b)
+	if err != nil || friendship == nil {
+		return false, err
+	}
+	blocked, err := blockedBetween(s.db, a, b)
+	if err != nil {
+		return false, err
+	}
+	return friendsUnblocked(friendship, blocked), nil
+}
+
+// friendsUnblocked reports whether a friendship counts for features between friends:
+// the request was accepted and there is no block between the two users.
+func friendsUnblocked(friendship *models.Friendship, blocked bool) bool {
+	return friendship != nil && friendship.Status == FriendshipAccepted && !blocked
+}
+
+func friendRequestResponse(f models.Friendship, userID uuid.UUID) dto.FriendRequestResponse {
+	return dto.FriendRequestResponse{
+		ID:        f.ID,
+		UserID:    otherFriend(f, userID),
+		Status:    f.Status,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func otherFriend(f models.Friendship, userID uuid.UUID) uuid.UUID {
+	if f.RequesterID == userID {
+		return f.AddresseeID
+	}
+	return f.RequesterID
+}
+
+// friendshipBetween scopes a query to the friendship row between a and b, whichever of
+// them sent the request.
+func friendshipBetween(db *gorm.DB, a, b uuid.UUID) *gorm.DB {
+	return db.Where("((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?))", a, b, b, a)
+}
+
+// lockFriendPair serializes transactions that create a friendship between a and b, in
+// either direction, until the transaction ends. Without it, requests sent both ways at
+// once would each find no row and insert one.
+func lockFriendPair(tx *gorm.DB, a, b uuid.UUID) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", friendPairKey(a, b)).Error
+}
+
+// friendPairKey names the pair the same way whichever user comes first.
+func friendPairKey(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return "friendship:" + a.String() + ":" + b.String()
+}
+
+func findFriendship(tx *gorm.DB, a, b uuid.UUID) (*models.Friendship, error) {
+	var friendship models.Friendship
+	if err := friendshipBetween(tx, a, b).First(&friendship).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+func acceptFriendship(tx *gorm.DB, friendship *models.Friendship) error {
+	now := time.Now()
+	friendship.Status = FriendshipAccepted
+	friendship.AcceptedAt = &now
+	return tx.Model(friendship).Updates(map[string]interface{}{
+		"status":      FriendshipAccepted,
+		"accepted_at": now,
+	}).Error
+}
+
+// endFriendship deletes any friendship or pending request between a and b.
+func endFriendship(tx *gorm.DB, a, b uuid.UUID) error {
+	return friendshipBetween(tx, a, b).Delete(&models.Friendship{}).Error
+}
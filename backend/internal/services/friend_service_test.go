@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestFriendRequestResponseShowsTheOtherUser(t *testing.T) {
+	requester, addressee := uuid.New(), uuid.New()
+	f := models.Friendship{ID: uuid.New(), RequesterID: requester, AddresseeID: addressee, Status: FriendshipPending}
+
+	if got := friendRequestResponse(f, requester); got.UserID != addressee || got.ID != f.ID {
+		t.Errorf("outgoing request = %+v", got)
+	}
+	if got := friendRequestResponse(f, addressee); got.UserID != requester || got.Status != FriendshipPending {
+		t.Errorf("incoming request = %+v", got)
+	}
+}
+
+func TestFriendRequestOutcome(t *testing.T) {
+	user, other := uuid.New(), uuid.New()
+	outgoing := &models.Friendship{RequesterID: user, AddresseeID: other, Status: FriendshipPending}
+	incoming := &models.Friendship{RequesterID: other, AddresseeID: user, Status: FriendshipPending}
+	accepted := &models.Friendship{RequesterID: other, AddresseeID: user, Status: FriendshipAccepted}
+
+	for name, tc := range map[string]struct {
+		existing   *models.Friendship
+		wantAccept bool
+		wantErr    error
+	}{
+		"none":     {nil, false, nil},
+		"outgoing": {outgoing, false, ErrFriendRequestExists},
+		"incoming": {incoming, true, nil},
+		"accepted": {accepted, false, ErrAlreadyFriends},
+	} {
+		accept, err := friendRequestOutcome(tc.existing, user)
+		if accept != tc.wantAccept || !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: accept=%v err=%v, want %v %v", name, accept, err, tc.wantAccept, tc.wantErr)
+		}
+	}
+}
+
+func TestFriendsUnblocked(t *testing.T) {
+	pending := &models.Friendship{Status: FriendshipPending}
+	accepted := &models.Friendship{Status: FriendshipAccepted}
+	if !friendsUnblocked(accepted, false) {
+		t.Error("accepted friends without a block refused")
+	}
+	if friendsUnblocked(accepted, true) {
+		t.Error("blocked friends allowed")
+	}
+	if friendsUnblocked(pending, false) {
+		t.Error("pending request counted as a friendship")
+	}
+	if friendsUnblocked(nil, false) {
+		t.Error("strangers counted as friends")
+	}
+}
+
+func TestFriendPairKeyIgnoresDirection(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	if friendPairKey(a, b) != friendPairKey(b, a) {
+		t.Error("pair key depends on who sent the request")
+	}
+	if friendPairKey(a, b) == friendPairKey(a, uuid.New()) {
+		t.Error("different pairs share a key")
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"image"
+	"math/bits"
+)
+
+// Duplicate photo policies, see config.AuraDuplicatePolicy.
+const (
+	DuplicatePolicyReuse = "reuse"
+	DuplicatePolicyFlag  = "flag"
+	DuplicatePolicyOff   = "off"
+)
+
+const (
+	defaultDuplicateThreshold = 6
+	// maxDuplicateCandidates bounds how many recent readings a scan is compared against.
+	maxDuplicateCandidates = 200
+	// dHashSamples is the sampling grid per hash cell; the average of a cell is what the
+	// hash compares, so re-encoding and mild resizing leave it unchanged.
+	dHashSamples = 8
+)
+
+// dHash is a 64-bit difference hash: the photo is shrunk to 9x8 grey cells and each bit
+// records whether a cell is brighter than its right neighbour. It survives re-compression,
+// resizing and small exposure changes, so near-identical uploads differ by only a few bits.
+func dHash(img image.Image) uint64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	var cells [8][9]float64
+	for cy := 0; cy < 8; cy++ {
+		for cx := 0; cx < 9; cx++ {
+			var sum float64
+			for sy := 0; sy < dHashSamples; sy++ {
+				y := b.Min.Y + ((cy*dHashSamples+sy)*h+h/2)/(8*dHashSamples)
+				for sx := 0; sx < dHashSamples; sx++ {
+					x := b.Min.X + ((cx*dHashSamples+sx)*w+w/2)/(9*dHashSamples)
+					r, g, bl, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+				}
+			}
+			cells[cy][cx] = sum
+		}
+	}
+
+	var hash uint64
+	for cy := 0; cy < 8; cy++ {
+		for cx := 0; cx < 8; cx++ {
+			hash <<= 1
+			if cells[cy][cx] > cells[cy][cx+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// hashDistance is the number of differing bits between two hashes.
+func hashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
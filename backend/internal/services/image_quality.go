@@ -0,0 +1,187 @@
+package services
+
+import (
+	"math"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+)
+
+// QualityIssueCode matches the codes produced by the mobile app's lib/imageQualityGate.ts so
+// clients can reuse their existing messaging. The face-related codes are only produced on
+// device; the server gate checks resolution, exposure, contrast and sharpness.
+type QualityIssueCode string
+
+const (
+	QualityLowResolution QualityIssueCode = "LOW_RESOLUTION"
+	QualityNoFace        QualityIssueCode = "NO_FACE"
+	QualityMultipleFaces QualityIssueCode = "MULTIPLE_FACES"
+	QualityFaceTooFar    QualityIssueCode = "FACE_TOO_FAR"
+	QualityFaceTooClose  QualityIssueCode = "FACE_TOO_CLOSE"
+	QualityHeadAngle     QualityIssueCode = "HEAD_ANGLE"
+	QualityLowLight      QualityIssueCode = "LOW_LIGHT"
+	QualityOverexposed   QualityIssueCode = "OVEREXPOSED"
+	QualityLowContrast   QualityIssueCode = "LOW_CONTRAST"
+	QualityBlurry        QualityIssueCode = "BLURRY"
+)
+
+// Thresholds and penalties are kept identical to the mobile gate.
+const (
+	qualityMinShortSide  = 480
+	qualityMinBrightness = 52
+	qualityMaxBrightness = 210
+	qualityMinContrast   = 17
+	qualityMinBlur       = 70
+	qualityAnalysisWidth = 192
+	qualityPenaltyLowRes = 20
+	qualityPenaltyDark   = 14
+	qualityPenaltyBright = 10
+	qualityPenaltyFlat   = 9
+	qualityPenaltyBlurry = 18
+)
+
+// qualityMessage is the guidance shown for an issue code. Results carry the default
+// language; handlers re-translate from the issue codes for each request.
+func qualityMessage(locale *i18n.Localizer, code QualityIssueCode) string {
+	return locale.T("quality." + string(code))
+}
+
+// ImageQualityMetrics are the raw measurements behind a quality verdict.
+type ImageQualityMetrics struct {
+	Brightness   float64 `json:"brightness"`
+	Contrast     float64 `json:"contrast"`
+	BlurVariance float64 `json:"blur_variance"`
+	ShortSide    int     `json:"short_side"`
+}
+
+// ImageQualityResult is the server-side equivalent of the mobile QualityGateResult.
+type ImageQualityResult struct {
+	OK      bool                `json:"ok"`
+	Score   int                 `json:"score"`
+	Issues  []QualityIssueCode  `json:"issues"`
+	Message string              `json:"message"`
+	Metrics ImageQualityMetrics `json:"metrics"`
+}
+
+// ImageQualityError rejects a scan photo before it is analyzed or counted against quota.
+type ImageQualityError struct {
+	Result ImageQualityResult
+}
+
+func (e *ImageQualityError) Error() string {
+	return e.Result.Message
+}
+
+// evaluateImageQuality measures the photo on a 192px-wide copy, the same size the mobile
+// app analyzes, so both gates agree on borderline photos.
+func evaluateImageQuality(img *auraImage) ImageQualityResult {
+	b := img.img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	shortSide := w
+	if h < shortSide {
+		shortSide = h
+	}
+
+	var issues []QualityIssueCode
+	score := 100
+
+	if shortSide > 0 && shortSide < qualityMinShortSide {
+		issues = append(issues, QualityLowResolution)
+		score -= qualityPenaltyLowRes
+	}
+
+	dw := qualityAnalysisWidth
+	if w < dw {
+		dw = w
+	}
+	dh := h * dw / w
+	mini := resizeImageTo(img.img, dw, dh)
+	brightness, contrast, blurVariance := luminanceMetrics(mini.Pix, mini.Stride, dw, dh)
+
+	if brightness < qualityMinBrightness {
+		issues = append(issues, QualityLowLight)
+		score -= qualityPenaltyDark
+	} else if brightness > qualityMaxBrightness {
+		issues = append(issues, QualityOverexposed)
+		score -= qualityPenaltyBright
+	}
+	if contrast < qualityMinContrast {
+		issues = append(issues, QualityLowContrast)
+		score -= qualityPenaltyFlat
+	}
+	if blurVariance < qualityMinBlur {
+		issues = append(issues, QualityBlurry)
+		score -= qualityPenaltyBlurry
+	}
+
+	score = clamp(score, 0, 100)
+	// Low contrast alone is tolerated, as on device.
+	ok := len(issues) == 0 || (len(issues) == 1 && issues[0] == QualityLowContrast)
+
+	message := qualityMessage(nil, "OK")
+	if len(issues) > 0 {
+		message = qualityMessage(nil, issues[0])
+	}
+	if issues == nil {
+		issues = []QualityIssueCode{}
+	}
+
+	return ImageQualityResult{
+		OK:      ok,
+		Score:   score,
+		Issues:  issues,
+		Message: message,
+		Metrics: ImageQualityMetrics{
+			Brightness:   brightness,
+			Contrast:     contrast,
+			BlurVariance: blurVariance,
+			ShortSide:    shortSide,
+		},
+	}
+}
+
+// luminanceMetrics returns mean luminance, its standard deviation, and the variance of the
+// 4-neighbour Laplacian (a standard sharpness measure) over RGBA pixels.
+func luminanceMetrics(pix []uint8, stride, w, h int) (float64, float64, float64) {
+	size := w * h
+	if size == 0 {
+		return 0, 0, 0
+	}
+
+	lum := make([]float64, size)
+	var sum float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := y*stride + x*4
+			v := 0.2126*float64(pix[p]) + 0.7152*float64(pix[p+1]) + 0.0722*float64(pix[p+2])
+			lum[y*w+x] = v
+			sum += v
+		}
+	}
+
+	mean := sum / float64(size)
+	var varianceSum float64
+	for _, v := range lum {
+		d := v - mean
+		varianceSum += d * d
+	}
+	contrast := math.Sqrt(varianceSum / float64(size))
+
+	var lapSum, lapSqSum, n float64
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			lap := 4*lum[i] - lum[i-1] - lum[i+1] - lum[i-w] - lum[i+w]
+			lapSum += lap
+			lapSqSum += lap * lap
+			n++
+		}
+	}
+
+	var blurVariance float64
+	if n > 0 {
+		lapMean := lapSum / n
+		blurVariance = lapSqSum/n - lapMean*lapMean
+	}
+
+	return mean, contrast, blurVariance
+}
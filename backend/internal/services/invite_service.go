@@ -0,0 +1,362 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Invite codes use letters and digits that cannot be mistaken for each other when read
+// aloud or copied by hand: no 0/O and no 1/I/L. Eight of them give about 8.5e11 codes.
+const (
+	inviteCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 8
+)
+
+const (
+	defaultInviteExpiryDays = 7
+	MaxInviteExpiryDays     = 30
+	MaxInviteUses           = 100
+	maxActiveInvites        = 20
+	maxListedInvites        = 100
+	defaultRedeemFailures   = 10
+	defaultRedeemWindow     = time.Hour
+)
+
+var (
+	ErrInvalidInviteUses   = errors.New("max_uses out of range")
+	ErrInvalidInviteExpiry = errors.New("expires_in_days out of range")
+	ErrTooManyInvites      = errors.New("too many active invites")
+	ErrInviteNotFound      = errors.New("invite not found")
+	ErrOwnInvite           = errors.New("cannot redeem your own invite")
+	ErrInviteRateLimited   = errors.New("too many failed invite redemptions")
+)
+
+// InviteService mints friend invite codes and redeems them into friendships. Redeeming
+// a code that is unknown, expired, revoked, used up, or belongs to someone with a block
+// between the two users fails the same way, so a failure says nothing about which codes
+// exist, and repeated failures lock the caller out for a while.
+type InviteService struct {
+	db *gorm.DB
+	// failures counts failed redemptions per user and per client IP.
+	failures *windowLimiter
+}
+
+func NewInviteService(db *gorm.DB, cfg *config.Config) *InviteService {
+	maxFailures := cfg.InviteRedeemMaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultRedeemFailures
+	}
+	window := cfg.InviteRedeemWindow
+	if window <= 0 {
+		window = defaultRedeemWindow
+	}
+	return &InviteService{
+		db:       db,
+		failures: newWindowLimiter(maxFailures, window),
+	}
+}
+
+// InviteSummary is an invite with the number of its redemptions that came with a signup.
+type InviteSummary struct {
+	Invite  models.FriendInvite
+	Signups int64
+}
+
+// Create mints an invite for the user. MaxUses defaults to 1 and ExpiresInDays to 7.
+func (s *InviteService) Create(userID uuid.UUID, req dto.CreateInviteRequest) (*models.FriendInvite, error) {
+	uses := req.MaxUses
+	if uses == 0 {
+		uses = 1
+	}
+	if uses < 1 || uses > MaxInviteUses {
+		return nil, ErrInvalidInviteUses
+	}
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultInviteExpiryDays
+	}
+	if days < 1 || days > MaxInviteExpiryDays {
+		return nil, ErrInvalidInviteExpiry
+	}
+
+	var active int64
+	if err := activeInvites(s.db.Model(&models.FriendInvite{})).
+		Where("user_id = ?", userID).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if active >= maxActiveInvites {
+		return nil, ErrTooManyInvites
+	}
+
+	code, err := s.unusedCode()
+	if err != nil {
+		return nil, err
+	}
+	invite := &models.FriendInvite{
+		UserID:    userID,
+		Code:      code,
+		MaxUses:   uses,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	if err := s.db.Create(invite).Error; err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// List returns the user's invites, newest first.
+func (s *InviteService) List(userID uuid.UUID) ([]InviteSummary, error) {
+	var invites []models.FriendInvite
+	if err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(maxListedInvites).
+		Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	if len(invites) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(invites))
+	for i, invite := range invites {
+		ids[i] = invite.ID
+	}
+	var counts []struct {
+		InviteID uuid.UUID
+		Signups  int64
+	}
+	if err := s.db.Model(&models.FriendInviteRedemption{}).
+		Select("invite_id, COUNT(*) AS signups").
+		Where("invite_id IN ? AND signup", ids).
+		Group("invite_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	signups := make(map[uuid.UUID]int64, len(counts))
+	for _, c := range counts {
+		signups[c.InviteID] = c.Signups
+	}
+
+	summaries := make([]InviteSummary, len(invites))
+	for i, invite := range invites {
+		summaries[i] = InviteSummary{Invite: invite, Signups: signups[invite.ID]}
+	}
+	return summaries, nil
+}
+
+// Revoke disables one of the user's invites. Revoking a revoked invite is a no-op.
+func (s *InviteService) Revoke(userID, id uuid.UUID) error {
+	var invite models.FriendInvite
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInviteNotFound
+		}
+		return err
+	}
+	if invite.RevokedAt != nil {
+		return nil
+	}
+	return s.db.Model(&invite).Update("revoked_at", time.Now()).Error
+}
+
+// Redeem makes the user friends with the invite's owner. clientIP is counted alongside
+// the user so that switching accounts does not reset the failed-attempt limit.
+func (s *InviteService) Redeem(userID uuid.UUID, code, clientIP string) (*models.Friendship, error) {
+	return s.limitedRedeem(userID, code, clientIP, false)
+}
+
+// AttributeSignup redeems the code a new user signed up with and records that the invite
+// brought them in. A bad code never fails the signup, and the signup response does not
+// say whether the code worked. Failures still count against clientIP, so registering
+// account after account does not get around the redemption limit.
+func (s *InviteService) AttributeSignup(userID uuid.UUID, code, clientIP string) {
+	if strings.TrimSpace(code) == "" {
+		return
+	}
+	if _, err := s.limitedRedeem(userID, code, clientIP, true); err != nil {
+		log.Printf("Invite code not applied to signup %s: %v", userID, err)
+	}
+}
+
+func (s *InviteService) limitedRedeem(userID uuid.UUID, code, clientIP string, signup bool) (*models.Friendship, error) {
+	keys := []string{"user:" + userID.String()}
+	if clientIP != "" {
+		keys = append(keys, "ip:"+clientIP)
+	}
+	now := time.Now()
+	if !s.failures.allow(now, keys...) {
+		return nil, ErrInviteRateLimited
+	}
+
+	friendship, err := s.redeem(userID, code, signup)
+	if countsAsRedeemFailure(err) {
+		s.failures.hit(now, keys...)
+	}
+	return friendship, err
+}
+
+// countsAsRedeemFailure reports whether a redemption outcome counts against the limit.
+// Being told the code is your own or a friend's confirms that it exists as surely as
+// success does, so those count too; database errors say nothing about the code.
+func countsAsRedeemFailure(err error) bool {
+	return errors.Is(err, ErrInviteNotFound) || errors.Is(err, ErrOwnInvite) || errors.Is(err, ErrAlreadyFriends)
+}
+
+func (s *InviteService) redeem(userID uuid.UUID, code string, signup bool) (*models.Friendship, error) {
+	code, ok := NormalizeInviteCode(code)
+	if !ok {
+		return nil, ErrInviteNotFound
+	}
+
+	var friendship *models.Friendship
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var invite models.FriendInvite
+		if err := activeInvites(tx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).
+			First(&invite).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInviteNotFound
+			}
+			return err
+		}
+		if invite.UserID == userID {
+			return ErrOwnInvite
+		}
+		if err := lockFriendPair(tx, userID, invite.UserID); err != nil {
+			return err
+		}
+		if blocked, err := blockedBetween(tx, userID, invite.UserID); err != nil {
+			return err
+		} else if blocked {
+			return ErrInviteNotFound
+		}
+
+		existing, err := findFriendship(tx, userID, invite.UserID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case existing == nil:
+			now := time.Now()
+			friendship = &models.Friendship{
+				ID:          uuid.New(),
+				RequesterID: invite.UserID,
+				AddresseeID: userID,
+				Status:      FriendshipAccepted,
+				AcceptedAt:  &now,
+			}
+			if err := tx.Create(friendship).Error; err != nil {
+				return err
+			}
+		case existing.Status == FriendshipAccepted:
+			return ErrAlreadyFriends
+		default:
+			friendship = existing
+			if err := acceptFriendship(tx, friendship); err != nil {
+				return err
+			}
+		}
+
+		// Someone who redeemed this invite before, then unfriended, does not use it up twice.
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.FriendInviteRedemption{
+			InviteID: invite.ID,
+			UserID:   userID,
+			Signup:   signup,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&invite).Update("uses", gorm.Expr("uses + 1")).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return friendship, nil
+}
+
+// unusedCode draws codes until one is not taken. With 8.5e11 codes a retry is rare.
+func (s *InviteService) unusedCode() (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newInviteCode()
+		if err != nil {
+			return "", err
+		}
+		var taken int64
+		if err := s.db.Model(&models.FriendInvite{}).Where("code = ?", code).Count(&taken).Error; err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to generate an unused invite code")
+}
+
+// activeInvites scopes a query to invites that can still be redeemed.
+func activeInvites(db *gorm.DB) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND expires_at > ? AND uses < max_uses", time.Now())
+}
+
+func newInviteCode() (string, error) {
+	// Bytes at or above the largest multiple of the alphabet size are redrawn, so every
+	// character is equally likely.
+	limit := byte(256 - 256%len(inviteCodeAlphabet))
+	code := make([]byte, 0, inviteCodeLength)
+	buf := make([]byte, inviteCodeLength*2)
+	for len(code) < inviteCodeLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate invite code: %w", err)
+		}
+		for _, b := range buf {
+			if b < limit && len(code) < inviteCodeLength {
+				code = append(code, inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)])
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// NormalizeInviteCode turns what a user typed or pasted into a stored code: case is
+// ignored, and spaces and dashes are dropped. ok is false when the result cannot be a
+// code.
+func NormalizeInviteCode(input string) (code string, ok bool) {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(input) {
+		switch {
+		case r == ' ' || r == '-':
+		case strings.ContainsRune(inviteCodeAlphabet, r):
+			b.WriteRune(r)
+		default:
+			return "", false
+		}
+	}
+	if b.Len() != inviteCodeLength {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// FormatInviteCode splits a code in two halves for display, e.g. ABCD-EFGH.
+func FormatInviteCode(code string) string {
+	if len(code) != inviteCodeLength {
+		return code
+	}
+	half := inviteCodeLength / 2
+	return code[:half] + "-" + code[half:]
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+)
+
+func TestNewInviteCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := newInviteCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if normalized, ok := NormalizeInviteCode(code); !ok || normalized != code {
+			t.Fatalf("code %q does not normalize to itself", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeInviteCode(t *testing.T) {
+	for input, want := range map[string]string{
+		"ABCD-EFGH":   "ABCDEFGH",
+		" abcd efgh ": "ABCDEFGH",
+		"x7k2-m9pq":   "X7K2M9PQ",
+	} {
+		if got, ok := NormalizeInviteCode(input); !ok || got != want {
+			t.Errorf("%q = %q, %v; want %q", input, got, ok, want)
+		}
+	}
+	for _, bad := range []string{"", "ABCDEFG", "ABCDEFGHJ", "ABCD0FGH", "ABCDIFGH", "ABCD_EFGH"} {
+		if got, ok := NormalizeInviteCode(bad); ok {
+			t.Errorf("%q accepted as %q", bad, got)
+		}
+	}
+	if got := FormatInviteCode("ABCDEFGH"); got != "ABCD-EFGH" {
+		t.Errorf("formatted = %q", got)
+	}
+}
+
+func TestFailureLimiter(t *testing.T) {
+	l := NewInviteService(nil, &config.Config{InviteRedeemMaxFailures: 3, InviteRedeemWindow: time.Minute}).failures
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if !l.allow(now, "user:a", "ip:1") {
+			t.Fatalf("refused after %d failures", i)
+		}
+		l.hit(now, "user:a", "ip:1")
+	}
+	if l.allow(now, "user:a") {
+		t.Error("user allowed at the limit")
+	}
+	if l.allow(now, "user:b", "ip:1") {
+		t.Error("another user on the same IP allowed at the limit")
+	}
+	if !l.allow(now, "user:b", "ip:2") {
+		t.Error("unrelated caller refused")
+	}
+	if !l.allow(now.Add(time.Minute+time.Second), "user:a", "ip:1") {
+		t.Error("still refused after the window")
+	}
+}
+
+func TestFailureLimiterDefaults(t *testing.T) {
+	l := NewInviteService(nil, &config.Config{}).failures
+	if l.max != defaultRedeemFailures || l.window != defaultRedeemWindow {
+		t.Errorf("limit = %d per %v, want %d per %v", l.max, l.window, defaultRedeemFailures, defaultRedeemWindow)
+	}
+}
+
+func TestCountsAsRedeemFailure(t *testing.T) {
+	for _, err := range []error{ErrInviteNotFound, ErrOwnInvite, ErrAlreadyFriends, fmt.Errorf("redeem: %w", ErrAlreadyFriends)} {
+		if !countsAsRedeemFailure(err) {
+			t.Errorf("%v not counted", err)
+		}
+	}
+	for _, err := range []error{nil, errors.New("connection reset")} {
+		if countsAsRedeemFailure(err) {
+			t.Errorf("%v counted", err)
+		}
+	}
+}
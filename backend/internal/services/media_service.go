@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	mediaURLPrefix     = "/api/media"
+	thumbnailMaxDim    = 320
+	thumbnailQuality   = 80
+	mediaStoreTimeout  = 15 * time.Second
+	defaultMediaURLTTL = 15 * time.Minute
+)
+
+var errMediaUnavailable = errors.New("blob storage is not configured")
+
+// MediaService stores scan photos and their thumbnails in the blob store and hands out
+// signed, expiring links to them.
+type MediaService struct {
+	store  storage.BlobStore
+	signer *storage.URLSigner
+	ttl    time.Duration
+}
+
+func NewMediaService(store storage.BlobStore, cfg *config.Config) *MediaService {
+	ttl := cfg.MediaURLTTL
+	if ttl <= 0 {
+		ttl = defaultMediaURLTTL
+	}
+	return &MediaService{
+		store:  store,
+		signer: storage.NewURLSigner(cfg.MediaSigningKey, cfg.PublicBaseURL, mediaURLPrefix),
+		ttl:    ttl,
+	}
+}
+
+func readingImageKeys(userID, readingID uuid.UUID, format string) (string, string) {
+	ext := "jpg"
+	if format == "png" {
+		ext = "png"
+	}
+	prefix := fmt.Sprintf("readings/%s/%s/", userID, readingID)
+	return prefix + "original." + ext, prefix + "thumb.jpg"
+}
+
+// StoreReadingImage saves the original upload and a JPEG thumbnail for a reading.
+func (m *MediaService) StoreReadingImage(userID, readingID uuid.UUID, img *auraImage) (string, string, error) {
+	if m == nil || img == nil {
+		return "", "", nil
+	}
+
+	imageKey, thumbKey := readingImageKeys(userID, readingID, img.format)
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, resizeImage(img.img, thumbnailMaxDim), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return "", "", fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mediaStoreTimeout)
+	defer cancel()
+
+	if err := m.store.Put(ctx, imageKey, img.raw, img.mimeType()); err != nil {
+		return "", "", fmt.Errorf("failed to store image: %w", err)
+	}
+	if err := m.store.Put(ctx, thumbKey, thumb.Bytes(), "image/jpeg"); err != nil {
+		m.deleteKeys(imageKey)
+		return "", "", fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+
+	return imageKey, thumbKey, nil
+}
+
+// StoreScanInput keeps an accepted async scan photo until a worker processes it.
+func (m *MediaService) StoreScanInput(jobID uuid.UUID, img *auraImage) (string, error) {
+	if m == nil {
+		return "", errMediaUnavailable
+	}
+	ext := "jpg"
+	if img.format == "png" {
+		ext = "png"
+	}
+	key := fmt.Sprintf("jobs/%s/input.%s", jobID, ext)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mediaStoreTimeout)
+	defer cancel()
+	if err := m.store.Put(ctx, key, img.raw, img.mimeType()); err != nil {
+		return "", fmt.Errorf("failed to store scan input: %w", err)
+	}
+	return key, nil
+}
+
+// LoadScanInput returns the photo saved by StoreScanInput.
+func (m *MediaService) LoadScanInput(key string) ([]byte, error) {
+	if m == nil {
+		return nil, errMediaUnavailable
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mediaStoreTimeout)
+	defer cancel()
+	data, _, err := m.store.Get(ctx, key)
+	return data, err
+}
+
+// DeleteScanInput removes jobs' photos once the jobs have finished or been deleted.
+func (m *MediaService) DeleteScanInput(keys ...string) {
+	if m == nil {
+		return
+	}
+	m.deleteKeys(keys...)
+}
+
+// DeleteReadingImages removes a reading's photo and thumbnail. Failures are logged, not
+// returned, so a flaky blob store never blocks a user's deletion request.
+func (m *MediaService) DeleteReadingImages(readings ...models.AuraReading) {
+	if m == nil {
+		return
+	}
+	for _, r := range readings {
+		m.deleteKeys(r.ImageKey, r.ThumbnailKey)
+	}
+}
+
+func (m *MediaService) deleteKeys(keys ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mediaStoreTimeout)
+	defer cancel()
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := m.store.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// Decorate replaces stored image references on a reading with signed links.
+func (m *MediaService) Decorate(reading *models.AuraReading) {
+	if m == nil || reading == nil {
+		return
+	}
+	if reading.ImageKey != "" {
+		reading.ImageURL = m.signer.URL(reading.ImageKey, m.ttl)
+	}
+	if reading.ThumbnailKey != "" {
+		reading.ThumbnailURL = m.signer.URL(reading.ThumbnailKey, m.ttl)
+	}
+}
+
+// Open returns a blob addressed by a signed link, or storage.ErrNotFound when the
+// signature is invalid or expired.
+func (m *MediaService) Open(key, expires, sig string) ([]byte, string, error) {
+	if !m.signer.Verify(key, expires, sig) {
+		return nil, "", storage.ErrNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mediaStoreTimeout)
+	defer cancel()
+	return m.store.Get(ctx, key)
+}
@@ -13,9 +13,12 @@ import (
 )
 
 var (
-	ErrReportNotFound = errors.New("report not found")
-	ErrAlreadyBlocked = errors.New("user already blocked")
-	ErrSelfBlock      = errors.New("cannot block yourself")
+	ErrReportNotFound      = errors.New("report not found")
+	ErrAlreadyBlocked      = errors.New("user already blocked")
+	ErrSelfBlock           = errors.New("cannot block yourself")
+	ErrInvalidContentType  = errors.New("invalid content_type: must be user, post, or comment")
+	ErrReportReasonMissing = errors.New("reason is required")
+	ErrInvalidReportStatus = errors.New("invalid status: must be reviewed, actioned, or dismissed")
 )
 
 // ProfanityPatterns is a basic regex-based content filter (Apple Guideline 1.2).
@@ -59,11 +62,11 @@ func (s *ModerationService) SanitizeContent(text string) string {
 func (s *ModerationService) CreateReport(reporterID uuid.UUID, req *dto.CreateReportRequest) (*models.Report, error) {
 	validTypes := map[string]bool{"user": true, "post": true, "comment": true}
 	if !validTypes[req.ContentType] {
-		return nil, errors.New("invalid content_type: must be user, post, or comment")
+		return nil, ErrInvalidContentType
 	}
 
 	if strings.TrimSpace(req.Reason) == "" {
-		return nil, errors.New("reason is required")
+		return nil, ErrReportReasonMissing
 	}
 
 	report := models.Report{
@@ -103,7 +106,7 @@ func (s *ModerationService) ListReports(status string, limit, offset int) ([]mod
 func (s *ModerationService) ActionReport(reportID uuid.UUID, req *dto.ActionReportRequest) error {
 	validStatuses := map[string]bool{"reviewed": true, "actioned": true, "dismissed": true}
 	if !validStatuses[req.Status] {
-		return errors.New("invalid status: must be reviewed, actioned, or dismissed")
+		return ErrInvalidReportStatus
 	}
 
 	result := s.db.Model(&models.Report{}).
@@ -138,7 +141,15 @@ func (s *ModerationService) BlockUser(blockerID, blockedID uuid.UUID) error {
 		BlockedID: blockedID,
 	}
 
-	return s.db.Create(&block).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&block).Error; err != nil {
+			return err
+		}
+		if err := endFriendship(tx, blockerID, blockedID); err != nil {
+			return err
+		}
+		return revokeSharesForBlock(tx, blockerID, blockedID)
+	})
 }
 
 func (s *ModerationService) UnblockUser(blockerID, blockedID uuid.UUID) error {
@@ -158,3 +169,12 @@ func (s *ModerationService) GetBlockedIDs(userID uuid.UUID) ([]uuid.UUID, error)
 	}
 	return ids, nil
 }
+
+// blockedBetween reports whether either user has blocked the other.
+func blockedBetween(db *gorm.DB, a, b uuid.UUID) (bool, error) {
+	var blocks int64
+	err := db.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&blocks).Error
+	return blocks > 0, err
+}
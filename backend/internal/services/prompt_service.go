@@ -0,0 +1,530 @@
+package services
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Prompt names. Each has an embedded default in prompts/<name>.tmpl.
+const (
+	PromptAnalysis  = "analysis"
+	PromptNarrative = "narrative"
+	PromptMatch     = "match"
+	PromptCompare   = "compare"
+)
+
+const (
+	// builtinPromptVariant names the embedded default; database variants cannot use it.
+	builtinPromptVariant    = "builtin"
+	defaultPromptRefresh    = time.Minute
+	MaxPromptWeight         = 1000
+	defaultPromptResultDays = 30
+	MaxPromptResultDays     = 366
+)
+
+var (
+	ErrUnknownPrompt         = errors.New("unknown prompt")
+	ErrInvalidPromptVariant  = errors.New("variant must be 1-50 lowercase letters, digits, - or _")
+	ErrInvalidPromptWeight   = errors.New("weight out of range")
+	ErrPromptVariantNotFound = errors.New("prompt variant not found")
+	ErrInvalidPromptDays     = errors.New("days out of range")
+)
+
+var promptVariantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+//go:embed prompts/*.tmpl
+var promptFiles embed.FS
+
+// PromptTemplateError is a prompt body that does not parse, misses a block or fails to
+// render the prompt's sample data.
+type PromptTemplateError struct {
+	Problem string
+}
+
+func (e *PromptTemplateError) Error() string {
+	return "invalid prompt template: " + e.Problem
+}
+
+// analysisPromptData feeds the analysis prompt. Photo reports whether Stats were measured
+// from a decoded photo; URL-only scans have just ImageURL.
+type analysisPromptData struct {
+	Colors   []string
+	Baseline auraAnalysisResult
+	Photo    bool
+	Stats    imageFeatures
+	ImageURL string
+}
+
+// narrativePromptData feeds the narrative prompt. Reading, ColorMeaning and
+// RecentReadings are JSON documents.
+type narrativePromptData struct {
+	Reading        string
+	ColorMeaning   string
+	RecentReadings string
+	Language       string
+}
+
+type matchPromptPerson struct {
+	AuraColor      string
+	SecondaryColor string
+	EnergyLevel    int
+	MoodScore      int
+	Personality    string
+	Strengths      string
+	Challenges     string
+}
+
+// comparePromptData feeds the compare prompt. First, Second and Changes are JSON.
+type comparePromptData struct {
+	First    string
+	Second   string
+	Changes  string
+	Language string
+}
+
+// matchPromptData feeds the match prompt; A is the user and B the friend.
+type matchPromptData struct {
+	ColorMeanings   string
+	ComplementPairs string
+	Language        string
+	A               matchPromptPerson
+	B               matchPromptPerson
+}
+
+// promptSpecs lists the blocks each prompt must define, the sample data an override has
+// to render before it is saved, and the query comparing what its versions produced.
+// Prompts whose output is not stored have no results query.
+var promptSpecs = map[string]struct {
+	blocks  []string
+	sample  func() interface{}
+	results string
+}{
+	PromptAnalysis: {
+		blocks:  []string{"system", "vision", "text"},
+		results: readingPromptResultsSQL,
+		sample: func() interface{} {
+			return analysisPromptData{
+				Colors:   builtinPalette().PrimaryColors(),
+				Baseline: auraAnalysisResult{AuraColor: "blue", EnergyLevel: 60, MoodScore: 6},
+				Photo:    true,
+				ImageURL: "https://example.com/photo.jpg",
+			}
+		},
+	},
+	PromptNarrative: {
+		blocks:  []string{"system", "user"},
+		results: readingPromptResultsSQL,
+		sample: func() interface{} {
+			return narrativePromptData{Reading: "{}", ColorMeaning: "{}", RecentReadings: "[]", Language: "English"}
+		},
+	},
+	PromptMatch: {
+		blocks:  []string{"system", "user"},
+		results: matchPromptResultsSQL,
+		sample: func() interface{} {
+			palette := builtinPalette()
+			person := matchPromptPerson{AuraColor: "blue", EnergyLevel: 60, MoodScore: 6}
+			return matchPromptData{
+				ColorMeanings:   palette.colorMeanings(),
+				ComplementPairs: palette.complementPairs(),
+				Language:        "English",
+				A:               person,
+				B:               person,
+			}
+		},
+	},
+	PromptCompare: {
+		blocks: []string{"system", "user"},
+		sample: func() interface{} {
+			return comparePromptData{First: "{}", Second: "{}", Changes: "{}", Language: "English"}
+		},
+	},
+}
+
+// promptVariant is a parsed prompt template: the embedded default or a database version.
+type promptVariant struct {
+	name    string
+	variant string
+	version int
+	weight  int
+	tmpl    *template.Template
+}
+
+// ID identifies the exact template text, e.g. "narrative/builtin" or "narrative/warm@3".
+func (p *promptVariant) ID() string {
+	if p.variant == builtinPromptVariant {
+		return p.name + "/" + builtinPromptVariant
+	}
+	return fmt.Sprintf("%s/%s@%d", p.name, p.variant, p.version)
+}
+
+func (p *promptVariant) render(block string, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := p.tmpl.ExecuteTemplate(&b, block, data); err != nil {
+		return "", fmt.Errorf("prompt %s: %w", p.ID(), err)
+	}
+	return b.String(), nil
+}
+
+// parsePrompt parses a prompt body and checks it against the prompt's spec.
+func parsePrompt(name, body string) (*template.Template, error) {
+	spec, ok := promptSpecs[name]
+	if !ok {
+		return nil, ErrUnknownPrompt
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, &PromptTemplateError{Problem: err.Error()}
+	}
+	sample := spec.sample()
+	for _, block := range spec.blocks {
+		if tmpl.Lookup(block) == nil {
+			return nil, &PromptTemplateError{Problem: fmt.Sprintf("missing {{define %q}} block", block)}
+		}
+		var b strings.Builder
+		if err := tmpl.ExecuteTemplate(&b, block, sample); err != nil {
+			return nil, &PromptTemplateError{Problem: err.Error()}
+		}
+		if strings.TrimSpace(b.String()) == "" {
+			return nil, &PromptTemplateError{Problem: fmt.Sprintf("block %q renders empty", block)}
+		}
+	}
+	return tmpl, nil
+}
+
+func builtinPromptBody(name string) string {
+	body, err := promptFiles.ReadFile("prompts/" + name + ".tmpl")
+	if err != nil {
+		panic(fmt.Sprintf("embedded prompt %s: %v", name, err))
+	}
+	return string(body)
+}
+
+var builtinPrompts = sync.OnceValue(func() map[string]*promptVariant {
+	prompts := make(map[string]*promptVariant, len(promptSpecs))
+	for name := range promptSpecs {
+		tmpl, err := parsePrompt(name, builtinPromptBody(name))
+		if err != nil {
+			panic(fmt.Sprintf("embedded prompt %s: %v", name, err))
+		}
+		prompts[name] = &promptVariant{name: name, variant: builtinPromptVariant, weight: 1, tmpl: tmpl}
+	}
+	return prompts
+})
+
+// resolvePrompt returns p, or the embedded default for name when p is nil.
+func resolvePrompt(p *promptVariant, name string) *promptVariant {
+	if p != nil {
+		return p
+	}
+	return builtinPrompts()[name]
+}
+
+// PromptService serves prompt templates: the embedded defaults, replaced by any active
+// database variants. When a prompt has several active variants, each user is assigned
+// one by weight from a hash of their ID, so they keep seeing the same variant while the
+// experiment runs. Variants are reloaded periodically so every instance picks up edits.
+type PromptService struct {
+	db      *gorm.DB
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	variants map[string][]*promptVariant
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func NewPromptService(db *gorm.DB, cfg *config.Config) *PromptService {
+	s := &PromptService{db: db, refresh: cfg.PromptRefresh}
+	if s.refresh <= 0 {
+		s.refresh = defaultPromptRefresh
+	}
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to load prompt templates, using embedded defaults: %v", err)
+	}
+	return s
+}
+
+// Reload reads the active variants. A stored variant that no longer parses is skipped so
+// one bad row cannot take a prompt down.
+func (s *PromptService) Reload() error {
+	var rows []models.PromptTemplate
+	if err := s.db.Where("active = ?", true).Order("name, variant").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	variants := make(map[string][]*promptVariant)
+	for _, row := range rows {
+		tmpl, err := parsePrompt(row.Name, row.Body)
+		if err != nil {
+			log.Printf("Skipping prompt %s/%s@%d: %v", row.Name, row.Variant, row.Version, err)
+			continue
+		}
+		variants[row.Name] = append(variants[row.Name], &promptVariant{
+			name:    row.Name,
+			variant: row.Variant,
+			version: row.Version,
+			weight:  row.Weight,
+			tmpl:    tmpl,
+		})
+	}
+
+	s.mu.Lock()
+	s.variants = variants
+	s.mu.Unlock()
+	return nil
+}
+
+// Start periodically reloads the active variants.
+func (s *PromptService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stop = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Reload(); err != nil {
+					log.Printf("Failed to reload prompt templates: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends the reload loop.
+func (s *PromptService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	s.stop()
+	s.wg.Wait()
+}
+
+// Select returns the prompt variant for a user. It never returns nil.
+func (s *PromptService) Select(name string, userID uuid.UUID) *promptVariant {
+	if s != nil {
+		s.mu.RLock()
+		variants := s.variants[name]
+		s.mu.RUnlock()
+		if len(variants) > 0 {
+			return assignPromptVariant(name, userID, variants)
+		}
+	}
+	return builtinPrompts()[name]
+}
+
+// assignPromptVariant picks a variant by weight from a hash of the prompt and user, so
+// assignments for different prompts are independent.
+func assignPromptVariant(name string, userID uuid.UUID, variants []*promptVariant) *promptVariant {
+	total := 0
+	for _, v := range variants {
+		total += v.weight
+	}
+	if total <= 0 {
+		return variants[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write(userID[:])
+	n := int(h.Sum32() % uint32(total))
+	for _, v := range variants {
+		if n < v.weight {
+			return v
+		}
+		n -= v.weight
+	}
+	return variants[len(variants)-1]
+}
+
+// --- Admin ---
+
+// List returns every prompt with its embedded default and active variants.
+func (s *PromptService) List() ([]dto.PromptResponse, error) {
+	var rows []models.PromptTemplate
+	if err := s.db.Where("active = ?", true).Order("name, variant").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(promptSpecs))
+	for name := range promptSpecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	prompts := make([]dto.PromptResponse, 0, len(names))
+	for _, name := range names {
+		p := dto.PromptResponse{Name: name, Default: builtinPromptBody(name), Variants: []models.PromptTemplate{}}
+		for _, row := range rows {
+			if row.Name == name {
+				p.Variants = append(p.Variants, row)
+			}
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, nil
+}
+
+// SaveVariant stores a new version of a variant and makes it active in place of the
+// previous one. The body must define the prompt's blocks and render its sample data.
+func (s *PromptService) SaveVariant(name, variant string, req dto.SavePromptVariantRequest) (*models.PromptTemplate, error) {
+	if _, ok := promptSpecs[name]; !ok {
+		return nil, ErrUnknownPrompt
+	}
+	if !promptVariantPattern.MatchString(variant) || variant == builtinPromptVariant {
+		return nil, ErrInvalidPromptVariant
+	}
+	weight := req.Weight
+	if weight == 0 {
+		weight = 1
+	}
+	if weight < 1 || weight > MaxPromptWeight {
+		return nil, ErrInvalidPromptWeight
+	}
+	if _, err := parsePrompt(name, req.Body); err != nil {
+		return nil, err
+	}
+
+	row := &models.PromptTemplate{Name: name, Variant: variant, Body: req.Body, Weight: weight, Active: true, Note: req.Note}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.PromptTemplate{}).
+			Where("name = ? AND variant = ?", name, variant).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		row.Version = latest + 1
+		if err := tx.Model(&models.PromptTemplate{}).
+			Where("name = ? AND variant = ? AND active = ?", name, variant, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(row).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reloadAfterEdit()
+	return row, nil
+}
+
+// DeactivateVariant takes a variant out of rotation. Once no variants are active the
+// embedded default is used again.
+func (s *PromptService) DeactivateVariant(name, variant string) error {
+	if _, ok := promptSpecs[name]; !ok {
+		return ErrUnknownPrompt
+	}
+	res := s.db.Model(&models.PromptTemplate{}).
+		Where("name = ? AND variant = ? AND active = ?", name, variant, true).
+		Update("active", false)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPromptVariantNotFound
+	}
+	s.reloadAfterEdit()
+	return nil
+}
+
+// reloadAfterEdit applies an edit on this instance right away; others pick it up on
+// their next refresh.
+func (s *PromptService) reloadAfterEdit() {
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to reload prompt templates: %v", err)
+	}
+}
+
+const readingPromptResultsSQL = `
+SELECT r.prompt_versions->>@name AS version,
+	COUNT(*) AS results,
+	COUNT(DISTINCT r.user_id) AS users,
+	COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM share_links s WHERE s.reading_id = r.id)) AS shared,
+	AVG(r.energy_level)::float8 AS avg_energy,
+	AVG(r.mood_score)::float8 AS avg_mood
+FROM aura_readings r
+WHERE r.prompt_versions->>@name IS NOT NULL AND r.created_at >= @since AND r.deleted_at IS NULL
+GROUP BY 1
+ORDER BY 1`
+
+const matchPromptResultsSQL = `
+SELECT m.prompt_version AS version,
+	COUNT(*) AS results,
+	COUNT(DISTINCT m.user_id) AS users,
+	COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM share_links s WHERE s.match_id = m.id)) AS shared,
+	AVG(m.compatibility_score)::float8 AS avg_score
+FROM aura_matches m
+WHERE m.prompt_version IS NOT NULL AND m.prompt_version <> '' AND m.created_at >= @since
+GROUP BY 1
+ORDER BY 1`
+
+type promptResultRow struct {
+	Version   string
+	Results   int64
+	Users     int64
+	Shared    int64
+	AvgEnergy *float64
+	AvgMood   *float64
+	AvgScore  *float64
+}
+
+// Results compares the readings or matches each version of a prompt produced over the
+// last `days` days. Only AI-written results are stamped, so fallbacks are not counted.
+func (s *PromptService) Results(name string, days int) (*dto.PromptResultsResponse, error) {
+	spec, ok := promptSpecs[name]
+	if !ok {
+		return nil, ErrUnknownPrompt
+	}
+	if days == 0 {
+		days = defaultPromptResultDays
+	}
+	if days < 1 || days > MaxPromptResultDays {
+		return nil, ErrInvalidPromptDays
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	if spec.results == "" {
+		return &dto.PromptResultsResponse{Name: name, Days: days, Versions: []dto.PromptVersionResult{}}, nil
+	}
+	var rows []promptResultRow
+	if err := s.db.Raw(spec.results, map[string]interface{}{"name": name, "since": since}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	resp := &dto.PromptResultsResponse{Name: name, Days: days, Versions: make([]dto.PromptVersionResult, len(rows))}
+	for i, r := range rows {
+		resp.Versions[i] = dto.PromptVersionResult{
+			Version:       r.Version,
+			Results:       r.Results,
+			Users:         r.Users,
+			Shared:        r.Shared,
+			AverageEnergy: round2(r.AvgEnergy),
+			AverageMood:   round2(r.AvgMood),
+			AverageScore:  round2(r.AvgScore),
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBuiltinPromptsRender(t *testing.T) {
+	for name, spec := range promptSpecs {
+		p := builtinPrompts()[name]
+		if p == nil {
+			t.Fatalf("missing builtin prompt %s", name)
+		}
+		if p.ID() != name+"/builtin" {
+			t.Errorf("ID = %q", p.ID())
+		}
+		for _, block := range spec.blocks {
+			out, err := p.render(block, spec.sample())
+			if err != nil || strings.TrimSpace(out) == "" {
+				t.Errorf("%s %s: %q, %v", name, block, out, err)
+			}
+		}
+	}
+}
+
+func TestParsePromptRejectsBadTemplates(t *testing.T) {
+	if _, err := parsePrompt("unknown", `{{define "system"}}x{{end}}`); !errors.Is(err, ErrUnknownPrompt) {
+		t.Errorf("unknown prompt: %v", err)
+	}
+	var problem *PromptTemplateError
+	cases := map[string]string{
+		"syntax":        `{{define "system"}}{{.Reading{{end}}`,
+		"missing block": `{{define "system"}}x{{end}}`,
+		"unknown field": `{{define "system"}}x{{end}}{{define "user"}}{{.Mood}}{{end}}`,
+	}
+	for label, body := range cases {
+		if _, err := parsePrompt(PromptNarrative, body); !errors.As(err, &problem) {
+			t.Errorf("%s: expected PromptTemplateError, got %v", label, err)
+		}
+	}
+	if _, err := parsePrompt(PromptNarrative, `{{define "system"}}x{{end}}{{define "user"}}{{.Language}}{{end}}`); err != nil {
+		t.Errorf("valid body rejected: %v", err)
+	}
+}
+
+func TestAssignPromptVariant(t *testing.T) {
+	control := &promptVariant{name: PromptNarrative, variant: "control", version: 1, weight: 3}
+	warm := &promptVariant{name: PromptNarrative, variant: "warm", version: 2, weight: 1}
+	variants := []*promptVariant{control, warm}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		userID := uuid.New()
+		v := assignPromptVariant(PromptNarrative, userID, variants)
+		if again := assignPromptVariant(PromptNarrative, userID, variants); again != v {
+			t.Fatal("assignment is not stable for a user")
+		}
+		counts[v.variant]++
+	}
+	// A 3:1 split over 4000 users lands near 3000/1000.
+	if counts["control"] < 2700 || counts["control"] > 3300 {
+		t.Errorf("unexpected split: %v", counts)
+	}
+
+	disabled := &promptVariant{name: PromptNarrative, variant: "off", weight: 0}
+	for i := 0; i < 100; i++ {
+		if v := assignPromptVariant(PromptNarrative, uuid.New(), []*promptVariant{disabled, warm}); v != warm {
+			t.Fatalf("zero-weight variant assigned")
+		}
+	}
+}
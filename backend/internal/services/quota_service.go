@@ -0,0 +1,252 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scan reservation statuses.
+const (
+	ReservationReserved  = "reserved"
+	ReservationCommitted = "committed"
+	ReservationRefunded  = "refunded"
+)
+
+var ErrQuotaExceeded = errors.New("daily scan limit reached")
+
+// ScanQuota is a user's allowance for their current local day. Limit and Remaining are
+// config.UnlimitedScans for unlimited plans.
+type ScanQuota struct {
+	Plan      string
+	Limit     int
+	Used      int
+	Remaining int
+	Day       time.Time
+	ResetsAt  time.Time
+}
+
+// QuotaService keeps the scan ledger. Each scan reserves a slot with a single
+// conditional upsert, so concurrent requests can never overshoot the limit, and days
+// roll over at midnight in the user's own timezone. A day never goes back to an earlier
+// date, so changing timezone cannot reopen a used-up day.
+type QuotaService struct {
+	db  *gorm.DB
+	cfg *config.Config
+	now func() time.Time
+}
+
+func NewQuotaService(db *gorm.DB, cfg *config.Config) *QuotaService {
+	return &QuotaService{db: db, cfg: cfg, now: time.Now}
+}
+
+// Status reports the user's plan, limit and usage for today without reserving anything.
+func (q *QuotaService) Status(userID uuid.UUID) (*ScanQuota, error) {
+	plan := q.plan(userID)
+	limit := q.cfg.ScanLimit(plan)
+	day, resetsAt, err := q.today(q.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage models.ScanQuotaDay
+	err = q.db.Where("user_id = ? AND day = ?", userID, day).First(&usage).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	remaining := config.UnlimitedScans
+	if limit != config.UnlimitedScans {
+		remaining = limit - usage.Used
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+
+	return &ScanQuota{
+		Plan:      plan,
+		Limit:     limit,
+		Used:      usage.Used,
+		Remaining: remaining,
+		Day:       day,
+		ResetsAt:  resetsAt,
+	}, nil
+}
+
+// Reserve takes one scan from today's allowance, returning ErrQuotaExceeded when none
+// is left. The reservation must later be committed or refunded.
+func (q *QuotaService) Reserve(userID uuid.UUID) (*models.ScanReservation, error) {
+	plan := q.plan(userID)
+	limit := q.cfg.ScanLimit(plan)
+	if limit == 0 {
+		return nil, ErrQuotaExceeded
+	}
+	if limit == config.UnlimitedScans {
+		limit = math.MaxInt32
+	}
+	reservation := &models.ScanReservation{
+		ID:     uuid.New(),
+		UserID: userID,
+		Plan:   plan,
+		Status: ReservationReserved,
+	}
+
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		day, _, err := q.today(tx, userID)
+		if err != nil {
+			return err
+		}
+		reservation.Day = day
+
+		// The conflict branch only increments while under the limit; when it is skipped
+		// nothing is returned and the reservation is refused.
+		var used []int
+		if err := tx.Raw(`
+			INSERT INTO scan_quota_days (user_id, day, used, updated_at)
+			VALUES (?, ?, 1, NOW())
+			ON CONFLICT (user_id, day) DO UPDATE
+			SET used = scan_quota_days.used + 1, updated_at = NOW()
+			WHERE scan_quota_days.used < ?
+			RETURNING used`, userID, reservation.Day, limit).Scan(&used).Error; err != nil {
+			return err
+		}
+		if len(used) == 0 {
+			return ErrQuotaExceeded
+		}
+		return tx.Create(reservation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// Commit marks a reservation as used by a saved reading.
+func (q *QuotaService) Commit(reservationID, readingID uuid.UUID) error {
+	return q.db.Model(&models.ScanReservation{}).
+		Where("id = ? AND status = ?", reservationID, ReservationReserved).
+		Updates(map[string]interface{}{"status": ReservationCommitted, "reading_id": readingID}).Error
+}
+
+// Refund returns a reservation's scan to the day it was taken from. Refunding twice,
+// or refunding a committed reservation, has no effect.
+func (q *QuotaService) Refund(reservationID uuid.UUID) error {
+	return q.db.Transaction(func(tx *gorm.DB) error {
+		var reservation models.ScanReservation
+		res := tx.Model(&reservation).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND status = ?", reservationID, ReservationReserved).
+			Update("status", ReservationRefunded)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&models.ScanQuotaDay{}).
+			Where("user_id = ? AND day = ?", reservation.UserID, reservation.Day).
+			Updates(map[string]interface{}{"used": gorm.Expr("GREATEST(used - 1, 0)"), "updated_at": q.now()}).Error
+	})
+}
+
+// commit and refund are used where a ledger failure must not fail the scan itself.
+func (q *QuotaService) commit(reservationID *uuid.UUID, readingID uuid.UUID) {
+	if q == nil || reservationID == nil {
+		return
+	}
+	if err := q.Commit(*reservationID, readingID); err != nil {
+		log.Printf("Failed to commit scan reservation %s: %v", *reservationID, err)
+	}
+}
+
+func (q *QuotaService) refund(reservationID *uuid.UUID) {
+	if q == nil || reservationID == nil {
+		return
+	}
+	if err := q.Refund(*reservationID); err != nil {
+		log.Printf("Failed to refund scan reservation %s: %v", *reservationID, err)
+	}
+}
+
+// plan resolves the user's plan from their active subscription. A product ID with its own
+// entry in SCAN_PLAN_LIMITS is used as the plan name; any other active subscription is
+// premium.
+func (q *QuotaService) plan(userID uuid.UUID) string {
+	var sub models.Subscription
+	err := q.db.
+		Where("user_id = ? AND status = ? AND current_period_end > ?", userID, "active", q.now()).
+		Order("current_period_end DESC").
+		First(&sub).Error
+	if err != nil {
+		return config.PlanFree
+	}
+	if sub.ProductID != "" && q.cfg.HasPlan(sub.ProductID) {
+		return sub.ProductID
+	}
+	return config.PlanPremium
+}
+
+// today returns the user's quota day and when it ends, held at their latest quota day
+// when their current timezone puts them on an earlier date.
+func (q *QuotaService) today(db *gorm.DB, userID uuid.UUID) (time.Time, time.Time, error) {
+	var latest []time.Time
+	if err := db.Model(&models.ScanQuotaDay{}).
+		Where("user_id = ?", userID).
+		Order("day DESC").
+		Limit(1).
+		Pluck("day", &latest).Error; err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	var last time.Time
+	if len(latest) > 0 {
+		last = latest[0]
+	}
+	day, resetsAt := currentQuotaDay(q.now(), q.location(userID), last)
+	return day, resetsAt, nil
+}
+
+func (q *QuotaService) location(userID uuid.UUID) *time.Location {
+	var user models.User
+	if err := q.db.Select("timezone").Where("id = ?", userID).First(&user).Error; err != nil {
+		return time.UTC
+	}
+	return userLocation(user.Timezone)
+}
+
+// userLocation loads an IANA timezone name, falling back to UTC.
+func userLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// quotaDay returns the user's local calendar date (as UTC midnight, matching the date
+// column) and the instant their next local day starts.
+func quotaDay(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	y, m, d := local.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
+
+// currentQuotaDay is quotaDay for a user whose latest quota day is latest (zero if they
+// have none). When the user's timezone puts them on an earlier date than latest, for
+// example after moving from UTC+14 to UTC-12, they stay on latest until it ends in the
+// new timezone. Days therefore only move forward, and a user cannot have more of them
+// than the calendar in the easternmost timezone allows.
+func currentQuotaDay(now time.Time, loc *time.Location, latest time.Time) (time.Time, time.Time) {
+	day, resetsAt := quotaDay(now, loc)
+	if day.Before(latest) {
+		y, m, d := latest.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+	return day, resetsAt
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+)
+
+func TestQuotaDayUsesUserTimezone(t *testing.T) {
+	// 22:30 UTC on March 1st is already March 2nd in Istanbul and still March 1st in New York.
+	now := time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC)
+
+	istanbul := userLocation("Europe/Istanbul")
+	day, resetsAt := quotaDay(now, istanbul)
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !day.Equal(want) {
+		t.Fatalf("expected Istanbul day %s, got %s", want, day)
+	}
+	if want := time.Date(2026, 3, 3, 0, 0, 0, 0, istanbul); !resetsAt.Equal(want) {
+		t.Fatalf("expected Istanbul reset %s, got %s", want, resetsAt)
+	}
+
+	day, _ = quotaDay(now, userLocation("America/New_York"))
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !day.Equal(want) {
+		t.Fatalf("expected New York day %s, got %s", want, day)
+	}
+
+	if loc := userLocation("Not/AZone"); loc != time.UTC {
+		t.Fatalf("expected UTC fallback, got %s", loc)
+	}
+}
+
+func TestQuotaDayDoesNotGoBackAfterTimezoneChange(t *testing.T) {
+	kiritimati := userLocation("Pacific/Kiritimati") // UTC+14
+	farWest := userLocation("Etc/GMT+12")            // UTC-12
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Noon UTC is already March 2nd in Kiritimati and only just March 1st in UTC-12.
+	east, _ := currentQuotaDay(now, kiritimati, time.Time{})
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !east.Equal(want) {
+		t.Fatalf("east day = %v, want %v", east, want)
+	}
+
+	// Switching west keeps the used day until it is over in the new timezone.
+	west, resetsAt := currentQuotaDay(now, farWest, east)
+	if !west.Equal(east) {
+		t.Errorf("west day = %v, want %v", west, east)
+	}
+	if want := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC); !resetsAt.Equal(want) {
+		t.Errorf("resets at %v, want %v", resetsAt, want)
+	}
+
+	// Once the new timezone reaches a later date, the day moves on as usual.
+	later := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	if day, _ := currentQuotaDay(later, farWest, east); !day.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("later day = %v", day)
+	}
+}
+
+func TestScanLimitPerPlan(t *testing.T) {
+	cfg := &config.Config{ScanPlanLimits: map[string]int{
+		config.PlanFree:    3,
+		config.PlanPremium: config.UnlimitedScans,
+		"aurasnap_lite":    10,
+	}}
+
+	if got := cfg.ScanLimit(config.PlanFree); got != 3 {
+		t.Fatalf("expected free limit 3, got %d", got)
+	}
+	if got := cfg.ScanLimit(config.PlanPremium); got != config.UnlimitedScans {
+		t.Fatalf("expected unlimited premium, got %d", got)
+	}
+	if got := cfg.ScanLimit("aurasnap_lite"); got != 10 {
+		t.Fatalf("expected product limit 10, got %d", got)
+	}
+	if got := cfg.ScanLimit("unknown"); got != 3 {
+		t.Fatalf("expected unknown plan to fall back to free, got %d", got)
+	}
+	if got := (&config.Config{}).ScanLimit(config.PlanFree); got != 2 {
+		t.Fatalf("expected default free limit 2, got %d", got)
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReadingPurgeInterval = time.Hour
+	readingPurgeBatch           = 200
+)
+
+// ReadingPurgeService hard-deletes readings that have sat in recently deleted for longer
+// than the retention window, together with their photos and the matches built on them.
+type ReadingPurgeService struct {
+	db        *gorm.DB
+	media     *MediaService
+	retention time.Duration
+	interval  time.Duration
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func NewReadingPurgeService(db *gorm.DB, cfg *config.Config, media *MediaService) *ReadingPurgeService {
+	s := &ReadingPurgeService{
+		db:        db,
+		media:     media,
+		retention: cfg.ReadingRetention,
+		interval:  cfg.ReadingPurgeInterval,
+	}
+	if s.retention <= 0 {
+		s.retention = defaultReadingRetention
+	}
+	if s.interval <= 0 {
+		s.interval = defaultReadingPurgeInterval
+	}
+	return s
+}
+
+// Start runs a purge immediately and then on every interval.
+func (s *ReadingPurgeService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stop = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := s.Purge(ctx); err != nil {
+				log.Printf("Failed to purge deleted readings: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d deleted readings", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for an in-progress purge batch to finish.
+func (s *ReadingPurgeService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	s.stop()
+	s.wg.Wait()
+}
+
+// Purge removes expired soft-deleted readings in batches and returns how many were removed.
+func (s *ReadingPurgeService) Purge(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := s.purgeBatch(time.Now().Add(-s.retention))
+		total += n
+		if err != nil || n < readingPurgeBatch {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (s *ReadingPurgeService) purgeBatch(cutoff time.Time) (int, error) {
+	var readings []models.AuraReading
+	if err := s.db.Unscoped().Select("id", "user_id", "image_key", "thumbnail_key").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("deleted_at").
+		Limit(readingPurgeBatch).
+		Find(&readings).Error; err != nil {
+		return 0, err
+	}
+	if len(readings) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uuid.UUID, len(readings))
+	for i, r := range readings {
+		ids[i] = r.ID
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Links to the readings were revoked on delete; drop them with the content.
+		shares := tx.Model(&models.ShareLink{}).Select("id").
+			Where("reading_id IN ? OR match_id IN (SELECT id FROM aura_matches WHERE user_aura_id IN ? OR friend_aura_id IN ?)", ids, ids, ids)
+		if err := tx.Where("share_id IN (?)", shares).Delete(&models.ShareView{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN (?)", shares).Delete(&models.ShareLink{}).Error; err != nil {
+			return err
+		}
+		// A match is meaningless without both readings.
+		if err := tx.Where("user_aura_id IN ? OR friend_aura_id IN ?", ids, ids).
+			Delete(&models.AuraMatch{}).Error; err != nil {
+			return err
+		}
+		// Keep job and quota history, minus the link to a reading that no longer exists.
+		if err := tx.Model(&models.ScanJob{}).Where("reading_id IN ?", ids).
+			Update("reading_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ScanReservation{}).Where("reading_id IN ?", ids).
+			Update("reading_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.AuraReading{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Blobs go only after the rows are gone, so a failed transaction never leaves a
+	// restorable reading without its photo.
+	s.media.DeleteReadingImages(readings...)
+	return len(readings), nil
+}
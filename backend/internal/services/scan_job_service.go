@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scan job statuses.
+const (
+	ScanJobQueued    = "queued"
+	ScanJobRunning   = "running"
+	ScanJobSucceeded = "succeeded"
+	ScanJobFailed    = "failed"
+)
+
+// Scan progress stages reported to pollers and SSE subscribers.
+const (
+	ScanStageQueued    = "queued"
+	ScanStagePreparing = "preparing"
+	ScanStageAnalyzing = "analyzing"
+	ScanStageWriting   = "writing"
+	ScanStageStoring   = "storing"
+	ScanStageSaving    = "saving"
+	ScanStageDone      = "done"
+)
+
+const (
+	defaultScanJobWorkers   = 4
+	defaultScanJobPoll      = 2 * time.Second
+	defaultScanJobLease     = 2 * time.Minute
+	defaultScanJobAttempts  = 3
+	defaultScanJobRetention = 7 * 24 * time.Hour
+	scanJobJanitorInterval  = time.Hour
+	scanJobRetryBase        = 5 * time.Second
+	scanJobRetryMax         = 2 * time.Minute
+	scanJobSubscriberBuffer = 8
+)
+
+var ErrScanJobNotFound = errors.New("scan job not found")
+
+// errScanJobLost stops a worker whose job was reclaimed by another attempt or deleted.
+var errScanJobLost = errors.New("scan job no longer claimed by this attempt")
+
+// ScanJobService runs aura scans in a bounded worker pool. Jobs live in Postgres and
+// their photos in the blob store, so a restart only delays them: a worker claims a
+// job with a lease, and a job whose lease lapses is claimed again by the next worker.
+// Every write a worker makes is fenced by its claim, so a worker that lost the job
+// drops its result instead of overwriting the attempt that replaced it.
+type ScanJobService struct {
+	db           *gorm.DB
+	aura         *AuraService
+	media        *MediaService
+	workers      int
+	pollInterval time.Duration
+	lease        time.Duration
+	maxAttempts  int
+	retention    time.Duration
+
+	wake chan struct{}
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan models.ScanJob]struct{}
+}
+
+func NewScanJobService(db *gorm.DB, cfg *config.Config, aura *AuraService, media *MediaService) *ScanJobService {
+	s := &ScanJobService{
+		db:           db,
+		aura:         aura,
+		media:        media,
+		workers:      cfg.ScanJobWorkers,
+		pollInterval: cfg.ScanJobPollInterval,
+		lease:        cfg.ScanJobLease,
+		maxAttempts:  cfg.ScanJobMaxAttempts,
+		retention:    cfg.ScanJobRetention,
+		subscribers:  make(map[uuid.UUID]map[chan models.ScanJob]struct{}),
+	}
+	if s.workers <= 0 {
+		s.workers = defaultScanJobWorkers
+	}
+	if s.pollInterval <= 0 {
+		s.pollInterval = defaultScanJobPoll
+	}
+	if s.lease <= 0 {
+		s.lease = defaultScanJobLease
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = defaultScanJobAttempts
+	}
+	if s.retention <= 0 {
+		s.retention = defaultScanJobRetention
+	}
+	s.wake = make(chan struct{}, s.workers)
+	return s
+}
+
+// Enqueue validates a JSON scan request the same way AuraService.Create does, so bad or
+// low-quality photos are still rejected immediately, reserves a scan from the user's
+// quota and queues it for the workers. The job remembers the request's language so the
+// reading and any failure message are written in it.
+func (s *ScanJobService) Enqueue(userID uuid.UUID, req dto.CreateAuraRequest, locale *i18n.Localizer) (*models.ScanJob, error) {
+	imageURL, img, err := s.aura.prepareScan(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.enqueue(userID, imageURL, img, locale)
+}
+
+// EnqueueImage queues raw JPEG/PNG bytes from a multipart upload.
+func (s *ScanJobService) EnqueueImage(userID uuid.UUID, raw []byte, locale *i18n.Localizer) (*models.ScanJob, error) {
+	img, err := s.aura.loadScanImage(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.enqueue(userID, "base64_upload", img, locale)
+}
+
+func (s *ScanJobService) enqueue(userID uuid.UUID, imageURL string, img *auraImage, locale *i18n.Localizer) (*models.ScanJob, error) {
+	if reading := s.aura.reuseDuplicate(userID, img); reading != nil {
+		return s.completeWith(userID, imageURL, reading, locale)
+	}
+
+	reservation, err := s.aura.quota.Reserve(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ScanJob{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Status:        ScanJobQueued,
+		Stage:         ScanStageQueued,
+		ImageURL:      imageURL,
+		ReservationID: &reservation.ID,
+		Language:      locale.Lang(),
+	}
+
+	if img != nil {
+		key, err := s.media.StoreScanInput(job.ID, img)
+		if err != nil {
+			s.aura.quota.refund(job.ReservationID)
+			return nil, err
+		}
+		job.InputKey = key
+	}
+
+	if err := s.db.Create(job).Error; err != nil {
+		s.media.DeleteScanInput(job.InputKey)
+		s.aura.quota.refund(job.ReservationID)
+		return nil, err
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// completeWith records a job that finished immediately with an earlier reading, so
+// async clients handle a duplicate photo the same way as any other finished scan. No
+// scan is reserved for it.
+func (s *ScanJobService) completeWith(userID uuid.UUID, imageURL string, reading *models.AuraReading, locale *i18n.Localizer) (*models.ScanJob, error) {
+	now := time.Now()
+	job := &models.ScanJob{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Status:     ScanJobSucceeded,
+		Stage:      ScanStageDone,
+		Progress:   100,
+		ImageURL:   imageURL,
+		ReadingID:  &reading.ID,
+		Language:   locale.Lang(),
+		FinishedAt: &now,
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, err
+	}
+	job.Reading = reading
+	return job, nil
+}
+
+// Get returns a user's job. Finished jobs include the resulting reading.
+func (s *ScanJobService) Get(userID, jobID uuid.UUID) (*models.ScanJob, error) {
+	var job models.ScanJob
+	if err := s.db.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScanJobNotFound
+		}
+		return nil, err
+	}
+	s.attachReading(&job)
+	return &job, nil
+}
+
+func (s *ScanJobService) attachReading(job *models.ScanJob) {
+	if job.Status != ScanJobSucceeded || job.ReadingID == nil {
+		return
+	}
+	if reading, err := s.aura.GetByID(job.UserID, *job.ReadingID); err == nil {
+		job.Reading = reading
+	}
+}
+
+// Subscribe delivers in-process updates for a job until the returned cancel func is
+// called. Updates from other instances are not delivered; callers should also poll Get.
+func (s *ScanJobService) Subscribe(jobID uuid.UUID) (<-chan models.ScanJob, func()) {
+	ch := make(chan models.ScanJob, scanJobSubscriberBuffer)
+
+	s.mu.Lock()
+	if s.subscribers[jobID] == nil {
+		s.subscribers[jobID] = make(map[chan models.ScanJob]struct{})
+	}
+	s.subscribers[jobID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers[jobID], ch)
+		if len(s.subscribers[jobID]) == 0 {
+			delete(s.subscribers, jobID)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *ScanJobService) publish(job models.ScanJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers[job.ID] {
+		select {
+		case ch <- job:
+		default:
+			// Slow subscriber; it will catch up from the database.
+		}
+	}
+}
+
+func (s *ScanJobService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start launches the worker pool and the janitor that removes old finished jobs.
+func (s *ScanJobService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stop = cancel
+
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.worker(ctx)
+	}
+	s.wg.Add(1)
+	go s.janitor(ctx)
+}
+
+// Stop stops claiming new jobs and waits for running ones to finish.
+func (s *ScanJobService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	s.stop()
+	s.wg.Wait()
+}
+
+func (s *ScanJobService) worker(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && s.processNext() {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ScanJobService) processNext() bool {
+	job, err := s.claim()
+	if err != nil {
+		log.Printf("Failed to claim scan job: %v", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	s.run(job)
+	return true
+}
+
+// claim takes the oldest queued job whose retry delay has passed, or a running job whose
+// worker stopped renewing its lease, without blocking on rows other workers are claiming.
+func (s *ScanJobService) claim() (*models.ScanJob, error) {
+	var job models.ScanJob
+	now := time.Now()
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)) OR (status = ? AND lease_expires_at < ?)",
+				ScanJobQueued, now, ScanJobRunning, now).
+			Order("created_at").
+			Limit(1).
+			Find(&job)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		lease := now.Add(s.lease)
+		job.Status = ScanJobRunning
+		job.Stage = ScanStagePreparing
+		job.Progress = 10
+		job.Attempts++
+		job.LeaseExpiresAt = &lease
+		job.NextAttemptAt = nil
+		if job.StartedAt == nil {
+			job.StartedAt = &now
+		}
+		return tx.Model(&models.ScanJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":           job.Status,
+			"stage":            job.Stage,
+			"progress":         job.Progress,
+			"attempts":         job.Attempts,
+			"lease_expires_at": job.LeaseExpiresAt,
+			"next_attempt_at":  nil,
+			"started_at":       job.StartedAt,
+		}).Error
+	})
+	if err != nil || job.ID == uuid.Nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *ScanJobService) run(job *models.ScanJob) {
+	s.publish(*job)
+	locale := i18n.For(job.Language)
+
+	// A previous attempt may have saved the reading just before the server stopped. That
+	// counts even when the job has no attempts left, so its scan is not refunded.
+	if s.readingSaved(job) {
+		s.succeed(job, job.ID)
+		return
+	}
+	if job.Attempts > s.maxAttempts {
+		s.fail(job, locale.T("scan.failed"))
+		return
+	}
+
+	var img *auraImage
+	if job.InputKey != "" {
+		raw, err := s.media.LoadScanInput(job.InputKey)
+		if err != nil {
+			s.retryOrFail(job, err)
+			return
+		}
+		if img, err = loadAuraImage(raw); err != nil {
+			s.fail(job, locale.T("scan.invalid_image"))
+			return
+		}
+	}
+
+	reading, err := s.aura.createReading(job.UserID, job.ImageURL, img, scanOptions{
+		readingID: job.ID,
+		locale:    locale,
+		progress: func(stage string, percent int) error {
+			return s.setProgress(job, stage, percent)
+		},
+	})
+	if errors.Is(err, errScanJobLost) {
+		return
+	}
+	if err != nil {
+		s.retryOrFail(job, err)
+		return
+	}
+	if !s.succeed(job, reading.ID) && !s.jobExists(job) {
+		// The job was deleted with its account while the reading was being saved.
+		s.discardReading(reading)
+	}
+}
+
+// setProgress records a stage change and renews the lease. It returns errScanJobLost
+// once the job is no longer this attempt's.
+func (s *ScanJobService) setProgress(job *models.ScanJob, stage string, percent int) error {
+	lease := time.Now().Add(s.lease)
+	job.Stage = stage
+	job.Progress = percent
+	job.LeaseExpiresAt = &lease
+	if !s.update(job, map[string]interface{}{
+		"stage":            stage,
+		"progress":         percent,
+		"lease_expires_at": job.LeaseExpiresAt,
+	}) {
+		return errScanJobLost
+	}
+	s.publish(*job)
+	return nil
+}
+
+// succeed finishes the job with its reading. It reports false when the job is no longer
+// this attempt's, in which case nothing else is touched.
+func (s *ScanJobService) succeed(job *models.ScanJob, readingID uuid.UUID) bool {
+	now := time.Now()
+	job.Status = ScanJobSucceeded
+	job.Stage = ScanStageDone
+	job.Progress = 100
+	job.ReadingID = &readingID
+	job.Error = ""
+	job.LeaseExpiresAt = nil
+	job.FinishedAt = &now
+	if !s.update(job, map[string]interface{}{
+		"status":           job.Status,
+		"stage":            job.Stage,
+		"progress":         job.Progress,
+		"reading_id":       readingID,
+		"error":            "",
+		"lease_expires_at": nil,
+		"finished_at":      now,
+	}) {
+		return false
+	}
+	s.media.DeleteScanInput(job.InputKey)
+	s.aura.quota.commit(job.ReservationID, readingID)
+
+	s.attachReading(job)
+	s.publish(*job)
+	return true
+}
+
+func (s *ScanJobService) fail(job *models.ScanJob, message string) {
+	now := time.Now()
+	job.Status = ScanJobFailed
+	job.Error = message
+	job.LeaseExpiresAt = nil
+	job.FinishedAt = &now
+	if !s.update(job, map[string]interface{}{
+		"status":           job.Status,
+		"error":            message,
+		"lease_expires_at": nil,
+		"finished_at":      now,
+	}) {
+		return
+	}
+	s.media.DeleteScanInput(job.InputKey)
+	// A scan that never produced a reading gives its quota back.
+	s.aura.quota.refund(job.ReservationID)
+	s.publish(*job)
+}
+
+func (s *ScanJobService) retryOrFail(job *models.ScanJob, err error) {
+	log.Printf("Scan job %s attempt %d failed: %v", job.ID, job.Attempts, err)
+	switch nextScanJobStep(s.readingSaved(job), job.Attempts, s.maxAttempts) {
+	case scanJobSucceed:
+		s.succeed(job, job.ID)
+		return
+	case scanJobFail:
+		s.fail(job, i18n.For(job.Language).T("scan.failed"))
+		return
+	}
+
+	next := time.Now().Add(scanJobBackoff(job.Attempts))
+	job.Status = ScanJobQueued
+	job.Stage = ScanStageQueued
+	job.Progress = 0
+	job.LeaseExpiresAt = nil
+	job.NextAttemptAt = &next
+	if !s.update(job, map[string]interface{}{
+		"status":           job.Status,
+		"stage":            job.Stage,
+		"progress":         0,
+		"lease_expires_at": nil,
+		"next_attempt_at":  next,
+	}) {
+		return
+	}
+	s.publish(*job)
+}
+
+// readingSaved reports whether the job's reading exists. The job ID doubles as the
+// reading ID, so an attempt whose lease lapsed while it kept running and a later attempt
+// cannot both save a reading: the second insert fails on the key.
+func (s *ScanJobService) readingSaved(job *models.ScanJob) bool {
+	var count int64
+	if err := s.db.Model(&models.AuraReading{}).Where("id = ? AND user_id = ?", job.ID, job.UserID).Count(&count).Error; err != nil {
+		log.Printf("Failed to check scan job %s reading: %v", job.ID, err)
+		return false
+	}
+	return count > 0
+}
+
+// jobExists reports whether the job's row is still there. A lookup failure counts as
+// existing, so a database hiccup never deletes a reading.
+func (s *ScanJobService) jobExists(job *models.ScanJob) bool {
+	var count int64
+	if err := s.db.Model(&models.ScanJob{}).Where("id = ?", job.ID).Count(&count).Error; err != nil {
+		log.Printf("Failed to check scan job %s: %v", job.ID, err)
+		return true
+	}
+	return count > 0
+}
+
+// discardReading removes a reading saved for a job that no longer exists, with its photos.
+func (s *ScanJobService) discardReading(reading *models.AuraReading) {
+	if err := s.db.Unscoped().Where("id = ? AND user_id = ?", reading.ID, reading.UserID).
+		Delete(&models.AuraReading{}).Error; err != nil {
+		log.Printf("Failed to discard reading %s of deleted scan job: %v", reading.ID, err)
+		return
+	}
+	s.media.DeleteReadingImages(*reading)
+}
+
+// scanJobStep is what happens to a job after a failed attempt.
+type scanJobStep int
+
+const (
+	scanJobRetry scanJobStep = iota
+	scanJobSucceed
+	scanJobFail
+)
+
+// nextScanJobStep decides how a failed attempt ends. A reading already saved under the
+// job's ID wins over the error, which is then usually the duplicate key from saving the
+// same reading twice; otherwise the job is retried until it has used maxAttempts.
+func nextScanJobStep(readingSaved bool, attempts, maxAttempts int) scanJobStep {
+	switch {
+	case readingSaved:
+		return scanJobSucceed
+	case attempts >= maxAttempts:
+		return scanJobFail
+	default:
+		return scanJobRetry
+	}
+}
+
+// scanJobBackoff is how long a job waits after its attempts-th failed attempt: doubling
+// from scanJobRetryBase, capped at scanJobRetryMax.
+func scanJobBackoff(attempts int) time.Duration {
+	delay := scanJobRetryBase
+	for i := 1; i < attempts && delay < scanJobRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > scanJobRetryMax {
+		delay = scanJobRetryMax
+	}
+	return delay
+}
+
+// update writes fields to the job only while this worker's claim still holds: the job
+// is running the same attempt. It reports false when the write did not happen, after
+// which the worker must not touch the job's photo, quota or subscribers.
+func (s *ScanJobService) update(job *models.ScanJob, fields map[string]interface{}) bool {
+	res := claimedScanJob(s.db.Model(&models.ScanJob{}), job).Updates(fields)
+	if res.Error != nil {
+		log.Printf("Failed to update scan job %s: %v", job.ID, res.Error)
+		return false
+	}
+	if res.RowsAffected == 0 {
+		log.Printf("Scan job %s attempt %d lost its claim; dropping its result", job.ID, job.Attempts)
+		return false
+	}
+	return true
+}
+
+// claimedScanJob scopes a query to the job as claimed by the attempt in hand. A later
+// claim bumps attempts, and finishing or requeueing the job changes its status.
+func claimedScanJob(db *gorm.DB, job *models.ScanJob) *gorm.DB {
+	return db.Where("id = ? AND attempts = ? AND status = ?", job.ID, job.Attempts, ScanJobRunning)
+}
+
+func (s *ScanJobService) janitor(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(scanJobJanitorInterval)
+	defer ticker.Stop()
+
+	for {
+		cutoff := time.Now().Add(-s.retention)
+		if err := s.db.Where("status IN ? AND finished_at < ?", []string{ScanJobSucceeded, ScanJobFailed}, cutoff).
+			Delete(&models.ScanJob{}).Error; err != nil {
+			log.Printf("Failed to remove old scan jobs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestNextScanJobStep(t *testing.T) {
+	cases := []struct {
+		saved    bool
+		attempts int
+		want     scanJobStep
+	}{
+		{false, 1, scanJobRetry},
+		{false, 2, scanJobRetry},
+		{false, 3, scanJobFail},
+		{false, 4, scanJobFail},
+		// The reading ID is the job ID: a failed insert after another attempt saved it
+		// still finishes the job, even with no attempts left.
+		{true, 1, scanJobSucceed},
+		{true, 3, scanJobSucceed},
+	}
+	for _, c := range cases {
+		if got := nextScanJobStep(c.saved, c.attempts, 3); got != c.want {
+			t.Errorf("saved=%v attempts=%d: got %d, want %d", c.saved, c.attempts, got, c.want)
+		}
+	}
+}
+
+func TestScanJobBackoff(t *testing.T) {
+	want := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 2 * time.Minute, 2 * time.Minute}
+	for i, w := range want {
+		if got := scanJobBackoff(i + 1); got != w {
+			t.Errorf("attempt %d: got %v, want %v", i+1, got, w)
+		}
+	}
+	if got := scanJobBackoff(1000); got != scanJobRetryMax {
+		t.Errorf("large attempt count: got %v", got)
+	}
+}
+
+func TestClaimedScanJobFencesByAttemptAndStatus(t *testing.T) {
+	job := &models.ScanJob{ID: uuid.New(), Attempts: 2}
+	stmt := claimedScanJob(dryRunDB(t).Model(&models.ScanJob{}), job).Updates(map[string]interface{}{"stage": ScanStageSaving}).Statement
+	if stmt.Error != nil {
+		t.Fatal(stmt.Error)
+	}
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, "attempts = $") || !strings.Contains(sql, "status = $") {
+		t.Errorf("update is not fenced by the claim: %s", sql)
+	}
+	if !reflect.DeepEqual(stmt.Vars[len(stmt.Vars)-3:], []interface{}{job.ID, job.Attempts, ScanJobRunning}) {
+		t.Errorf("fence vars = %v", stmt.Vars)
+	}
+}
+
+// dryRunDB builds Postgres statements without connecting, so tests can check the SQL.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCreateReadingStopsOnceTheJobIsLost(t *testing.T) {
+	// The service has no analyzer or database: reaching either would panic.
+	reading, err := (&AuraService{}).createReading(uuid.New(), "https://example.com/photo.jpg", nil, scanOptions{
+		locale:   i18n.For("en"),
+		progress: func(string, int) error { return errScanJobLost },
+	})
+	if reading != nil || !errors.Is(err, errScanJobLost) {
+		t.Errorf("reading = %v, err = %v; want the scan stopped", reading, err)
+	}
+}
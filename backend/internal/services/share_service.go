@@ -0,0 +1,311 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Share link target types.
+const (
+	ShareTargetReading = "reading"
+	ShareTargetMatch   = "match"
+)
+
+const (
+	defaultShareExpiryDays = 30
+	MaxShareExpiryDays     = 365
+	maxListedShares        = 100
+	// An anonymous visitor counts once per link in this window, however often they reload.
+	anonymousShareViewWindow = 30 * time.Minute
+)
+
+// previewAgents are substrings of the user agents of link-preview fetchers and crawlers.
+// Their fetches render the page but are not views. iMessage fetches previews as
+// facebookexternalhit and Twitterbot.
+var previewAgents = []string{
+	"slackbot", "twitterbot", "discordbot", "telegrambot", "linkedinbot", "facebookexternalhit",
+	"facebot", "whatsapp", "skypeuripreview", "pinterestbot", "redditbot", "embedly",
+	"googlebot", "bingbot", "applebot", "duckduckbot", "yandexbot", "crawler", "spider",
+}
+
+var (
+	ErrInvalidShareTarget  = errors.New("target_type must be reading or match")
+	ErrInvalidShareExpiry  = errors.New("expires_in_days out of range")
+	ErrShareTargetNotFound = errors.New("share target not found")
+	ErrShareNotFound       = errors.New("share link not found")
+)
+
+// ShareService mints public links to readings and matches. A link stops resolving once
+// it expires, its owner revokes it, the content behind it is deleted, or the owner blocks
+// someone who opened it.
+type ShareService struct {
+	db    *gorm.DB
+	cards *AuraCardService
+	// anonymousViews remembers which client IPs recently viewed which links.
+	anonymousViews *windowLimiter
+}
+
+func NewShareService(db *gorm.DB, cards *AuraCardService) *ShareService {
+	return &ShareService{db: db, cards: cards, anonymousViews: newWindowLimiter(1, anonymousShareViewWindow)}
+}
+
+// SharedContent is what a share link resolves to. Match links carry both readings so
+// callers can show the two colors.
+type SharedContent struct {
+	Link       models.ShareLink
+	Reading    *models.AuraReading
+	Match      *models.AuraMatch
+	UserAura   *models.AuraReading
+	FriendAura *models.AuraReading
+}
+
+// Create mints a link to one of the user's readings or matches.
+func (s *ShareService) Create(userID uuid.UUID, req dto.CreateShareRequest) (*models.ShareLink, error) {
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultShareExpiryDays
+	}
+	if days < 1 || days > MaxShareExpiryDays {
+		return nil, ErrInvalidShareExpiry
+	}
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		return nil, ErrShareTargetNotFound
+	}
+
+	link := &models.ShareLink{
+		UserID:     userID,
+		TargetType: req.TargetType,
+		ExpiresAt:  time.Now().AddDate(0, 0, days),
+	}
+	var target interface{}
+	switch req.TargetType {
+	case ShareTargetReading:
+		target, link.ReadingID = &models.AuraReading{}, &targetID
+	case ShareTargetMatch:
+		target, link.MatchID = &models.AuraMatch{}, &targetID
+	default:
+		return nil, ErrInvalidShareTarget
+	}
+	if err := s.db.Where("id = ? AND user_id = ?", targetID, userID).First(target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareTargetNotFound
+		}
+		return nil, err
+	}
+	// Matches with someone on either side of a block cannot be shared.
+	if match, ok := target.(*models.AuraMatch); ok {
+		blocked, err := blockedBetween(s.db, match.UserID, match.FriendID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrShareTargetNotFound
+		}
+	}
+
+	if link.Token, err = newShareToken(); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(link).Error; err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// List returns the user's most recent share links, including revoked and expired ones.
+func (s *ShareService) List(userID uuid.UUID) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(maxListedShares).
+		Find(&links).Error
+	return links, err
+}
+
+// Revoke disables one of the user's links. Revoking a revoked link is a no-op.
+func (s *ShareService) Revoke(userID, id uuid.UUID) error {
+	var link models.ShareLink
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareNotFound
+		}
+		return err
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+	return s.db.Model(&link).Update("revoked_at", time.Now()).Error
+}
+
+// Open resolves a link for a page view and counts it. viewerID is the signed-in user
+// opening it, or nil for an anonymous visitor; signed-in viewers are remembered so the
+// link can be revoked if the owner blocks them, and count once. Anonymous visitors count
+// once per client IP every anonymousShareViewWindow, and link-preview fetchers, told
+// apart by userAgent, not at all. Owners previewing their own link are not counted.
+func (s *ShareService) Open(token string, viewerID *uuid.UUID, clientIP, userAgent string) (*SharedContent, error) {
+	content, err := s.resolve(token, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	link := content.Link
+	if viewerID != nil && *viewerID == link.UserID {
+		return content, nil
+	}
+
+	now := time.Now()
+	count := false
+	if viewerID != nil {
+		view := models.ShareView{ShareID: link.ID, ViewerID: *viewerID, FirstViewedAt: now}
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&view)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		count = result.RowsAffected > 0
+	} else if !isPreviewAgent(userAgent) {
+		key := link.ID.String() + ":" + clientIP
+		if count = s.anonymousViews.allow(now, key); count {
+			s.anonymousViews.hit(now, key)
+		}
+	}
+	if !count {
+		return content, nil
+	}
+
+	if err := s.db.Model(&models.ShareLink{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+		"view_count":     gorm.Expr("view_count + 1"),
+		"last_viewed_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// isPreviewAgent reports whether a user agent belongs to a link-preview fetcher or crawler.
+func isPreviewAgent(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, agent := range previewAgents {
+		if strings.Contains(ua, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Card renders the image for a link without counting a view. Match links get a match card.
+func (s *ShareService) Card(token, size string) (*AuraCard, error) {
+	content, err := s.resolve(token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if content.Match != nil {
+		return s.cards.RenderMatch(*content.Match, *content.UserAura, *content.FriendAura, size)
+	}
+	return s.cards.RenderReading(*content.Reading, size)
+}
+
+// Describe returns the page title and description for shared content. The title is in
+// the viewer's language; the description quotes the content in its own language.
+func (s *ShareService) Describe(content *SharedContent, locale *i18n.Localizer) (string, string) {
+	if content.Match != nil {
+		return locale.T("share.match_title", content.Match.CompatibilityScore), firstSentence(content.Match.Synergy)
+	}
+	palette := s.cards.colors.Palette()
+	return locale.T("share.reading_title", palette.Name(locale, content.Reading.AuraColor)), firstSentence(content.Reading.Personality)
+}
+
+func (s *ShareService) resolve(token string, viewerID *uuid.UUID) (*SharedContent, error) {
+	var link models.ShareLink
+	if err := s.db.Where("token = ? AND revoked_at IS NULL AND expires_at > ?", token, time.Now()).
+		First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+
+	if viewerID != nil && *viewerID != link.UserID {
+		blocked, err := blockedBetween(s.db, link.UserID, *viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrShareNotFound
+		}
+	}
+
+	content := &SharedContent{Link: link}
+	var err error
+	switch {
+	case link.ReadingID != nil:
+		content.Reading, err = findReading(s.db, *link.ReadingID)
+	case link.MatchID != nil:
+		var match models.AuraMatch
+		if err = s.db.First(&match, "id = ?", *link.MatchID).Error; err != nil {
+			break
+		}
+		content.Match = &match
+		if content.UserAura, err = findReading(s.db, match.UserAuraID); err != nil {
+			break
+		}
+		content.FriendAura, err = findReading(s.db, match.FriendAuraID)
+	default:
+		return nil, ErrShareNotFound
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func findReading(db *gorm.DB, id uuid.UUID) (*models.AuraReading, error) {
+	var reading models.AuraReading
+	if err := db.First(&reading, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &reading, nil
+}
+
+func newShareToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// revokeReadingShares revokes links to the given readings and to matches built on them.
+func revokeReadingShares(tx *gorm.DB, readingIDs []uuid.UUID) error {
+	if len(readingIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.ShareLink{}).
+		Where("revoked_at IS NULL").
+		Where("(reading_id IN ? OR match_id IN (SELECT id FROM aura_matches WHERE user_aura_id IN ? OR friend_aura_id IN ?))",
+			readingIDs, readingIDs, readingIDs).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeSharesForBlock revokes the owner's links that the blocked user has opened, and
+// links to the owner's matches with them.
+func revokeSharesForBlock(tx *gorm.DB, ownerID, blockedID uuid.UUID) error {
+	return tx.Model(&models.ShareLink{}).
+		Where("user_id = ? AND revoked_at IS NULL", ownerID).
+		Where("(id IN (SELECT share_id FROM share_views WHERE viewer_id = ?) OR match_id IN (SELECT id FROM aura_matches WHERE user_id = ? AND friend_id = ?))",
+			blockedID, ownerID, blockedID).
+		Update("revoked_at", time.Now()).Error
+}
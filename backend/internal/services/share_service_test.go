@@ -0,0 +1,44 @@
+package services
+
+import "testing"
+
+func TestNewShareToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := newShareToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(token) != 43 {
+			t.Fatalf("token %q has length %d, want 43", token, len(token))
+		}
+		if seen[token] {
+			t.Fatalf("duplicate token %q", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestIsPreviewAgent(t *testing.T) {
+	for _, ua := range []string{
+		"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+		"Mozilla/5.0 (compatible; Twitterbot/1.0)",
+		"facebookexternalhit/1.1 Facebot Twitterbot/1.0", // iMessage
+		"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)",
+		"WhatsApp/2.23.20.0",
+		"TelegramBot (like TwitterBot)",
+	} {
+		if !isPreviewAgent(ua) {
+			t.Errorf("%q counted as a view", ua)
+		}
+	}
+	for _, ua := range []string{
+		"",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		"Mozilla/5.0 (Linux; Android 10; CUBOT X30) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36",
+	} {
+		if isPreviewAgent(ua) {
+			t.Errorf("%q not counted as a view", ua)
+		}
+	}
+}
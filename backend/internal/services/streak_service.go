@@ -5,27 +5,20 @@ import (
 	"time"
 
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/i18n"
 	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type StreakService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	colors *ColorCatalogService
 }
 
-func NewStreakService(db *gorm.DB) *StreakService {
-	return &StreakService{db: db}
-}
-
-// Unlockable colors at specific streak milestones
-var streakUnlocks = map[int]string{
-	3:  "silver",   // 3-day streak
-	7:  "gold",     // 7-day streak
-	14: "white",    // 14-day streak
-	21: "rainbow",  // 21-day streak
-	30: "cosmic",   // 30-day streak
-	50: "celestial", // 50-day streak
+// NewStreakService reads the colors unlocked at streak milestones from the color catalog.
+func NewStreakService(db *gorm.DB, colors *ColorCatalogService) *StreakService {
+	return &StreakService{db: db, colors: colors}
 }
 
 func (s *StreakService) GetOrCreate(userID uuid.UUID) (*models.AuraStreak, error) {
@@ -70,18 +63,48 @@ func (s *StreakService) Get(userID uuid.UUID) (*dto.StreakResponse, error) {
 	}
 
 	// Calculate next unlock
-	for days, color := range streakUnlocks {
-		if streak.CurrentStreak < days {
-			response.NextUnlock = color
-			response.DaysUntilUnlock = days - streak.CurrentStreak
-			break
-		}
+	response.NextUnlock, response.DaysUntilUnlock = s.colors.Palette().NextUnlock(streak.CurrentStreak)
+
+	return response, nil
+}
+
+// Palette lists the colors the user's readings can come out in, with names in the
+// localizer's language. Rare colors are listed whether or not they are unlocked yet.
+func (s *StreakService) Palette(userID uuid.UUID, locale *i18n.Localizer) (*dto.PaletteResponse, error) {
+	streak, err := s.GetOrCreate(userID)
+	if err != nil {
+		return nil, err
 	}
 
+	palette := s.colors.Palette()
+	response := &dto.PaletteResponse{Version: palette.Version, Colors: []dto.PaletteColor{}}
+	add := func(key string, rare, unlocked bool) {
+		c, _ := palette.Color(key)
+		entry := dto.PaletteColor{
+			Key:       key,
+			Name:      palette.Name(locale, key),
+			Hex:       c.Hex,
+			Rare:      rare,
+			Unlocked:  unlocked,
+			CardStyle: c.CardStyle,
+		}
+		if rare {
+			entry.UnlockStreak = c.UnlockStreak
+			entry.ScanChance = c.ScanChance
+		}
+		response.Colors = append(response.Colors, entry)
+	}
+	for _, key := range palette.PrimaryColors() {
+		add(key, false, true)
+	}
+	for _, key := range palette.RareColors() {
+		add(key, true, contains(streak.UnlockedColors, key))
+	}
 	return response, nil
 }
 
-func (s *StreakService) Update(userID uuid.UUID) (*dto.StreakUpdateResponse, error) {
+// Update records today's scan and returns a streak message in the localizer's language.
+func (s *StreakService) Update(userID uuid.UUID, locale *i18n.Localizer) (*dto.StreakUpdateResponse, error) {
 	streak, err := s.GetOrCreate(userID)
 	if err != nil {
 		return nil, err
@@ -97,7 +120,7 @@ func (s *StreakService) Update(userID uuid.UUID) (*dto.StreakUpdateResponse, err
 
 	// Already scanned today
 	if !streak.LastScanDate.IsZero() && lastScan.Equal(today) {
-		message = "You've already scanned today! Come back tomorrow."
+		message = locale.T("streak.already_scanned")
 		return &dto.StreakUpdateResponse{
 			Streak: dto.StreakResponse{
 				ID:             streak.ID,
@@ -119,18 +142,18 @@ func (s *StreakService) Update(userID uuid.UUID) (*dto.StreakUpdateResponse, err
 	if streak.LastScanDate.IsZero() {
 		// First scan ever
 		streak.CurrentStreak = 1
-		message = "🔥 Your aura journey begins! Day 1 streak started."
+		message = locale.T("streak.journey_begins_first")
 	} else if lastScan.Equal(yesterday) {
 		// Consecutive day - streak continues
 		streak.CurrentStreak++
-		message = "🔥 Amazing! " + formatStreakMessage(streak.CurrentStreak)
+		message = locale.T("streak.continued", formatStreakMessage(locale, streak.CurrentStreak))
 	} else {
 		// Streak broken (more than 1 day gap)
 		if streak.CurrentStreak > 0 {
 			streakBroken = true
-			message = "💫 New beginning! Your previous streak was " + formatDays(streak.CurrentStreak) + ". Let's start fresh!"
+			message = locale.T("streak.new_beginning", formatDays(locale, streak.CurrentStreak))
 		} else {
-			message = "🔥 Your aura journey begins!"
+			message = locale.T("streak.journey_begins")
 		}
 		streak.CurrentStreak = 1
 	}
@@ -145,14 +168,12 @@ func (s *StreakService) Update(userID uuid.UUID) (*dto.StreakUpdateResponse, err
 	streak.LastScanDate = now
 
 	// Check for new unlocks
-	for days, color := range streakUnlocks {
-		if streak.CurrentStreak == days {
-			newUnlock = color
-			if !contains(streak.UnlockedColors, color) {
-				streak.UnlockedColors = append(streak.UnlockedColors, color)
-				message = "🎉 " + message + " You unlocked the " + color + " aura!"
-			}
-			break
+	palette := s.colors.Palette()
+	if color := palette.UnlockAt(streak.CurrentStreak); color != "" {
+		newUnlock = color
+		if !contains(streak.UnlockedColors, color) {
+			streak.UnlockedColors = append(streak.UnlockedColors, color)
+			message = locale.T("streak.unlocked", message, palette.Name(locale, color))
 		}
 	}
 
@@ -176,37 +197,23 @@ func (s *StreakService) Update(userID uuid.UUID) (*dto.StreakUpdateResponse, err
 	}
 
 	// Add next unlock info
-	for days, color := range streakUnlocks {
-		if streak.CurrentStreak < days {
-			response.Streak.NextUnlock = color
-			response.Streak.DaysUntilUnlock = days - streak.CurrentStreak
-			break
-		}
-	}
+	response.Streak.NextUnlock, response.Streak.DaysUntilUnlock = palette.NextUnlock(streak.CurrentStreak)
 
 	return response, nil
 }
 
-func formatStreakMessage(days int) string {
-	if days == 7 {
-		return "1 week streak! You're on fire! 🔥"
-	} else if days == 14 {
-		return "2 weeks strong! Incredible dedication! ⭐"
-	} else if days == 21 {
-		return "3 weeks! You're a true aura master! 🌟"
-	} else if days == 30 {
-		return "30 days! Legendary status achieved! 👑"
-	} else if days == 50 {
-		return "50 days! You're absolutely cosmic! 🌌"
-	}
-	return formatDays(days) + " streak! Keep going!"
+func formatStreakMessage(locale *i18n.Localizer, days int) string {
+	if key := fmt.Sprintf("streak.milestone.%d", days); locale.Has(key) {
+		return locale.T(key)
+	}
+	return locale.T("streak.keep_going", formatDays(locale, days))
 }
 
-func formatDays(days int) string {
+func formatDays(locale *i18n.Localizer, days int) string {
 	if days == 1 {
-		return "1 day"
+		return locale.T("streak.days.one")
 	}
-	return fmt.Sprintf("%d days", days)
+	return locale.T("streak.days.other", days)
 }
 
 func contains(slice []string, item string) bool {
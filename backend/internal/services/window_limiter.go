@@ -0,0 +1,67 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTrackedLimiterKeys bounds a limiter's table before stale keys are swept.
+const maxTrackedLimiterKeys = 10000
+
+// windowLimiter counts events per key over a sliding window and refuses keys that
+// reached the limit. Counts live in this instance only, so with several replicas each
+// one allows its own share.
+type windowLimiter struct {
+	max    int
+	window time.Duration
+
+	mu     sync.Mutex
+	events map[string][]time.Time
+}
+
+func newWindowLimiter(max int, window time.Duration) *windowLimiter {
+	return &windowLimiter{max: max, window: window, events: make(map[string][]time.Time)}
+}
+
+// allow reports whether none of the keys has reached the limit.
+func (l *windowLimiter) allow(now time.Time, keys ...string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if len(l.recent(key, now)) >= l.max {
+			return false
+		}
+	}
+	return true
+}
+
+// hit records an event against each key.
+func (l *windowLimiter) hit(now time.Time, keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) >= maxTrackedLimiterKeys {
+		for key := range l.events {
+			l.recent(key, now)
+		}
+	}
+	for _, key := range keys {
+		l.events[key] = append(l.recent(key, now), now)
+	}
+}
+
+// recent drops events older than the window and returns the rest. Callers hold mu.
+func (l *windowLimiter) recent(key string, now time.Time) []time.Time {
+	times := l.events[key]
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	if i == len(times) {
+		delete(l.events, key)
+		return nil
+	}
+	times = times[i:]
+	l.events[key] = times
+	return times
+}
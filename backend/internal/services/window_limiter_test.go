@@ -0,0 +1,34 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWindowLimiterForgetsExpiredEvents(t *testing.T) {
+	l := newWindowLimiter(1, time.Minute)
+	now := time.Now()
+	l.hit(now, "a")
+	if l.allow(now.Add(time.Minute-time.Second), "a") {
+		t.Error("allowed within the window")
+	}
+	if !l.allow(now.Add(time.Minute+time.Second), "a") {
+		t.Error("still refused after the window")
+	}
+	if _, tracked := l.events["a"]; tracked {
+		t.Error("expired events kept")
+	}
+}
+
+func TestWindowLimiterSweepsWhenFull(t *testing.T) {
+	l := newWindowLimiter(1, time.Minute)
+	now := time.Now()
+	for i := 0; i < maxTrackedLimiterKeys; i++ {
+		l.hit(now, strconv.Itoa(i))
+	}
+	l.hit(now.Add(2*time.Minute), "fresh")
+	if len(l.events) != 1 {
+		t.Errorf("tracking %d keys after a sweep, want 1", len(l.events))
+	}
+}
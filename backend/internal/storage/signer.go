@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URLSigner issues and verifies expiring HMAC-signed links to blobs served by the API.
+type URLSigner struct {
+	secret  []byte
+	baseURL string
+	prefix  string
+	now     func() time.Time
+}
+
+// NewURLSigner signs links of the form {baseURL}{prefix}/{key}?expires=..&sig=...
+func NewURLSigner(secret, baseURL, prefix string) *URLSigner {
+	return &URLSigner{
+		secret:  []byte(secret),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		prefix:  "/" + strings.Trim(prefix, "/"),
+		now:     time.Now,
+	}
+}
+
+// URL returns a link to key valid for ttl.
+func (s *URLSigner) URL(key string, ttl time.Duration) string {
+	expires := s.now().Add(ttl).Unix()
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("sig", s.signature(key, expires))
+	return s.baseURL + s.prefix + "/" + escapeKey(key) + "?" + q.Encode()
+}
+
+// Verify checks a signature and expiry taken from a request.
+func (s *URLSigner) Verify(key, expires, sig string) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > exp {
+		return false
+	}
+	expected := s.signature(key, exp)
+	return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+func (s *URLSigner) signature(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
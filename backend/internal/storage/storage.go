@@ -0,0 +1,56 @@
+// Package storage persists binary blobs (scan photos, thumbnails, rendered cards) behind a
+// small interface with local filesystem and S3-compatible implementations.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/aurasnap/backend/internal/config"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore stores opaque objects under slash-separated keys.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the blob store selected by STORAGE_BACKEND.
+func New(cfg *config.Config) (BlobStore, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.StorageBackend)) {
+	case "", "local":
+		return NewLocalStore(cfg.StorageLocalDir)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// validateKey rejects keys that could escape the store root or address a "directory".
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
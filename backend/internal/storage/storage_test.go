@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func exerciseStore(t *testing.T, store BlobStore) {
+	t.Helper()
+	ctx := context.Background()
+	key := "readings/user-1/reading-1/original.jpg"
+
+	if err := store.Put(ctx, key, []byte("\xff\xd8\xffjpeg-bytes"), "image/jpeg"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	data, contentType, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if string(data) != "\xff\xd8\xffjpeg-bytes" {
+		t.Fatalf("unexpected data %q", data)
+	}
+	if contentType != "image/jpeg" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, err := store.Get(ctx, key); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("deleting a missing blob should succeed, got %v", err)
+	}
+
+	if err := store.Put(ctx, "../escape", []byte("x"), ""); err != ErrInvalidKey {
+		t.Fatalf("expected ErrInvalidKey for traversal, got %v", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new local store: %v", err)
+	}
+	exerciseStore(t, store)
+}
+
+// fakeS3 is a minimal in-memory stand-in for the S3 object API.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	t       *testing.T
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "Signature=") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	if r.Header.Get("x-amz-content-sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[r.URL.Path])
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3StoreAgainstStandIn(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}, t: t}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Options{
+		Endpoint:  server.URL,
+		Bucket:    "aura",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("new s3 store: %v", err)
+	}
+	exerciseStore(t, store)
+
+	if err := store.Put(context.Background(), "a/b.png", []byte("png"), "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, ok := fake.objects["/aura/a/b.png"]; !ok {
+		t.Fatalf("expected path-style object key, have %v", fake.objects)
+	}
+}
+
+func TestURLSigner(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := NewURLSigner("secret", "https://api.example.com", "/api/media")
+	signer.now = func() time.Time { return now }
+
+	link := signer.URL("readings/u/r/thumb.jpg", time.Minute)
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if u.Path != "/api/media/readings/u/r/thumb.jpg" {
+		t.Fatalf("unexpected path %q", u.Path)
+	}
+
+	q := u.Query()
+	if !signer.Verify("readings/u/r/thumb.jpg", q.Get("expires"), q.Get("sig")) {
+		t.Fatalf("expected signature to verify")
+	}
+	if signer.Verify("readings/u/r/original.jpg", q.Get("expires"), q.Get("sig")) {
+		t.Fatalf("signature must be bound to the key")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if signer.Verify("readings/u/r/thumb.jpg", q.Get("expires"), q.Get("sig")) {
+		t.Fatalf("expired link must not verify")
+	}
+}
@@ -467,7 +467,7 @@ export default function HistoryScreen() {
   const [isLoading, setIsLoading] = useState(true);
   const [refreshing, setRefreshing] = useState(false);
   const [loadError, setLoadError] = useState<string | null>(null);
-  const [page, setPage] = useState(1);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const [totalCount, setTotalCount] = useState(0);
   const [hasMore, setHasMore] = useState(false);
   const [isLoadingMore, setIsLoadingMore] = useState(false);
@@ -522,7 +522,7 @@ export default function HistoryScreen() {
 
       if (source.length === 0) {
         try {
-          const remoteRes = await api.get('/aura?page=1&page_size=30');
+          const remoteRes = await api.get('/aura?page_size=30');
           const remoteRows = Array.isArray(remoteRes.data?.data) ? remoteRes.data.data : [];
           if (remoteRows.length > 0) {
             const normalizedRows = remoteRows.map((item: any) => normalizeGuestAuraReading(item));
@@ -541,7 +541,7 @@ export default function HistoryScreen() {
       setStreakDays(computeStreakDays(parsed));
       setTotalCount(parsed.length);
       setHasMore(false);
-      setPage(1);
+      setNextCursor(null);
       setLoadError(null);
     } catch {
       setReadings([]);
@@ -554,17 +554,20 @@ export default function HistoryScreen() {
     }
   }, []);
 
-  const fetchAuthPage = useCallback(async (pageNum: number, append: boolean) => {
+  const fetchAuthPage = useCallback(async (cursor: string | null, append: boolean) => {
     if (!isAuthenticated) return;
 
+    const isFirstPage = cursor === null;
     try {
-      if (pageNum === 1 && !append) {
+      if (isFirstPage && !append) {
         setLoadError(null);
       }
 
-      const listPromise = api.get(`/aura?page=${pageNum}&page_size=${PAGE_SIZE}`);
-      const statsPromise = pageNum === 1 ? api.get('/aura/stats') : null;
-      const streakPromise = pageNum === 1 ? api.get('/streak') : null;
+      const listPromise = api.get('/aura', {
+        params: cursor ? { page_size: PAGE_SIZE, cursor } : { page_size: PAGE_SIZE },
+      });
+      const statsPromise = isFirstPage ? api.get('/aura/stats') : null;
+      const streakPromise = isFirstPage ? api.get('/streak') : null;
 
       const [listRes, statsRes, streakRes] = await Promise.all([
         listPromise,
@@ -576,7 +579,7 @@ export default function HistoryScreen() {
       const mappedRows: AuraReading[] = rows.map((item: any) => normalizeReading(item));
 
       setReadings((prev) => {
-        if (!append || pageNum === 1) return mappedRows;
+        if (!append || isFirstPage) return mappedRows;
         const seen = new Set(prev.map((entry) => entry.id));
         const merged = [...prev];
         mappedRows.forEach((entry) => {
@@ -585,10 +588,11 @@ export default function HistoryScreen() {
         return merged;
       });
 
-      const total = Number(listRes.data?.total_count || 0);
-      setTotalCount(total);
-      setHasMore(pageNum * PAGE_SIZE < total);
-      setPage(pageNum);
+      const cursorValue = listRes.data?.next_cursor;
+      const next = typeof cursorValue === 'string' && cursorValue ? cursorValue : null;
+      setTotalCount(Number(listRes.data?.total_count || 0));
+      setNextCursor(next);
+      setHasMore(Boolean(listRes.data?.has_more) && next !== null);
 
       if (statsRes) {
         const statsPayload = (statsRes.data?.data || statsRes.data) ?? null;
@@ -642,6 +646,7 @@ export default function HistoryScreen() {
       setStreakDays(0);
       setTotalCount(0);
       setHasMore(false);
+      setNextCursor(null);
       setLoadError(null);
       setIsLoading(false);
       setRefreshing(false);
@@ -649,7 +654,7 @@ export default function HistoryScreen() {
       return;
     }
 
-    await fetchAuthPage(1, false);
+    await fetchAuthPage(null, false);
   }, [fetchAuthPage, fetchGuestData, isAuthLoading, isAuthenticated, isGuest]);
 
   useEffect(() => {
@@ -663,9 +668,9 @@ export default function HistoryScreen() {
   };
 
   const handleLoadMore = () => {
-    if (isGuest || !isAuthenticated || isLoadingMore || !hasMore) return;
+    if (isGuest || !isAuthenticated || isLoadingMore || !hasMore || !nextCursor) return;
     setIsLoadingMore(true);
-    fetchAuthPage(page + 1, true);
+    fetchAuthPage(nextCursor, true);
   };
 
   const handleSelectReading = (reading: AuraReading) => {
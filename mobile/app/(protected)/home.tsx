@@ -1,4 +1,4 @@
-import React, { useEffect, useMemo, useState } from 'react';
+import React, { useEffect, useMemo, useRef, useState } from 'react';
 import { Alert, Pressable, RefreshControl, ScrollView, Share, Text, useWindowDimensions, View } from 'react-native';
 import { SafeAreaView } from 'react-native-safe-area-context';
 import { useRouter } from 'expo-router';
@@ -98,6 +98,8 @@ export default function HomeScreen() {
   const [history, setHistory] = useState<ReturnType<typeof toDisplayResult>[]>([]);
   const [refreshing, setRefreshing] = useState(false);
   const [todayScanCount, setTodayScanCount] = useState(0);
+  const [dailyScanLimit, setDailyScanLimit] = useState(2);
+  const timezoneSynced = useRef(false);
 
   // Animations
   const orbPulse = useSharedValue(1);
@@ -189,7 +191,7 @@ export default function HomeScreen() {
 
         if (source.length === 0) {
           try {
-            const remoteRes = await api.get('/aura?page=1&page_size=10');
+            const remoteRes = await api.get('/aura?page_size=10');
             const rows = Array.isArray(remoteRes.data?.data) ? remoteRes.data.data : [];
             if (rows.length > 0) {
               const normalizedRows = rows.map((row: any) => normalizeGuestAuraReading(row));
@@ -215,12 +217,25 @@ export default function HomeScreen() {
 
       if (isAuthenticated) {
         const [listRes, checkRes] = await Promise.allSettled([
-          api.get('/aura?page=1&page_size=10'),
+          api.get('/aura?page_size=10'),
           api.get('/aura/scan/check'),
         ]);
 
         if (checkRes.status === 'fulfilled') {
-          setTodayScanCount(2 - (checkRes.value.data?.remaining || 0));
+          const limit = checkRes.value.data?.limit;
+          if (typeof limit === 'number' && limit >= 0) {
+            setDailyScanLimit(limit);
+          }
+          setTodayScanCount(checkRes.value.data?.used || 0);
+        }
+
+        // The daily quota resets at midnight in the profile's timezone.
+        if (!timezoneSynced.current) {
+          timezoneSynced.current = true;
+          const timezone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+          if (timezone) {
+            api.patch('/auth/profile', { timezone }).catch(() => {});
+          }
         }
 
         if (listRes.status === 'fulfilled') {
@@ -280,11 +295,11 @@ export default function HomeScreen() {
     }
 
     // Check authenticated user daily limit
-    if (isAuthenticated && !isSubscribed && todayScanCount >= 2) {
+    if (isAuthenticated && !isSubscribed && todayScanCount >= dailyScanLimit) {
       hapticError();
       Alert.alert(
         'Daily Limit Reached',
-        'Free users get 2 scans per day. Upgrade to Premium for unlimited scans!',
+        `Free users get ${dailyScanLimit} scan${dailyScanLimit === 1 ? '' : 's'} per day. Upgrade to Premium for unlimited scans!`,
         [
           { text: 'Upgrade', onPress: () => router.push('/(protected)/paywall') },
           { text: 'OK', style: 'cancel' },
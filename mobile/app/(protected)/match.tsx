@@ -575,7 +575,7 @@ export default function MatchScreen() {
 
   const fetchSelfAura = useCallback(async () => {
     try {
-      const res = await api.get('/aura?page=1&page_size=1');
+      const res = await api.get('/aura?page_size=1');
       const rows = Array.isArray(res.data?.data) ? res.data.data : [];
       const latestColor = rows[0]?.aura_color;
       if (typeof latestColor === 'string' && latestColor.length > 0) {
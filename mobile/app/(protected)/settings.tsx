@@ -695,8 +695,14 @@ export default function SettingsScreen() {
           readings: guestRows,
         };
       } else if (isAuthenticated) {
-        const res = await api.get('/aura?page=1&page_size=200');
-        const rows = Array.isArray(res.data?.data) ? res.data.data : [];
+        // The API pages at most 100 readings at a time; follow the cursor for the rest.
+        const rows: any[] = [];
+        let cursor: string | undefined;
+        do {
+          const res = await api.get('/aura', { params: { page_size: 100, cursor } });
+          if (Array.isArray(res.data?.data)) rows.push(...res.data.data);
+          cursor = res.data?.next_cursor || undefined;
+        } while (cursor && rows.length < 200);
         dataPayload = {
           mode: 'account',
           exported_at: new Date().toISOString(),
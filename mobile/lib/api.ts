@@ -18,13 +18,17 @@ const api = axios.create({
   headers: { 'Content-Type': 'application/json' },
 });
 
-// Request interceptor: attach access token
+// Device locale, sent so readings and error messages come back in the user's language
+const DEVICE_LOCALE = Intl.DateTimeFormat().resolvedOptions().locale || 'en';
+
+// Request interceptor: attach access token and locale
 api.interceptors.request.use(
   async (config: InternalAxiosRequestConfig) => {
     const token = await getAccessToken();
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    config.headers['Accept-Language'] = DEVICE_LOCALE;
     return config;
   },
   (error) => Promise.reject(error)